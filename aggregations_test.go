@@ -156,5 +156,26 @@ func TestAggregations(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Script for termsAggs",
+			Aggregate(
+				TermsAgg("genres", "").
+					Script(InlineScript("doc['genre'].value + params.suffix").Param("suffix", "-eu")).
+					Size(5),
+			),
+			map[string]interface{}{
+				"aggs": map[string]interface{}{
+					"genres": map[string]interface{}{
+						"terms": map[string]interface{}{
+							"script": map[string]interface{}{
+								"source": "doc['genre'].value + params.suffix",
+								"params": map[string]interface{}{"suffix": "-eu"},
+							},
+							"size": 5,
+						},
+					},
+				},
+			},
+		},
 	})
 }
@@ -14,6 +14,8 @@ type TermsAggregation struct {
 	aggs        []Aggregation
 	order       map[string]string
 	include     []string
+	script      *Script
+	frozen      bool
 }
 
 // TermsAgg creates a new aggregation of type "terms". The method name includes
@@ -32,12 +34,14 @@ func (agg *TermsAggregation) Name() string {
 
 // Size sets the number of term buckets to return.
 func (agg *TermsAggregation) Size(size uint64) *TermsAggregation {
+	agg = agg.mutable()
 	agg.size = &size
 	return agg
 }
 
 // ShardSize sets how many terms to request from each shard.
 func (agg *TermsAggregation) ShardSize(size float64) *TermsAggregation {
+	agg = agg.mutable()
 	agg.shardSize = &size
 	return agg
 }
@@ -46,33 +50,94 @@ func (agg *TermsAggregation) ShardSize(size float64) *TermsAggregation {
 // returned by the aggregation which represents the worst case error in the
 // document count.
 func (agg *TermsAggregation) ShowTermDocCountError(b bool) *TermsAggregation {
+	agg = agg.mutable()
 	agg.showTermDoc = &b
 	return agg
 }
 
 // Aggs sets sub-aggregations for the aggregation.
 func (agg *TermsAggregation) Aggs(aggs ...Aggregation) *TermsAggregation {
+	agg = agg.mutable()
 	agg.aggs = aggs
 	return agg
 }
 
 // Order sets the sort for terms agg
 func (agg *TermsAggregation) Order(order map[string]string) *TermsAggregation {
+	agg = agg.mutable()
 	agg.order = order
 	return agg
 }
 
 // Include filter the values for  buckets
 func (agg *TermsAggregation) Include(include ...string) *TermsAggregation {
+	agg = agg.mutable()
 	agg.include = include
 	return agg
 }
 
+// Script sets the script generating the terms to aggregate on. The field may
+// be left empty when a script is used.
+func (agg *TermsAggregation) Script(script *Script) *TermsAggregation {
+	agg = agg.mutable()
+	agg.script = script
+	return agg
+}
+
+// GetField returns the name of the field the aggregation applies to.
+func (agg *TermsAggregation) GetField() string {
+	return agg.field
+}
+
+// GetAggs returns the sub-aggregations of the aggregation.
+func (agg *TermsAggregation) GetAggs() []Aggregation {
+	if agg.frozen {
+		return cloneAggs(agg.aggs)
+	}
+	return agg.aggs
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *TermsAggregation) Clone() *TermsAggregation {
+	c := *agg
+	c.frozen = false
+	c.aggs = cloneAggs(agg.aggs)
+	if agg.order != nil {
+		c.order = make(map[string]string, len(agg.order))
+		for k, v := range agg.order {
+			c.order[k] = v
+		}
+	}
+	c.include = cloneStrings(agg.include)
+	c.script = cloneScript(agg.script)
+	return &c
+}
+
+// Freeze makes the aggregation and its sub-aggregations immutable, so that its
+// setters return a modified copy instead (see SearchRequest.Freeze).
+func (agg *TermsAggregation) Freeze() *TermsAggregation {
+	*agg = *agg.Clone()
+	freeze(agg)
+	return agg
+}
+
+// mutable returns the aggregation if it is not frozen, or a mutable copy otherwise.
+func (agg *TermsAggregation) mutable() *TermsAggregation {
+	if agg.frozen {
+		return agg.Clone()
+	}
+	return agg
+}
+
 // Map returns a map representation of the aggregation, thus implementing the
 // Mappable interface.
 func (agg *TermsAggregation) Map() map[string]interface{} {
-	innerMap := map[string]interface{}{
-		"field": agg.field,
+	innerMap := make(map[string]interface{})
+	if agg.field != "" || agg.script == nil {
+		innerMap["field"] = agg.field
+	}
+	if agg.script != nil {
+		innerMap["script"] = agg.script.Map()
 	}
 
 	if agg.size != nil {
@@ -88,13 +153,10 @@ func (agg *TermsAggregation) Map() map[string]interface{} {
 		innerMap["order"] = agg.order
 	}
 
-	if agg.include != nil {
-		if len(agg.include) <= 1 {
-			innerMap["include"] = agg.include[0]
-		} else {
-			innerMap["include"] = agg.include
-		}
-
+	if len(agg.include) == 1 {
+		innerMap["include"] = agg.include[0]
+	} else if len(agg.include) > 1 {
+		innerMap["include"] = agg.include
 	}
 
 	outerMap := map[string]interface{}{
@@ -110,3 +172,60 @@ func (agg *TermsAggregation) Map() map[string]interface{} {
 
 	return outerMap
 }
+
+// writeJSON writes the aggregation in the same way as Map, see EncodeJSON.
+func (agg *TermsAggregation) writeJSON(e *encoder) {
+	e.openObject()
+	if len(agg.aggs) > 0 {
+		e.key("aggs")
+		e.aggs(agg.aggs)
+	}
+	e.key("terms")
+	e.openObject()
+	if agg.field != "" || agg.script == nil {
+		e.key("field")
+		e.string(agg.field)
+	}
+	if len(agg.include) == 1 {
+		e.key("include")
+		e.string(agg.include[0])
+	} else if len(agg.include) > 1 {
+		e.key("include")
+		e.strings(agg.include)
+	}
+	if agg.order != nil {
+		e.key("order")
+		e.stringMap(agg.order)
+	}
+	if agg.script != nil {
+		e.key("script")
+		e.mappable(agg.script)
+	}
+	if agg.shardSize != nil {
+		e.key("shard_size")
+		e.float(*agg.shardSize, 64)
+	}
+	if agg.showTermDoc != nil {
+		e.key("show_term_doc_count_error")
+		e.bool(*agg.showTermDoc)
+	}
+	if agg.size != nil {
+		e.key("size")
+		e.uint(*agg.size)
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate validates the aggregation, its script and its sub-aggregations,
+// implementing the Validator interface.
+func (agg *TermsAggregation) Validate() error {
+	var v validation
+	if agg.script == nil {
+		v.field("/terms/field", agg.field)
+	} else {
+		v.child("/terms/script", agg.script)
+	}
+	v.aggs("/aggs", agg.aggs)
+	return v.err()
+}
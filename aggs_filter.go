@@ -4,6 +4,7 @@ type FilterAggregation struct {
 	name   string
 	filter Mappable
 	aggs   []Aggregation
+	frozen bool
 }
 
 // Filter creates a new aggregation of type "filter". The method name includes
@@ -22,19 +23,63 @@ func (agg *FilterAggregation) Name() string {
 
 // Filter sets the filter items
 func (agg *FilterAggregation) Filter(filter Mappable) *FilterAggregation {
+	agg = agg.mutable()
 	agg.filter = filter
 	return agg
 }
 
 // Aggs sets sub-aggregations for the aggregation.
 func (agg *FilterAggregation) Aggs(aggs ...Aggregation) *FilterAggregation {
+	agg = agg.mutable()
 	agg.aggs = aggs
 	return agg
 }
 
+// GetFilter returns the filter query of the aggregation.
+func (agg *FilterAggregation) GetFilter() Mappable {
+	if agg.frozen {
+		return cloneMappable(agg.filter)
+	}
+	return agg.filter
+}
+
+// GetAggs returns the sub-aggregations of the aggregation.
+func (agg *FilterAggregation) GetAggs() []Aggregation {
+	if agg.frozen {
+		return cloneAggs(agg.aggs)
+	}
+	return agg.aggs
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *FilterAggregation) Clone() *FilterAggregation {
+	c := *agg
+	c.frozen = false
+	c.filter = cloneMappable(agg.filter)
+	c.aggs = cloneAggs(agg.aggs)
+	return &c
+}
+
+// Freeze makes the aggregation and its sub-aggregations immutable, so that its
+// setters return a modified copy instead (see SearchRequest.Freeze).
+func (agg *FilterAggregation) Freeze() *FilterAggregation {
+	*agg = *agg.Clone()
+	freeze(agg)
+	return agg
+}
+
+// mutable returns the aggregation if it is not frozen, or a mutable copy otherwise.
+func (agg *FilterAggregation) mutable() *FilterAggregation {
+	if agg.frozen {
+		return agg.Clone()
+	}
+	return agg
+}
+
 func (agg *FilterAggregation) Map() map[string]interface{} {
-	outerMap := map[string]interface{}{
-		"filter": agg.filter.Map(),
+	outerMap := make(map[string]interface{})
+	if agg.filter != nil {
+		outerMap["filter"] = agg.filter.Map()
 	}
 
 	if len(agg.aggs) > 0 {
@@ -47,3 +92,26 @@ func (agg *FilterAggregation) Map() map[string]interface{} {
 
 	return outerMap
 }
+
+// writeJSON writes the aggregation in the same way as Map, see EncodeJSON.
+func (agg *FilterAggregation) writeJSON(e *encoder) {
+	e.openObject()
+	if len(agg.aggs) > 0 {
+		e.key("aggs")
+		e.aggs(agg.aggs)
+	}
+	if agg.filter != nil {
+		e.key("filter")
+		e.mappable(agg.filter)
+	}
+	e.closeObject()
+}
+
+// Validate validates the aggregation's filter and sub-aggregations,
+// implementing the Validator interface.
+func (agg *FilterAggregation) Validate() error {
+	var v validation
+	v.child("/filter", agg.filter)
+	v.aggs("/aggs", agg.aggs)
+	return v.err()
+}
@@ -13,10 +13,14 @@ type BaseAgg struct {
 // types.
 type BaseAggParams struct {
 	// Field is the name of the field to aggregate on.
-	Field string `structs:"field"`
+	Field string `structs:"field,omitempty"`
 	// Miss is a value to provide for documents that are missing a value for the
 	// field.
 	Miss interface{} `structs:"missing,omitempty"`
+	// Scr is the script generating the values to aggregate on, instead of (or
+	// in addition to) the field. It is skipped by the structs package, and
+	// added to the maps of aggregations by mapScript.
+	Scr *Script `structs:"-"`
 }
 
 func newBaseAgg(apiName, name, field string) *BaseAgg {
@@ -35,12 +39,98 @@ func (agg *BaseAgg) Name() string {
 	return agg.name
 }
 
+// Clone returns a deep copy of the aggregation.
+func (agg *BaseAgg) Clone() *BaseAgg {
+	c := *agg
+	c.BaseAggParams = agg.BaseAggParams.clone()
+	return &c
+}
+
 // Map returns a map representation of the aggregation, implementing the
 // Mappable interface.
 func (agg *BaseAgg) Map() map[string]interface{} {
 	return map[string]interface{}{
-		agg.apiName: structs.Map(agg.BaseAggParams),
+		agg.apiName: agg.BaseAggParams.toMap(),
+	}
+}
+
+// writeBaseJSON writes the aggregation in the same way as BaseAgg's Map
+// method, see EncodeJSON. It is only used for the types that don't override
+// the Map method.
+func (agg *BaseAgg) writeBaseJSON(e *encoder) {
+	if !isScalar(agg.Miss) {
+		e.mapValue(agg.Map())
+		return
+	}
+
+	e.openObject()
+	e.key(agg.apiName)
+	e.openObject()
+	if agg.Field != "" {
+		e.key("field")
+		e.string(agg.Field)
+	}
+	if agg.Miss != nil {
+		e.key("missing")
+		e.value(agg.Miss)
+	}
+	if agg.Scr != nil {
+		e.key("script")
+		e.mappable(agg.Scr)
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks that the aggregation has a field name or a script,
+// implementing the Validator interface.
+func (agg *BaseAgg) Validate() error {
+	var v validation
+	agg.BaseAggParams.validateInto(&v, jsonPath(agg.apiName))
+	return v.err()
+}
+
+// toMap returns a map representation of the parameters, as generated by the
+// structs package from their tags, without using reflection.
+func (params *BaseAggParams) toMap() map[string]interface{} {
+	m := make(map[string]interface{}, 1)
+	if params.Field != "" {
+		m["field"] = params.Field
+	}
+	if params.Miss != nil {
+		m["missing"] = structValue(params.Miss)
 	}
+	return params.mapScript(m)
+}
+
+// mapScript adds the script of the parameters, if any, to a map representation
+// of an aggregation, and returns the map.
+func (params *BaseAggParams) mapScript(m map[string]interface{}) map[string]interface{} {
+	if params.Scr != nil {
+		m["script"] = params.Scr.Map()
+	}
+	return m
+}
+
+// clone returns a deep copy of the parameters.
+func (params *BaseAggParams) clone() *BaseAggParams {
+	if params == nil {
+		return nil
+	}
+	c := *params
+	c.Scr = cloneScript(params.Scr)
+	return &c
+}
+
+// validateInto checks that the parameters include a field name or a valid
+// script, recording errors under the provided path.
+func (params *BaseAggParams) validateInto(v *validation, path string) {
+	if params == nil {
+		v.errorf(path, "is required")
+		return
+	}
+	v.check(params.Field != "" || params.Scr != nil, path, "a field name or a script is required")
+	v.optional(path+"/script", params.Scr)
 }
 
 // AvgAgg represents an aggregation of type "avg", as described in
@@ -65,6 +155,18 @@ func (agg *AvgAgg) Missing(val interface{}) *AvgAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *AvgAgg) Script(script *Script) *AvgAgg {
+	agg.Scr = script
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *AvgAgg) Clone() *AvgAgg {
+	return &AvgAgg{BaseAgg: agg.BaseAgg.Clone()}
+}
+
 //----------------------------------------------------------------------------//
 
 // WeightedAvgAgg represents an aggregation of type "weighted_avg", as described
@@ -117,6 +219,14 @@ func (agg *WeightedAvgAgg) Weight(field string, missing ...interface{}) *Weighte
 	return agg
 }
 
+// Clone returns a deep copy of the aggregation.
+func (agg *WeightedAvgAgg) Clone() *WeightedAvgAgg {
+	c := *agg
+	c.Val = agg.Val.clone()
+	c.Weig = agg.Weig.clone()
+	return &c
+}
+
 // Map returns a map representation of the aggregation, thus implementing the
 // Mappable interface.
 func (agg *WeightedAvgAgg) Map() map[string]interface{} {
@@ -125,6 +235,15 @@ func (agg *WeightedAvgAgg) Map() map[string]interface{} {
 	}
 }
 
+// Validate checks that the aggregation has both a value and a weight,
+// implementing the Validator interface.
+func (agg *WeightedAvgAgg) Validate() error {
+	var v validation
+	agg.Val.validateInto(&v, jsonPath(agg.apiName, "value"))
+	agg.Weig.validateInto(&v, jsonPath(agg.apiName, "weight"))
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // CardinalityAgg represents an aggregation of type "cardinality", as described
@@ -152,17 +271,31 @@ func (agg *CardinalityAgg) Missing(val interface{}) *CardinalityAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *CardinalityAgg) Script(script *Script) *CardinalityAgg {
+	agg.Scr = script
+	return agg
+}
+
 // PrecisionThreshold sets the precision threshold of the aggregation.
 func (agg *CardinalityAgg) PrecisionThreshold(val uint16) *CardinalityAgg {
 	agg.PrecisionThr = val
 	return agg
 }
 
+// Clone returns a deep copy of the aggregation.
+func (agg *CardinalityAgg) Clone() *CardinalityAgg {
+	c := *agg
+	c.BaseAgg = agg.BaseAgg.Clone()
+	return &c
+}
+
 // Map returns a map representation of the aggregation, thus implementing the
 // Mappable interface
 func (agg *CardinalityAgg) Map() map[string]interface{} {
 	return map[string]interface{}{
-		agg.apiName: structs.Map(agg),
+		agg.apiName: agg.mapScript(structs.Map(agg)),
 	}
 }
 
@@ -190,6 +323,18 @@ func (agg *MaxAgg) Missing(val interface{}) *MaxAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *MaxAgg) Script(script *Script) *MaxAgg {
+	agg.Scr = script
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *MaxAgg) Clone() *MaxAgg {
+	return &MaxAgg{BaseAgg: agg.BaseAgg.Clone()}
+}
+
 //----------------------------------------------------------------------------//
 
 // MinAgg represents an aggregation of type "min", as described in:
@@ -214,6 +359,18 @@ func (agg *MinAgg) Missing(val interface{}) *MinAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *MinAgg) Script(script *Script) *MinAgg {
+	agg.Scr = script
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *MinAgg) Clone() *MinAgg {
+	return &MinAgg{BaseAgg: agg.BaseAgg.Clone()}
+}
+
 //----------------------------------------------------------------------------//
 
 // SumAgg represents an aggregation of type "sum", as described in:
@@ -238,6 +395,18 @@ func (agg *SumAgg) Missing(val interface{}) *SumAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *SumAgg) Script(script *Script) *SumAgg {
+	agg.Scr = script
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *SumAgg) Clone() *SumAgg {
+	return &SumAgg{BaseAgg: agg.BaseAgg.Clone()}
+}
+
 //----------------------------------------------------------------------------//
 
 // ValueCountAgg represents an aggregation of type "value_count", as described
@@ -255,6 +424,18 @@ func ValueCount(name, field string) *ValueCountAgg {
 	}
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *ValueCountAgg) Script(script *Script) *ValueCountAgg {
+	agg.Scr = script
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *ValueCountAgg) Clone() *ValueCountAgg {
+	return &ValueCountAgg{BaseAgg: agg.BaseAgg.Clone()}
+}
+
 //----------------------------------------------------------------------------//
 
 // PercentilesAgg represents an aggregation of type "percentiles", as described
@@ -304,6 +485,13 @@ func (agg *PercentilesAgg) Missing(val interface{}) *PercentilesAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *PercentilesAgg) Script(script *Script) *PercentilesAgg {
+	agg.Scr = script
+	return agg
+}
+
 // Keyed sets whether the aggregate is keyed or not.
 func (agg *PercentilesAgg) Keyed(b bool) *PercentilesAgg {
 	agg.Key = &b
@@ -323,11 +511,21 @@ func (agg *PercentilesAgg) NumHistogramDigits(val uint8) *PercentilesAgg {
 	return agg
 }
 
+// Clone returns a deep copy of the aggregation.
+func (agg *PercentilesAgg) Clone() *PercentilesAgg {
+	c := *agg
+	c.BaseAgg = agg.BaseAgg.Clone()
+	if agg.Prcnts != nil {
+		c.Prcnts = append([]float32(nil), agg.Prcnts...)
+	}
+	return &c
+}
+
 // Map returns a map representation of the aggregation, thus implementing the
 // Mappable interface.
 func (agg *PercentilesAgg) Map() map[string]interface{} {
 	return map[string]interface{}{
-		agg.apiName: structs.Map(agg),
+		agg.apiName: agg.mapScript(structs.Map(agg)),
 	}
 }
 
@@ -354,6 +552,18 @@ func (agg *StatsAgg) Missing(val interface{}) *StatsAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *StatsAgg) Script(script *Script) *StatsAgg {
+	agg.Scr = script
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *StatsAgg) Clone() *StatsAgg {
+	return &StatsAgg{BaseAgg: agg.BaseAgg.Clone()}
+}
+
 // ---------------------------------------------------------------------------//
 
 // StringStatsAgg represents an aggregation of type "string_stats", as described
@@ -381,6 +591,13 @@ func (agg *StringStatsAgg) Missing(val interface{}) *StringStatsAgg {
 	return agg
 }
 
+// Script sets the script generating the values to aggregate on. The field
+// may be left empty when a script is used.
+func (agg *StringStatsAgg) Script(script *Script) *StringStatsAgg {
+	agg.Scr = script
+	return agg
+}
+
 // ShowDistribution sets whether to show the probability distribution for all
 // characters
 func (agg *StringStatsAgg) ShowDistribution(b bool) *StringStatsAgg {
@@ -388,11 +605,18 @@ func (agg *StringStatsAgg) ShowDistribution(b bool) *StringStatsAgg {
 	return agg
 }
 
+// Clone returns a deep copy of the aggregation.
+func (agg *StringStatsAgg) Clone() *StringStatsAgg {
+	c := *agg
+	c.BaseAgg = agg.BaseAgg.Clone()
+	return &c
+}
+
 // Map returns a map representation of the aggregation, thus implementing the
 // Mappable interface.
 func (agg *StringStatsAgg) Map() map[string]interface{} {
 	return map[string]interface{}{
-		agg.apiName: structs.Map(agg),
+		agg.apiName: agg.mapScript(structs.Map(agg)),
 	}
 }
 
@@ -402,11 +626,8 @@ func (agg *StringStatsAgg) Map() map[string]interface{} {
 // in https://www.elastic.co/guide/en/elasticsearch/reference/
 //     current/search-aggregations-metrics-top-hits-aggregation.html
 type TopHitsAgg struct {
-	name   string
-	from   uint64
-	size   uint64
-	sort   []map[string]interface{}
-	source Source
+	hitOptions
+	name string
 }
 
 // TopHits creates an aggregation of type "top_hits".
@@ -423,26 +644,21 @@ func (agg *TopHitsAgg) Name() string {
 
 // From sets an offset from the first result to return.
 func (agg *TopHitsAgg) From(offset uint64) *TopHitsAgg {
-	agg.from = offset
+	agg.from = &offset
 	return agg
 }
 
 // Size sets the maximum number of top matching hits to return per bucket (the
 // default is 3).
 func (agg *TopHitsAgg) Size(size uint64) *TopHitsAgg {
-	agg.size = size
+	agg.size = &size
 	return agg
 }
 
 // Sort sets how the top matching hits should be sorted. By default the hits are
 // sorted by the score of the main query.
 func (agg *TopHitsAgg) Sort(name string, order Order) *TopHitsAgg {
-	agg.sort = append(agg.sort, map[string]interface{}{
-		name: map[string]interface{}{
-			"order": order,
-		},
-	})
-
+	agg.addSort(name, order)
 	return agg
 }
 
@@ -452,25 +668,89 @@ func (agg *TopHitsAgg) SourceIncludes(keys ...string) *TopHitsAgg {
 	return agg
 }
 
+// SourceExcludes sets the keys to not return from the top matching documents.
+func (agg *TopHitsAgg) SourceExcludes(keys ...string) *TopHitsAgg {
+	agg.source.excludes = keys
+	return agg
+}
+
+// Highlight sets a highlight for the top matching hits.
+func (agg *TopHitsAgg) Highlight(highlight Mappable) *TopHitsAgg {
+	agg.highlight = highlight
+	return agg
+}
+
+// Explain sets whether to return an explanation for how each hit's score was
+// calculated.
+func (agg *TopHitsAgg) Explain(b bool) *TopHitsAgg {
+	agg.explain = &b
+	return agg
+}
+
+// Version sets whether to return the version of each hit.
+func (agg *TopHitsAgg) Version(b bool) *TopHitsAgg {
+	agg.version = &b
+	return agg
+}
+
+// SeqNoPrimaryTerm sets whether to return the sequence number and primary
+// term of the last modification of each hit.
+func (agg *TopHitsAgg) SeqNoPrimaryTerm(b bool) *TopHitsAgg {
+	agg.seqNoPrimaryTerm = &b
+	return agg
+}
+
+// DocvalueFields adds fields whose doc values should be returned for each
+// hit.
+func (agg *TopHitsAgg) DocvalueFields(fields ...string) *TopHitsAgg {
+	for _, field := range fields {
+		agg.docvalueFields = append(agg.docvalueFields, docvalueField{field: field})
+	}
+	return agg
+}
+
+// DocvalueFieldFormat adds a field whose doc values should be returned for
+// each hit, using the provided format (e.g. "epoch_millis" for dates).
+func (agg *TopHitsAgg) DocvalueFieldFormat(field, format string) *TopHitsAgg {
+	agg.docvalueFields = append(agg.docvalueFields, docvalueField{field, format})
+	return agg
+}
+
+// StoredFields sets the stored fields to return for each hit.
+func (agg *TopHitsAgg) StoredFields(fields ...string) *TopHitsAgg {
+	agg.storedFields = append(agg.storedFields, fields...)
+	return agg
+}
+
+// ScriptField adds a field that is computed by the provided script for each
+// hit.
+func (agg *TopHitsAgg) ScriptField(name string, script *Script) *TopHitsAgg {
+	agg.addScriptField(name, script)
+	return agg
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *TopHitsAgg) Clone() *TopHitsAgg {
+	c := *agg
+	c.hitOptions = agg.hitOptions.clone()
+	return &c
+}
+
 // Map returns a map representation of the aggregation, thus implementing the
 // Mappable interface.
 func (agg *TopHitsAgg) Map() map[string]interface{} {
 	innerMap := make(map[string]interface{})
-
-	if agg.from > 0 {
-		innerMap["from"] = agg.from
-	}
-	if agg.size > 0 {
-		innerMap["size"] = agg.size
-	}
-	if len(agg.sort) > 0 {
-		innerMap["sort"] = agg.sort
-	}
-	if len(agg.source.includes) > 0 {
-		innerMap["_source"] = agg.source.Map()
-	}
+	agg.mapInto(innerMap)
 
 	return map[string]interface{}{
 		"top_hits": innerMap,
 	}
 }
+
+// Validate validates the aggregation's highlight and script fields,
+// implementing the Validator interface.
+func (agg *TopHitsAgg) Validate() error {
+	var v validation
+	agg.validateInto(&v, "/top_hits")
+	return v.err()
+}
@@ -23,6 +23,37 @@ func TestMetricAggs(t *testing.T) {
 				},
 			},
 		},
+		{
+			"avg agg: with script",
+			Avg("average_score", "").Script(
+				InlineScript("doc['score'].value * params.factor").Param("factor", 2),
+			),
+			map[string]interface{}{
+				"avg": map[string]interface{}{
+					"script": map[string]interface{}{
+						"source": "doc['score'].value * params.factor",
+						"params": map[string]interface{}{
+							"factor": 2,
+						},
+					},
+				},
+			},
+		},
+		{
+			"stats agg: with stored script",
+			Stats("grades_stats", "grade").Script(StoredScript("curve").Param("by", 5)),
+			map[string]interface{}{
+				"stats": map[string]interface{}{
+					"field": "grade",
+					"script": map[string]interface{}{
+						"id": "curve",
+						"params": map[string]interface{}{
+							"by": 5,
+						},
+					},
+				},
+			},
+		},
 		{
 			"weighted avg",
 			WeightedAvg("weighted_grade").Value("grade", 2).Weight("weight"),
@@ -154,5 +185,66 @@ func TestMetricAggs(t *testing.T) {
 				},
 			},
 		},
+		{
+			"top_hits agg: zero from and size",
+			TopHits("top").From(0).Size(0),
+			map[string]interface{}{
+				"top_hits": map[string]interface{}{
+					"from": 0,
+					"size": 0,
+				},
+			},
+		},
+		{
+			"top_hits agg: all options",
+			TopHits("top").
+				From(5).
+				Size(2).
+				Sort("date", OrderDesc).
+				SourceIncludes("title").
+				SourceExcludes("body").
+				Highlight(Highlight().Field("title")).
+				Explain(true).
+				Version(true).
+				SeqNoPrimaryTerm(true).
+				DocvalueFields("tag").
+				DocvalueFieldFormat("date", "epoch_millis").
+				StoredFields("_none_").
+				ScriptField("double_score", InlineScript("doc['score'].value * params.factor").Param("factor", 2)),
+			map[string]interface{}{
+				"top_hits": map[string]interface{}{
+					"from": 5,
+					"size": 2,
+					"sort": []map[string]interface{}{
+						{"date": map[string]interface{}{"order": "desc"}},
+					},
+					"_source": map[string]interface{}{
+						"includes": []string{"title"},
+						"excludes": []string{"body"},
+					},
+					"highlight": map[string]interface{}{
+						"fields": map[string]interface{}{
+							"title": map[string]interface{}{},
+						},
+					},
+					"explain":             true,
+					"version":             true,
+					"seq_no_primary_term": true,
+					"docvalue_fields": []interface{}{
+						"tag",
+						map[string]interface{}{"field": "date", "format": "epoch_millis"},
+					},
+					"stored_fields": []string{"_none_"},
+					"script_fields": map[string]interface{}{
+						"double_score": map[string]interface{}{
+							"script": map[string]interface{}{
+								"source": "doc['score'].value * params.factor",
+								"params": map[string]interface{}{"factor": 2},
+							},
+						},
+					},
+				},
+			},
+		},
 	})
 }
@@ -1,9 +1,10 @@
 package esquery
 
 type NestedAggregation struct {
-	name string
-	path string
-	aggs []Aggregation
+	name   string
+	path   string
+	aggs   []Aggregation
+	frozen bool
 }
 
 // NestedAgg creates a new aggregation of type "nested". The method name includes
@@ -22,16 +23,55 @@ func (agg *NestedAggregation) Name() string {
 
 // NumberOfFragments sets the aggregations path
 func (agg *NestedAggregation) Path(p string) *NestedAggregation {
+	agg = agg.mutable()
 	agg.path = p
 	return agg
 }
 
 // Aggs sets sub-aggregations for the aggregation.
 func (agg *NestedAggregation) Aggs(aggs ...Aggregation) *NestedAggregation {
+	agg = agg.mutable()
 	agg.aggs = aggs
 	return agg
 }
 
+// GetPath returns the path of the nested field.
+func (agg *NestedAggregation) GetPath() string {
+	return agg.path
+}
+
+// GetAggs returns the sub-aggregations of the aggregation.
+func (agg *NestedAggregation) GetAggs() []Aggregation {
+	if agg.frozen {
+		return cloneAggs(agg.aggs)
+	}
+	return agg.aggs
+}
+
+// Clone returns a deep copy of the aggregation.
+func (agg *NestedAggregation) Clone() *NestedAggregation {
+	c := *agg
+	c.frozen = false
+	c.aggs = cloneAggs(agg.aggs)
+	return &c
+}
+
+// Freeze makes the aggregation and its sub-aggregations immutable, so that its
+// setters return a modified copy instead (see SearchRequest.Freeze).
+func (agg *NestedAggregation) Freeze() *NestedAggregation {
+	*agg = *agg.Clone()
+	freeze(agg)
+	return agg
+}
+
+// mutable returns the aggregation if it is not frozen, or a mutable copy otherwise.
+func (agg *NestedAggregation) mutable() *NestedAggregation {
+	if agg.frozen {
+		return agg.Clone()
+	}
+	return agg
+}
+
 func (agg *NestedAggregation) Map() map[string]interface{} {
 	innerMap := map[string]interface{}{
 		"path": agg.path,
@@ -51,3 +91,27 @@ func (agg *NestedAggregation) Map() map[string]interface{} {
 
 	return outerMap
 }
+
+// writeJSON writes the aggregation in the same way as Map, see EncodeJSON.
+func (agg *NestedAggregation) writeJSON(e *encoder) {
+	e.openObject()
+	if len(agg.aggs) > 0 {
+		e.key("aggs")
+		e.aggs(agg.aggs)
+	}
+	e.key("nested")
+	e.openObject()
+	e.key("path")
+	e.string(agg.path)
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate validates the aggregation and its sub-aggregations, implementing
+// the Validator interface.
+func (agg *NestedAggregation) Validate() error {
+	var v validation
+	v.check(agg.path != "", "/nested/path", "is required")
+	v.aggs("/aggs", agg.aggs)
+	return v.err()
+}
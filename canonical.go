@@ -0,0 +1,214 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// CanonicalJSON returns a canonical JSON encoding of the provided query,
+// aggregation or request, suitable for use as a cache key. Equivalent values
+// have the same encoding:
+//
+//   - object keys are sorted, and no insignificant whitespace is added.
+//   - numbers are encoded in the same way regardless of their Go type, e.g.
+//     int(2), uint8(2) and float64(2.0) are all encoded as 2, and floating
+//     point numbers use the shortest representation of their float64 value.
+//   - the values of "terms", "ids" and "terms_set" queries, whose order does
+//     not matter, are sorted and deduplicated.
+//
+// The order of other lists, such as the clauses of a bool query, is kept.
+// The provided value is not modified.
+func CanonicalJSON(m Mappable) ([]byte, error) {
+	if isNil(m) {
+		return []byte("null"), nil
+	}
+
+	m, err := sortUnorderedValues(m)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalValue(m.Map())
+}
+
+// Equal reports whether the provided values are equivalent, i.e. whether they
+// have the same canonical JSON encoding (see CanonicalJSON). Values that
+// cannot be encoded to JSON are never equal.
+func Equal(a, b Mappable) bool {
+	aJSON, err := CanonicalJSON(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := CanonicalJSON(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
+// Hash returns the 64-bit FNV-1a hash of the canonical JSON encoding of the
+// provided value (see CanonicalJSON), so that equivalent values have the same
+// hash. Values that cannot be encoded to JSON have a hash of 0.
+func Hash(m Mappable) uint64 {
+	b, err := CanonicalJSON(m)
+	if err != nil {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// sortUnorderedValues returns a copy of the provided value where the values
+// of the queries whose order does not matter are sorted and deduplicated. The
+// value itself is returned if it contains no such query.
+func sortUnorderedValues(m Mappable) (Mappable, error) {
+	var found bool
+	Inspect(m, func(node Mappable) bool {
+		switch node.(type) {
+		case *TermsQuery, *IDsQuery, *TermsSetQuery:
+			found = true
+		}
+		return !found
+	})
+	if !found {
+		return m, nil
+	}
+
+	var err error
+	m = cloneMappable(m)
+	Inspect(m, func(node Mappable) bool {
+		if err != nil {
+			return false
+		}
+		switch q := node.(type) {
+		case *TermsQuery:
+			q.values, err = sortValues(q.values)
+		case *IDsQuery:
+			q.IDs.Values = sortStrings(q.IDs.Values)
+		case *TermsSetQuery:
+			q.params.Terms = sortStrings(q.params.Terms)
+		}
+		return true
+	})
+	return m, err
+}
+
+func sortValues(values []interface{}) ([]interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	type keyedValue struct {
+		key   string
+		value interface{}
+	}
+
+	keyed := make([]keyedValue, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		b, err := canonicalValue(v)
+		if err != nil {
+			return nil, err
+		}
+		if key := string(b); !seen[key] {
+			seen[key] = true
+			keyed = append(keyed, keyedValue{key, v})
+		}
+	}
+	sort.Slice(keyed, func(i, j int) bool {
+		return keyed[i].key < keyed[j].key
+	})
+
+	sorted := make([]interface{}, len(keyed))
+	for i, kv := range keyed {
+		sorted[i] = kv.value
+	}
+	return sorted, nil
+}
+
+func sortStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+
+	sorted := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			sorted = append(sorted, s)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// canonicalValue returns the canonical JSON encoding of the provided value.
+// The value is encoded and decoded again, so that values of any type (e.g.
+// structs or GeoPoint values) are reduced to maps, lists and scalars.
+func canonicalValue(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("esquery: failed encoding value: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("esquery: failed decoding value: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(normalizeNumbers(decoded)); err != nil {
+		return nil, fmt.Errorf("esquery: failed encoding value: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// normalizeNumbers replaces the numbers of a decoded JSON value with their
+// canonical representation.
+func normalizeNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, elem := range val {
+			val[k] = normalizeNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range val {
+			val[i] = normalizeNumbers(elem)
+		}
+	case json.Number:
+		return canonicalNumber(val)
+	}
+	return v
+}
+
+// canonicalNumber returns the canonical representation of a JSON number:
+// integers are kept as they are, and other numbers are converted to float64,
+// and encoded as integers if they have no fractional part, or in the
+// shortest representation otherwise.
+func canonicalNumber(n json.Number) json.Number {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(i, 10))
+	}
+	if _, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return n
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return n
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return json.Number(strconv.FormatInt(int64(f), 10))
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
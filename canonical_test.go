@@ -0,0 +1,136 @@
+package esquery
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Mappable
+		exp  string
+	}{
+		{
+			"keys are sorted",
+			Range("score").Lte(9).Gte(5).Boost(2),
+			`{"range":{"score":{"boost":2,"gte":5,"lte":9}}}`,
+		},
+		{
+			"numbers are normalized",
+			Bool().Filter(Term("a", uint8(2)), Term("b", float32(0.5)), Term("c", 3.0), Term("d", -0.0)),
+			`{"bool":{"filter":[{"term":{"a":{"value":2}}},{"term":{"b":{"value":0.5}}},{"term":{"c":{"value":3}}},{"term":{"d":{"value":0}}}]}}`,
+		},
+		{
+			"terms values are sorted and deduplicated",
+			Bool().Filter(Terms("severity", "high", "critical", "high"), Terms("id", 3, 1.0, int64(2))),
+			`{"bool":{"filter":[{"terms":{"severity":["critical","high"]}},{"terms":{"id":[1,2,3]}}]}}`,
+		},
+		{
+			"ids are sorted",
+			IDs("b", "a"),
+			`{"ids":{"values":["a","b"]}}`,
+		},
+		{
+			"html characters are not escaped",
+			Match("title", "<b>&</b>"),
+			`{"match":{"title":{"query":"<b>&</b>"}}}`,
+		},
+		{
+			"nil value",
+			(*BoolQuery)(nil),
+			`null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, _ := json.Marshal(test.q)
+			got, err := CanonicalJSON(test.q)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(got) != test.exp {
+				t.Errorf("expected %s, got %s", test.exp, got)
+			}
+			if after, _ := json.Marshal(test.q); string(before) != string(after) {
+				t.Errorf("query was modified from %s to %s", before, after)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Mappable
+		b     Mappable
+		equal bool
+	}{
+		{
+			"same query",
+			Bool().Must(Match("title", "openssl")).Filter(Term("status", "open")),
+			Bool().Filter(Term("status", "open")).Must(Match("title", "openssl")),
+			true,
+		},
+		{
+			"numeric types",
+			Range("score").Gte(7).Lt(int64(9)),
+			Range("score").Gte(7.0).Lt(uint16(9)),
+			true,
+		},
+		{
+			"reordered terms",
+			Search().Query(Terms("severity", "high", "critical")).Size(10),
+			Search().Query(Terms("severity", "critical", "high")).Size(10),
+			true,
+		},
+		{
+			"reordered terms in highlight and sort",
+			Search().
+				Highlight(Highlight().HighlightQuery(Terms("severity", "high", "critical"))).
+				SortBy(SortField("price").Nested(SortNested("offers").Filter(Terms("offers.tag", 1, 2)))),
+			Search().
+				Highlight(Highlight().HighlightQuery(Terms("severity", "critical", "high"))).
+				SortBy(SortField("price").Nested(SortNested("offers").Filter(Terms("offers.tag", 2, 1)))),
+			true,
+		},
+		{
+			"custom query",
+			CustomQuery(map[string]interface{}{"term": map[string]interface{}{"a": 1}}),
+			Term("a", 1),
+			false,
+		},
+		{
+			"reordered clauses",
+			Bool().Filter(Term("a", 1), Term("b", 2)),
+			Bool().Filter(Term("b", 2), Term("a", 1)),
+			false,
+		},
+		{
+			"different values",
+			Term("a", 1),
+			Term("a", "1"),
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Equal(test.a, test.b); got != test.equal {
+				t.Errorf("expected Equal to return %t, got %t", test.equal, got)
+			}
+			if got := Hash(test.a) == Hash(test.b); got != test.equal {
+				t.Errorf("expected equality of hashes to be %t, got %t", test.equal, got)
+			}
+		})
+	}
+
+	q := Term("a", func() {})
+	if Equal(q, q) {
+		t.Error("expected values that cannot be encoded not to be equal")
+	}
+	if h := Hash(q); h != 0 {
+		t.Errorf("expected values that cannot be encoded to have a hash of 0, got %d", h)
+	}
+}
@@ -0,0 +1,184 @@
+package esquery
+
+import "reflect"
+
+// cloneMappable returns a deep copy of the provided value by calling its
+// Clone method. Values without a Clone method returning a Mappable, such as
+// custom implementations of Mappable, are returned as they are.
+func cloneMappable(m Mappable) Mappable {
+	if isNil(m) {
+		return m
+	}
+
+	method := reflect.ValueOf(m).MethodByName("Clone")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return m
+	}
+	c, ok := method.Call(nil)[0].Interface().(Mappable)
+	if !ok {
+		return m
+	}
+	return c
+}
+
+func cloneMappables(list []Mappable) []Mappable {
+	if list == nil {
+		return nil
+	}
+	c := make([]Mappable, len(list))
+	for i, m := range list {
+		c[i] = cloneMappable(m)
+	}
+	return c
+}
+
+func cloneAggs(aggs []Aggregation) []Aggregation {
+	if aggs == nil {
+		return nil
+	}
+	c := make([]Aggregation, len(aggs))
+	for i, agg := range aggs {
+		c[i] = agg
+		if clone, ok := cloneMappable(agg).(Aggregation); ok {
+			c[i] = clone
+		}
+	}
+	return c
+}
+
+func cloneSpanQuery(q SpanQuery) SpanQuery {
+	if q == nil {
+		return nil
+	}
+	return cloneMappable(q).(SpanQuery)
+}
+
+func cloneSpanQueries(clauses []SpanQuery) []SpanQuery {
+	if clauses == nil {
+		return nil
+	}
+	c := make([]SpanQuery, len(clauses))
+	for i, q := range clauses {
+		c[i] = cloneSpanQuery(q)
+	}
+	return c
+}
+
+func cloneIntervalsRule(r IntervalsRule) IntervalsRule {
+	if r == nil {
+		return nil
+	}
+	return cloneMappable(r).(IntervalsRule)
+}
+
+func cloneIntervalsRules(rules []IntervalsRule) []IntervalsRule {
+	if rules == nil {
+		return nil
+	}
+	c := make([]IntervalsRule, len(rules))
+	for i, r := range rules {
+		c[i] = cloneIntervalsRule(r)
+	}
+	return c
+}
+
+func cloneIntervalsFilter(f *IntervalsFilter) *IntervalsFilter {
+	if f == nil {
+		return nil
+	}
+	return f.Clone()
+}
+
+func cloneScript(s *Script) *Script {
+	if s == nil {
+		return nil
+	}
+	return s.Clone()
+}
+
+func cloneLikeItems(items []LikeItem) []LikeItem {
+	if items == nil {
+		return nil
+	}
+	c := make([]LikeItem, len(items))
+	for i, item := range items {
+		c[i] = item
+		if doc, ok := item.(*LikeDocument); ok && doc != nil {
+			c[i] = doc.Clone()
+		}
+	}
+	return c
+}
+
+func cloneStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	return append([]string(nil), list...)
+}
+
+// cloneValues copies a list of values provided by the user, which are
+// cloned if they are maps or slices decoded from JSON.
+func cloneValues(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	c := make([]interface{}, len(values))
+	for i, v := range values {
+		c[i] = cloneJSON(v)
+	}
+	return c
+}
+
+// cloneMap copies a map, such as script parameters or a custom query, along
+// with the maps and slices it contains.
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = cloneJSON(v)
+	}
+	return c
+}
+
+func cloneJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return cloneMap(val)
+	case []interface{}:
+		return cloneValues(val)
+	case []map[string]interface{}:
+		c := make([]map[string]interface{}, len(val))
+		for i, m := range val {
+			c[i] = cloneMap(m)
+		}
+		return c
+	case []string:
+		return cloneStrings(val)
+	}
+	return v
+}
+
+// freeze marks the provided value, along with the bool queries and bucket
+// aggregations it contains, as frozen. Other types have no frozen mode (see
+// the "Reusing Queries" section of the README), and are only protected by
+// accessors returning copies.
+func freeze(node Mappable) {
+	Inspect(node, func(n Mappable) bool {
+		switch n := n.(type) {
+		case *SearchRequest:
+			n.frozen = true
+		case *BoolQuery:
+			n.frozen = true
+		case *TermsAggregation:
+			n.frozen = true
+		case *FilterAggregation:
+			n.frozen = true
+		case *NestedAggregation:
+			n.frozen = true
+		}
+		return true
+	})
+}
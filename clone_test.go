@@ -0,0 +1,291 @@
+package esquery
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		q      Mappable
+		clone  func(Mappable) Mappable
+		modify func(Mappable)
+	}{
+		{
+			"bool query",
+			Bool().Must(Match("title", "openssl")).Filter(Bool().Filter(Term("status", "open"))),
+			func(q Mappable) Mappable { return q.(*BoolQuery).Clone() },
+			func(q Mappable) {
+				b := q.(*BoolQuery)
+				b.Must(Match("body", "overflow"))
+				b.GetMust()[0].(*MatchQuery).Operator(OperatorAnd)
+				b.GetFilter()[0].(*BoolQuery).MustNot(Exists("fixed_at"))
+			},
+		},
+		{
+			"span query",
+			SpanNear(SpanTerm("body", "buffer"), SpanTerm("body", "overflow")).Slop(2),
+			func(q Mappable) Mappable { return q.(*SpanNearQuery).Clone() },
+			func(q Mappable) {
+				s := q.(*SpanNearQuery)
+				s.GetClauses()[0].(*SpanTermQuery).Boost(2)
+				s.Slop(5)
+			},
+		},
+		{
+			"script score query",
+			ScriptScore(Match("title", "openssl"), InlineScript("doc['score'].value").Param("factor", 2)),
+			func(q Mappable) Mappable { return q.(*ScriptScoreQuery).Clone() },
+			func(q Mappable) {
+				s := q.(*ScriptScoreQuery)
+				s.script.Param("factor", 3)
+				s.GetQuery().(*MatchQuery).Fuzziness("AUTO")
+			},
+		},
+		{
+			"custom query",
+			CustomQuery(map[string]interface{}{
+				"term": map[string]interface{}{"status": "open"},
+			}),
+			func(q Mappable) Mappable { return q.(*CustomQueryMap).Clone() },
+			func(q Mappable) {
+				(*q.(*CustomQueryMap))["term"].(map[string]interface{})["status"] = "closed"
+			},
+		},
+		{
+			"aggregation",
+			TermsAgg("by_status", "status").Aggs(Avg("avg_score", "score")),
+			func(q Mappable) Mappable { return q.(*TermsAggregation).Clone() },
+			func(q Mappable) {
+				agg := q.(*TermsAggregation)
+				agg.Size(5)
+				agg.aggs[0].(*AvgAgg).Missing(0)
+			},
+		},
+		{
+			"search request",
+			Search().
+				Query(Bool().Filter(Term("status", "open"))).
+				Aggs(TermsAgg("by_status", "status")).
+				Sort("score", OrderDesc).
+				SortBy(SortField("created_at").Order(OrderDesc)).
+				SourceIncludes("title").
+				Highlight(Highlight().Field("title")).
+				ScriptField("double", InlineScript("doc['score'].value * params.f").Param("f", 2)),
+			func(q Mappable) Mappable { return q.(*SearchRequest).Clone() },
+			func(q Mappable) {
+				req := q.(*SearchRequest)
+				req.GetQuery().(*BoolQuery).Filter(Exists("cve"))
+				req.GetAggs()[0].(*TermsAggregation).Size(5)
+				req.hitOptions.sort[0].fields["score"].(map[string]interface{})["order"] = OrderAsc
+				req.hitOptions.sort[1].sorter.(*FieldSort).Order(OrderAsc)
+				req.hitOptions.source.includes[0] = "body"
+				req.hitOptions.highlight.(*QueryHighlight).PreTags("<b>")
+				req.hitOptions.scriptFields["double"].Param("f", 3)
+				req.Size(10)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, _ := json.Marshal(test.q.Map())
+
+			c := test.clone(test.q)
+			exp, got, ok := sameJSON(test.q.Map(), c.Map())
+			if !ok {
+				t.Fatalf("expected clone %s, got %s", exp, got)
+			}
+
+			test.modify(c)
+			after, _ := json.Marshal(test.q.Map())
+			if string(before) != string(after) {
+				t.Errorf("original was modified from %s to %s", before, after)
+			}
+			modified, _ := json.Marshal(c.Map())
+			if string(before) == string(modified) {
+				t.Errorf("clone was not modified")
+			}
+		})
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	base := Search().
+		Query(Bool().Filter(Term("tenant", "acme"))).
+		Aggs(FilterAgg("open", Term("status", "open")).Aggs(TermsAgg("by_severity", "severity"))).
+		Size(20).
+		Freeze()
+	before, _ := json.Marshal(base.Map())
+
+	derived := base.Size(10)
+	if derived == base {
+		t.Fatal("setter of a frozen request returned the request itself")
+	}
+	derived.From(10)
+	derived.GetQuery().(*BoolQuery).Filter(Term("status", "open"))
+
+	// nested values are frozen as well
+	q := base.query.(*BoolQuery)
+	if q.Must(Match("title", "openssl")) == q {
+		t.Error("setter of a frozen bool query returned the query itself")
+	}
+	agg := base.aggs[0].(*FilterAggregation)
+	if agg.Aggs(Avg("avg_score", "score")) == agg {
+		t.Error("setter of a frozen aggregation returned the aggregation itself")
+	}
+
+	// accessors of frozen values return mutable copies
+	if got := base.GetQuery(); got == base.query || got.(*BoolQuery).frozen {
+		t.Error("accessor of a frozen request returned a frozen value")
+	}
+	base.GetQuery().(*BoolQuery).GetFilter()[0].(*TermQuery).Value("globex")
+	base.GetAggs()[0].(*FilterAggregation).GetFilter().(*TermQuery).Value("closed")
+
+	after, _ := json.Marshal(base.Map())
+	if string(before) != string(after) {
+		t.Errorf("frozen request was modified from %s to %s", before, after)
+	}
+
+	runMapTests(t, []mapTest{
+		{
+			"derived request",
+			derived,
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"filter": []map[string]interface{}{
+							{"term": map[string]interface{}{"tenant": map[string]interface{}{"value": "acme"}}},
+							{"term": map[string]interface{}{"status": map[string]interface{}{"value": "open"}}},
+						},
+					},
+				},
+				"aggs": map[string]interface{}{
+					"open": map[string]interface{}{
+						"filter": map[string]interface{}{
+							"term": map[string]interface{}{"status": map[string]interface{}{"value": "open"}},
+						},
+						"aggs": map[string]interface{}{
+							"by_severity": map[string]interface{}{
+								"terms": map[string]interface{}{"field": "severity"},
+							},
+						},
+					},
+				},
+				"size": 10,
+				"from": 10,
+			},
+		},
+	})
+
+	// the values derived from a frozen one are mutable
+	if derived.Size(5) != derived {
+		t.Error("setter of a derived request returned a copy")
+	}
+	if base.Clone().Size(5) == nil || base.Clone().frozen {
+		t.Error("clone of a frozen request is frozen")
+	}
+}
+
+// TestFreezeCopies checks that the values provided to the setters of a
+// request before it is frozen no longer affect it.
+func TestFreezeCopies(t *testing.T) {
+	match := Match("title", "openssl")
+	avg := Avg("avg_score", "score")
+	sorter := SortField("created_at")
+	base := Search().
+		Query(Bool().Must(match)).
+		Aggs(TermsAgg("by_status", "status").Aggs(avg)).
+		SortBy(sorter).
+		Freeze()
+	before, _ := json.Marshal(base.Map())
+
+	match.Fuzziness("AUTO")
+	avg.Missing(0)
+	sorter.Order(OrderDesc)
+
+	after, _ := json.Marshal(base.Map())
+	if string(before) != string(after) {
+		t.Errorf("frozen request was modified from %s to %s", before, after)
+	}
+}
+
+// TestConcurrentDerivation derives requests from a shared base in several
+// goroutines. It is meant to be run with the race detector.
+func TestConcurrentDerivation(t *testing.T) {
+	base := Search().
+		Query(Bool().Filter(Term("tenant", "acme"))).
+		Aggs(TermsAgg("by_status", "status").Aggs(Avg("avg_score", "score"))).
+		Sort("created_at", OrderDesc).
+		Freeze()
+	before, _ := json.Marshal(base.Map())
+
+	tests := []struct {
+		name   string
+		derive func(i int) *SearchRequest
+	}{
+		{
+			"frozen base",
+			func(i int) *SearchRequest {
+				return base.
+					Query(base.GetQuery().(*BoolQuery).Filter(Term("user", i))).
+					Aggs(Avg("avg_score", "score")).
+					Size(uint64(i))
+			},
+		},
+		{
+			"leaf builders",
+			func(i int) *SearchRequest {
+				q := base.GetQuery().(*BoolQuery)
+				q.GetFilter()[0].(*TermQuery).Value(i)
+				aggs := base.GetAggs()
+				aggs[0].(*TermsAggregation).GetAggs()[0].(*AvgAgg).Missing(i)
+				return base.Query(q).Aggs(aggs...)
+			},
+		},
+		{
+			"cloned base",
+			func(i int) *SearchRequest {
+				req := base.Clone()
+				req.GetQuery().(*BoolQuery).Filter(Term("user", i))
+				req.GetAggs()[0].(*TermsAggregation).Size(uint64(i))
+				return req.Sort("score", OrderAsc)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			results := make([]string, 20)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					b, err := json.Marshal(test.derive(i).Map())
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					results[i] = string(b)
+				}(i)
+			}
+			wg.Wait()
+
+			seen := make(map[string]bool)
+			for i, res := range results {
+				if seen[res] {
+					t.Errorf("request %d is not distinct: %s", i, res)
+				}
+				seen[res] = true
+			}
+
+			after, _ := json.Marshal(base.Map())
+			if string(before) != string(after) {
+				t.Errorf("base was modified from %s to %s", before, after)
+			}
+		})
+	}
+}
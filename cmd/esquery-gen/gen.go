@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aquasecurity/esquery"
+)
+
+// descriptors maps mapping types to the fields package's descriptor types.
+// Types not listed here use fields.Field.
+var descriptors = map[string]string{
+	"keyword":          "Keyword",
+	"constant_keyword": "Keyword",
+	"wildcard":         "Keyword",
+	"text":             "Text",
+	"match_only_text":  "Text",
+	"long":             "Integer",
+	"integer":          "Integer",
+	"short":            "Integer",
+	"byte":             "Integer",
+	"double":           "Numeric",
+	"float":            "Numeric",
+	"half_float":       "Numeric",
+	"scaled_float":     "Numeric",
+	"unsigned_long":    "UnsignedLong",
+	"date":             "Date",
+	"date_nanos":       "Date",
+	"boolean":          "Boolean",
+	"nested":           "Nested",
+}
+
+// node is a field of the mapping, along with its children (object
+// properties, nested fields or multi-fields).
+type node struct {
+	field    *esquery.FieldMapping
+	ident    string
+	children []*node
+}
+
+// descriptor returns the name of the field's descriptor type, or an empty
+// string for objects, which are only generated as a group of children.
+func (n *node) descriptor() string {
+	if n.field.Type == "object" {
+		return ""
+	}
+	if d, ok := descriptors[n.field.Type]; ok {
+		return d
+	}
+	return "Field"
+}
+
+// generate generates the source of a Go package named pkg, declaring a
+// descriptor for every field of the provided schema. The source argument is
+// the name of the mapping file, mentioned in the generated header.
+func generate(schema *esquery.Schema, pkg, source string) ([]byte, error) {
+	roots := tree(schema)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by esquery-gen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import \"github.com/aquasecurity/esquery/fields\"\n")
+
+	for _, n := range roots {
+		if len(n.children) == 0 && n.descriptor() == "" {
+			// empty object
+			continue
+		}
+
+		b.WriteString("\n")
+		writeDoc(&b, n)
+		if len(n.children) == 0 && n.descriptor() != "" {
+			fmt.Fprintf(&b, "const %s fields.%s = %q\n", n.ident, n.descriptor(), n.field.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "var %s = ", n.ident)
+		writeType(&b, n)
+		writeValue(&b, n)
+		b.WriteString("\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// tree arranges the fields of the schema by parent.
+func tree(schema *esquery.Schema) []*node {
+	nodes := make(map[string]*node)
+	var roots []*node
+
+	// fields are sorted by name, so parents come before their children
+	for _, f := range schema.Fields() {
+		n := &node{field: f}
+		nodes[f.Name] = n
+
+		name := f.Name
+		var parent *node
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			parent = nodes[name[:i]]
+			if parent != nil {
+				name = name[i+1:]
+			}
+		}
+
+		if parent == nil {
+			roots = append(roots, n)
+			n.ident = identifier(name, roots[:len(roots)-1], "")
+		} else {
+			parent.children = append(parent.children, n)
+			n.ident = identifier(name, parent.children[:len(parent.children)-1], parent.descriptor())
+		}
+	}
+
+	return roots
+}
+
+// identifier converts a field name to an exported Go identifier that does not
+// conflict with its siblings or with the descriptor embedded by its parent.
+func identifier(name string, siblings []*node, reserved string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	ident := b.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "F" + ident
+	}
+
+	taken := make(map[string]bool, len(siblings)+1)
+	taken[reserved] = true
+	for _, s := range siblings {
+		taken[s.ident] = true
+	}
+
+	candidate := ident
+	for i := 2; taken[candidate] || !token.IsIdentifier(candidate); i++ {
+		candidate = fmt.Sprintf("%s%d", ident, i)
+	}
+	return candidate
+}
+
+func writeDoc(b *bytes.Buffer, n *node) {
+	if n.field.Type == "object" {
+		fmt.Fprintf(b, "// %s is the %q object.\n", n.ident, n.field.Name)
+	} else {
+		fmt.Fprintf(b, "// %s is the %q field, of type %s.\n", n.ident, n.field.Name, n.field.Type)
+	}
+}
+
+// writeType writes the type of a node's descriptor: a descriptor type for
+// leaves, or an anonymous struct embedding the node's descriptor (if any) and
+// holding its children.
+func writeType(b *bytes.Buffer, n *node) {
+	if len(n.children) == 0 {
+		fmt.Fprintf(b, "fields.%s", n.descriptor())
+		return
+	}
+
+	b.WriteString("struct {\n")
+	if d := n.descriptor(); d != "" {
+		fmt.Fprintf(b, "fields.%s\n", d)
+	}
+	for _, c := range sortedChildren(n) {
+		if len(c.children) == 0 && c.descriptor() == "" {
+			continue
+		}
+		fmt.Fprintf(b, "%s ", c.ident)
+		writeType(b, c)
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+}
+
+// writeValue writes the value of a node's descriptor, as a composite literal
+// for nodes with children.
+func writeValue(b *bytes.Buffer, n *node) {
+	if len(n.children) == 0 {
+		fmt.Fprintf(b, "%q", n.field.Name)
+		return
+	}
+
+	b.WriteString("{\n")
+	if d := n.descriptor(); d != "" {
+		fmt.Fprintf(b, "%s: %q,\n", d, n.field.Name)
+	}
+	for _, c := range sortedChildren(n) {
+		if len(c.children) == 0 && c.descriptor() == "" {
+			continue
+		}
+		fmt.Fprintf(b, "%s: ", c.ident)
+		if len(c.children) > 0 {
+			writeType(b, c)
+		}
+		writeValue(b, c)
+		b.WriteString(",\n")
+	}
+	b.WriteString("}")
+}
+
+func sortedChildren(n *node) []*node {
+	children := append([]*node(nil), n.children...)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].ident < children[j].ident
+	})
+	return children
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/esquery"
+)
+
+const testMapping = `{
+	"properties": {
+		"title": {
+			"type": "text",
+			"fields": {
+				"keyword": {"type": "keyword"}
+			}
+		},
+		"status": {"type": "keyword"},
+		"created_at": {"type": "date"},
+		"location": {"type": "geo_point"},
+		"asset": {
+			"properties": {
+				"ip-address": {"type": "ip"},
+				"ip_address": {"type": "ip"}
+			}
+		},
+		"empty": {"type": "object"},
+		"packages": {
+			"type": "nested",
+			"properties": {
+				"name": {"type": "keyword"},
+				"nested": {"type": "long"}
+			}
+		}
+	}
+}`
+
+const expectedCode = `// Code generated by esquery-gen from mapping.json. DO NOT EDIT.
+
+package findings
+
+import "github.com/aquasecurity/esquery/fields"
+
+// Asset is the "asset" object.
+var Asset = struct {
+	IpAddress  fields.Field
+	IpAddress2 fields.Field
+}{
+	IpAddress:  "asset.ip-address",
+	IpAddress2: "asset.ip_address",
+}
+
+// CreatedAt is the "created_at" field, of type date.
+const CreatedAt fields.Date = "created_at"
+
+// Location is the "location" field, of type geo_point.
+const Location fields.Field = "location"
+
+// Packages is the "packages" field, of type nested.
+var Packages = struct {
+	fields.Nested
+	Name    fields.Keyword
+	Nested2 fields.Integer
+}{
+	Nested:  "packages",
+	Name:    "packages.name",
+	Nested2: "packages.nested",
+}
+
+// Status is the "status" field, of type keyword.
+const Status fields.Keyword = "status"
+
+// Title is the "title" field, of type text.
+var Title = struct {
+	fields.Text
+	Keyword fields.Keyword
+}{
+	Text:    "title",
+	Keyword: "title.keyword",
+}
+`
+
+func TestGenerate(t *testing.T) {
+	schema, err := esquery.ParseSchema([]byte(testMapping))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	code, err := generate(schema, "findings", "mapping.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(code) != expectedCode {
+		t.Errorf("unexpected code:\n%s", code)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		reserved string
+		expected string
+	}{
+		{"status", "", "Status"},
+		{"created_at", "", "CreatedAt"},
+		{"ip-address", "", "IpAddress"},
+		{"@timestamp", "", "Timestamp"},
+		{"2fa", "", "F2fa"},
+		{"_", "", "F"},
+		{"text", "Text", "Text2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := identifier(test.name, nil, test.reserved)
+			if got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Command esquery-gen generates a Go package of typed field descriptors (see
+// the github.com/aquasecurity/esquery/fields package) from index mappings in
+// JSON format, as returned by the get mapping API (GET <index>/_mapping) or
+// provided to the create index API.
+//
+// Usage:
+//
+//	esquery-gen [-in mapping.json] [-out fields.go] [-pkg name]
+//
+// The mapping is read from the standard input and the generated code written
+// to the standard output unless files are provided. The package name
+// defaults to the package of the file containing the go:generate directive,
+// so the command is typically invoked as:
+//
+//	//go:generate esquery-gen -in mapping.json -out fields.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aquasecurity/esquery"
+)
+
+func main() {
+	in := flag.String("in", "", "mapping file to read (default: standard input)")
+	out := flag.String("out", "", "Go file to write (default: standard output)")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "name of the generated package")
+	flag.Parse()
+
+	err := run(*in, *out, *pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "esquery-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("no package name provided, use -pkg")
+	}
+
+	var r io.Reader = os.Stdin
+	source := "standard input"
+	if in != "" {
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+		source = filepath.Base(in)
+	}
+
+	schema, err := esquery.LoadSchema(r)
+	if err != nil {
+		return err
+	}
+
+	code, err := generate(schema, pkg, source)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(out, code, 0644)
+}
@@ -1,7 +1,15 @@
 package esquery
 
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
 // Source represents the "_source" option which is commonly accepted in ES
-// queries. Currently, only the "includes" option is supported.
+// queries.
 type Source struct {
 	includes []string
 	excludes []string
@@ -32,3 +40,345 @@ const (
 	// OrderDesc represents sorting in descending order.
 	OrderDesc Order = "desc"
 )
+
+// BoostedField represents a field name with an optional boost, as accepted by
+// queries searching multiple fields (e.g. "title^3").
+type BoostedField struct {
+	Name  string
+	Boost float32
+}
+
+// FieldBoost creates a new field name with the provided boost.
+func FieldBoost(name string, boost float32) BoostedField {
+	return BoostedField{Name: name, Boost: boost}
+}
+
+// String returns the field in the syntax expected by ElasticSearch. The
+// boost is omitted if it is zero.
+func (f BoostedField) String() string {
+	if f.Boost == 0 {
+		return f.Name
+	}
+	return f.Name + "^" + strconv.FormatFloat(float64(f.Boost), 'f', -1, 32)
+}
+
+func boostedFields(fields []BoostedField) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.String()
+	}
+	return names
+}
+
+// fieldNames returns a copy of the provided fields without their boosts,
+// e.g. "title" for "title^3".
+func fieldNames(fields []string) []string {
+	if fields == nil {
+		return nil
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		if j := strings.LastIndexByte(f, '^'); j >= 0 {
+			f = f[:j]
+		}
+		names[i] = f
+	}
+	return names
+}
+
+// GeoPoint represents a geographical point, as accepted by geo-related
+// queries, sorts and score functions.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Map returns a map representation of the point.
+func (p GeoPoint) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"lat": p.Lat,
+		"lon": p.Lon,
+	}
+}
+
+// structValue converts a value stored in an interface{} field of a parameter
+// struct in the same way as the structs package: structs with exported fields
+// (and pointers to them) are converted to maps, and other values are returned
+// as they are. Scalar values are returned without using reflection.
+func structValue(v interface{}) interface{} {
+	if isScalar(v) {
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return v
+	}
+	if m := structs.Map(v); len(m) > 0 {
+		return m
+	}
+	return v
+}
+
+// hitOptions contains the options that control which hits are returned and
+// how they are shaped. It is shared by all request types that return hits
+// (currently SearchRequest and TopHitsAgg), so that they stay in sync.
+type hitOptions struct {
+	from             *uint64
+	size             *uint64
+	sort             []sortKey
+	source           Source
+	highlight        Mappable
+	explain          *bool
+	version          *bool
+	seqNoPrimaryTerm *bool
+	docvalueFields   []docvalueField
+	storedFields     []string
+	scriptFields     map[string]*Script
+}
+
+// sortKey is a sort key added by either the Sort method, as a map, or the
+// SortBy method, as a typed Sorter which is only rendered when the options
+// are, so that it can still be validated and traversed.
+type sortKey struct {
+	fields map[string]interface{}
+	sorter Sorter
+}
+
+// Map returns a map representation of the sort key.
+func (k sortKey) Map() map[string]interface{} {
+	if k.fields != nil {
+		return k.fields
+	}
+	if isNil(k.sorter) {
+		return nil
+	}
+	return k.sorter.Map()
+}
+
+type docvalueField struct {
+	field  string
+	format string
+}
+
+func (opts *hitOptions) addSort(name string, order Order) {
+	opts.sort = append(opts.sort, sortKey{fields: map[string]interface{}{
+		name: map[string]interface{}{
+			"order": order,
+		},
+	}})
+}
+
+func (opts *hitOptions) addSorters(sorts []Sorter) {
+	for _, s := range sorts {
+		opts.sort = append(opts.sort, sortKey{sorter: s})
+	}
+}
+
+// sortMap returns the map representations of the sort keys.
+func (opts *hitOptions) sortMap() Sort {
+	sort := make(Sort, len(opts.sort))
+	for i, k := range opts.sort {
+		sort[i] = k.Map()
+	}
+	return sort
+}
+
+func (opts *hitOptions) addScriptField(name string, script *Script) {
+	if opts.scriptFields == nil {
+		opts.scriptFields = make(map[string]*Script)
+	}
+	opts.scriptFields[name] = script
+}
+
+// clone returns a deep copy of the options.
+func (opts hitOptions) clone() hitOptions {
+	if opts.sort != nil {
+		sort := make([]sortKey, len(opts.sort))
+		for i, k := range opts.sort {
+			sort[i].fields = cloneMap(k.fields)
+			if c, ok := cloneMappable(k.sorter).(Sorter); ok {
+				sort[i].sorter = c
+			}
+		}
+		opts.sort = sort
+	}
+	opts.source.includes = cloneStrings(opts.source.includes)
+	opts.source.excludes = cloneStrings(opts.source.excludes)
+	if opts.highlight != nil {
+		opts.highlight = cloneMappable(opts.highlight)
+	}
+	if opts.docvalueFields != nil {
+		opts.docvalueFields = append([]docvalueField(nil), opts.docvalueFields...)
+	}
+	opts.storedFields = cloneStrings(opts.storedFields)
+	if opts.scriptFields != nil {
+		fields := make(map[string]*Script, len(opts.scriptFields))
+		for name, script := range opts.scriptFields {
+			fields[name] = cloneScript(script)
+		}
+		opts.scriptFields = fields
+	}
+	return opts
+}
+
+// mapInto adds the options that are set to the provided map.
+func (opts *hitOptions) mapInto(m map[string]interface{}) {
+	if opts.from != nil {
+		m["from"] = *opts.from
+	}
+	if opts.size != nil {
+		m["size"] = *opts.size
+	}
+	if len(opts.sort) > 0 {
+		m["sort"] = opts.sortMap()
+	}
+	if opts.explain != nil {
+		m["explain"] = *opts.explain
+	}
+	if opts.version != nil {
+		m["version"] = *opts.version
+	}
+	if opts.seqNoPrimaryTerm != nil {
+		m["seq_no_primary_term"] = *opts.seqNoPrimaryTerm
+	}
+	if opts.highlight != nil {
+		m["highlight"] = opts.highlight.Map()
+	}
+	if len(opts.docvalueFields) > 0 {
+		fields := make([]interface{}, len(opts.docvalueFields))
+		for i, f := range opts.docvalueFields {
+			if f.format == "" {
+				fields[i] = f.field
+			} else {
+				fields[i] = map[string]interface{}{
+					"field":  f.field,
+					"format": f.format,
+				}
+			}
+		}
+		m["docvalue_fields"] = fields
+	}
+	if len(opts.storedFields) > 0 {
+		m["stored_fields"] = opts.storedFields
+	}
+	if len(opts.scriptFields) > 0 {
+		fields := make(map[string]interface{}, len(opts.scriptFields))
+		for name, script := range opts.scriptFields {
+			fields[name] = map[string]interface{}{
+				"script": script.Map(),
+			}
+		}
+		m["script_fields"] = fields
+	}
+
+	source := opts.source.Map()
+	if len(source) > 0 {
+		m["_source"] = source
+	}
+}
+
+// writeSource writes the "_source" field of the options if it is set, see
+// EncodeJSON.
+func (opts *hitOptions) writeSource(e *encoder) {
+	if len(opts.source.includes) == 0 && len(opts.source.excludes) == 0 {
+		return
+	}
+	e.key("_source")
+	e.openObject()
+	if len(opts.source.excludes) > 0 {
+		e.key("excludes")
+		e.strings(opts.source.excludes)
+	}
+	if len(opts.source.includes) > 0 {
+		e.key("includes")
+		e.strings(opts.source.includes)
+	}
+	e.closeObject()
+}
+
+// writeDocvalueFields writes the "docvalue_fields" field of the options if it
+// is set, see EncodeJSON.
+func (opts *hitOptions) writeDocvalueFields(e *encoder) {
+	if len(opts.docvalueFields) == 0 {
+		return
+	}
+	e.key("docvalue_fields")
+	e.openArray()
+	for _, f := range opts.docvalueFields {
+		e.elem()
+		if f.format == "" {
+			e.string(f.field)
+			continue
+		}
+		e.openObject()
+		e.key("field")
+		e.string(f.field)
+		e.key("format")
+		e.string(f.format)
+		e.closeObject()
+	}
+	e.closeArray()
+}
+
+// writeSort writes the "sort" field of the options if it is set, see
+// EncodeJSON.
+func (opts *hitOptions) writeSort(e *encoder) {
+	if len(opts.sort) == 0 {
+		return
+	}
+	e.key("sort")
+	e.openArray()
+	for _, k := range opts.sort {
+		e.elem()
+		switch {
+		case k.fields != nil:
+			e.mapValue(k.fields)
+		case isNil(k.sorter):
+			e.null()
+		default:
+			e.mappable(k.sorter)
+		}
+	}
+	e.closeArray()
+}
+
+// writeScriptFields writes the "script_fields" field of the options if it is
+// set, see EncodeJSON.
+func (opts *hitOptions) writeScriptFields(e *encoder) {
+	if len(opts.scriptFields) == 0 {
+		return
+	}
+	e.key("script_fields")
+	start := len(e.keys)
+	for name := range opts.scriptFields {
+		e.keys = append(e.keys, name)
+	}
+	e.openObject()
+	for _, name := range e.sortKeys(start) {
+		e.stringKey(name)
+		e.openObject()
+		e.key("script")
+		e.mappable(opts.scriptFields[name])
+		e.closeObject()
+	}
+	e.closeObject()
+	e.keys = e.keys[:start]
+}
+
+// validateInto validates the options that are set, recording errors in the
+// provided validation under the provided path.
+func (opts *hitOptions) validateInto(v *validation, path string) {
+	for i, k := range opts.sort {
+		if k.fields == nil {
+			v.child(path+jsonPath("sort", i), k.sorter)
+		}
+	}
+	v.optional(path+"/highlight", opts.highlight)
+	for name, script := range opts.scriptFields {
+		v.child(path+jsonPath("script_fields", name, "script"), script)
+	}
+}
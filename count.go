@@ -2,7 +2,6 @@ package esquery
 
 import (
 	"bytes"
-	"encoding/json"
 
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
@@ -22,17 +21,50 @@ func Count(q Mappable) *CountRequest {
 	}
 }
 
+// GetQuery returns the query of the request.
+func (req *CountRequest) GetQuery() Mappable {
+	return req.query
+}
+
+// Clone returns a deep copy of the request.
+func (req *CountRequest) Clone() *CountRequest {
+	c := *req
+	c.query = cloneMappable(req.query)
+	return &c
+}
+
 // Map returns a map representation of the request, thus implementing the
 // Mappable interface.
 func (req *CountRequest) Map() map[string]interface{} {
-	return map[string]interface{}{
-		"query": req.query.Map(),
+	m := make(map[string]interface{})
+	if req.query != nil {
+		m["query"] = req.query.Map()
+	}
+	return m
+}
+
+// writeJSON writes the request in the same way as Map, see EncodeJSON.
+func (req *CountRequest) writeJSON(e *encoder) {
+	e.openObject()
+	if req.query != nil {
+		e.key("query")
+		e.mappable(req.query)
 	}
+	e.closeObject()
+}
+
+// Validate validates the request's query, implementing the Validator
+// interface.
+func (req *CountRequest) Validate() error {
+	var v validation
+	v.child("/query", req.query)
+	return v.err()
 }
 
 // Run executes the request using the provided ElasticCount client. Zero or
 // more search options can be provided as well. It returns the standard Response
-// type of the official Go client.
+// type of the official Go client. The request is validated first, see
+// Validate.
 func (req *CountRequest) Run(
 	api *elasticsearch.Client,
 	o ...func(*esapi.CountRequest),
@@ -50,13 +82,17 @@ func (req *CountRequest) RunCount(
 	count esapi.Count,
 	o ...func(*esapi.CountRequest),
 ) (res *esapi.Response, err error) {
-	var b bytes.Buffer
-	err = json.NewEncoder(&b).Encode(req.Map())
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := AppendJSON(nil, req)
 	if err != nil {
 		return nil, err
 	}
 
-	opts := append([]func(*esapi.CountRequest){count.WithBody(&b)}, o...)
+	opts := append([]func(*esapi.CountRequest){count.WithBody(bytes.NewReader(body))}, o...)
 
 	return count(opts...)
 }
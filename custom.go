@@ -18,12 +18,24 @@ func CustomQuery(m map[string]interface{}) *CustomQueryMap {
 	return &q
 }
 
+// Clone returns a deep copy of the query.
+func (m *CustomQueryMap) Clone() *CustomQueryMap {
+	c := CustomQueryMap(cloneMap(*m))
+	return &c
+}
+
 // Map returns the custom query as a map[string]interface{}, thus implementing
 // the Mappable interface.
 func (m *CustomQueryMap) Map() map[string]interface{} {
 	return map[string]interface{}(*m)
 }
 
+// Validate implements the Validator interface. Custom queries are not
+// validated.
+func (m *CustomQueryMap) Validate() error {
+	return nil
+}
+
 // Run executes the custom query using the provided ElasticSearch client. Zero
 // or more search options can be provided as well. It returns the standard
 // Response type of the official Go client.
@@ -56,8 +68,21 @@ func (agg *CustomAggMap) Name() string {
 	return agg.name
 }
 
+// Clone returns a deep copy of the aggregation.
+func (agg *CustomAggMap) Clone() *CustomAggMap {
+	c := *agg
+	c.agg = cloneMap(agg.agg)
+	return &c
+}
+
 // Map returns a map representation of the custom aggregation, thus implementing
 // the Mappable interface
 func (agg *CustomAggMap) Map() map[string]interface{} {
 	return agg.agg
 }
+
+// Validate implements the Validator interface. Custom aggregations are not
+// validated.
+func (agg *CustomAggMap) Validate() error {
+	return nil
+}
@@ -33,7 +33,29 @@ func (req *DeleteRequest) Query(q Mappable) *DeleteRequest {
 	return req
 }
 
-// Run executes the request using the provided ElasticSearch client.
+// GetQuery returns the query of the request.
+func (req *DeleteRequest) GetQuery() Mappable {
+	return req.query
+}
+
+// Clone returns a deep copy of the request.
+func (req *DeleteRequest) Clone() *DeleteRequest {
+	c := *req
+	c.index = cloneStrings(req.index)
+	c.query = cloneMappable(req.query)
+	return &c
+}
+
+// Validate validates the request's query, implementing the Validator
+// interface. Unlike search requests, delete requests must have a query.
+func (req *DeleteRequest) Validate() error {
+	var v validation
+	v.child("/query", req.query)
+	return v.err()
+}
+
+// Run executes the request using the provided ElasticSearch client. The
+// request is validated first, see Validate.
 func (req *DeleteRequest) Run(
 	api *elasticsearch.Client,
 	o ...func(*esapi.DeleteByQueryRequest),
@@ -51,6 +73,11 @@ func (req *DeleteRequest) RunDelete(
 	del esapi.DeleteByQuery,
 	o ...func(*esapi.DeleteByQueryRequest),
 ) (res *esapi.Response, err error) {
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	var b bytes.Buffer
 	err = json.NewEncoder(&b).Encode(map[string]interface{}{
 		"query": req.query.Map(),
@@ -0,0 +1,519 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// EncodeJSON writes the JSON encoding of the provided query, aggregation or
+// request to w. The output is identical to that of json.Marshal(m.Map()), but
+// the most common types (search and count requests, bool, term, terms, exists
+// and match_all queries, bucket aggregations and simple metric aggregations)
+// write themselves directly, without building the intermediate maps, and the
+// maps returned by the Map method of other types are written without
+// reflection. Encoding buffers are pooled.
+//
+// Unlike SearchRequest's MarshalJSON method, EncodeJSON doesn't validate the
+// provided value.
+func EncodeJSON(w io.Writer, m Mappable) error {
+	e := encoderPool.Get().(*encoder)
+	defer e.release()
+
+	e.mappable(m)
+	if e.err != nil {
+		return e.err
+	}
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// AppendJSON appends the JSON encoding of the provided query, aggregation or
+// request to dst and returns the extended buffer, see EncodeJSON.
+func AppendJSON(dst []byte, m Mappable) ([]byte, error) {
+	e := encoder{buf: dst}
+	e.mappable(m)
+	if e.err != nil {
+		return dst, e.err
+	}
+	return e.buf, nil
+}
+
+// maxPooledBuffer is the capacity above which encoding buffers are not
+// returned to the pool, so that encoding a few large requests doesn't retain
+// large buffers.
+const maxPooledBuffer = 64 << 10
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		return &encoder{buf: make([]byte, 0, 1024)}
+	},
+}
+
+// encoder writes JSON into a byte buffer. Errors are recorded, and the first
+// one is returned once the value is written. In ordered mode (see
+// MarshalOrdered), values are written through their Map method, and object
+// keys are ordered by orderKeys, depending on the key of their parent object.
+type encoder struct {
+	buf     []byte
+	keys    []string
+	err     error
+	ordered bool
+	parent  string
+}
+
+func (e *encoder) release() {
+	if cap(e.buf) > maxPooledBuffer {
+		return
+	}
+	e.buf = e.buf[:0]
+	e.keys = e.keys[:0]
+	e.err = nil
+	e.ordered = false
+	e.parent = ""
+	encoderPool.Put(e)
+}
+
+// mappable writes the provided value, directly if its type supports it, or
+// through its Map method otherwise. Types are matched exactly, so that types
+// embedding the library's types and overriding their Map method are encoded
+// correctly.
+func (e *encoder) mappable(m Mappable) {
+	if e.ordered && m != nil {
+		e.mapValue(m.Map())
+		return
+	}
+
+	switch n := m.(type) {
+	case nil:
+		e.null()
+	case *SearchRequest:
+		n.writeJSON(e)
+	case *CountRequest:
+		n.writeJSON(e)
+	case *BoolQuery:
+		n.writeJSON(e)
+	case *TermQuery:
+		n.writeJSON(e)
+	case *TermsQuery:
+		n.writeJSON(e)
+	case *ExistsQuery:
+		n.writeJSON(e)
+	case *MatchAllQuery:
+		n.writeJSON(e)
+	case *TermsAggregation:
+		n.writeJSON(e)
+	case *FilterAggregation:
+		n.writeJSON(e)
+	case *NestedAggregation:
+		n.writeJSON(e)
+	case *AvgAgg:
+		n.writeBaseJSON(e)
+	case *MaxAgg:
+		n.writeBaseJSON(e)
+	case *MinAgg:
+		n.writeBaseJSON(e)
+	case *SumAgg:
+		n.writeBaseJSON(e)
+	case *ValueCountAgg:
+		n.writeBaseJSON(e)
+	case *StatsAgg:
+		n.writeBaseJSON(e)
+	default:
+		e.mapValue(m.Map())
+	}
+}
+
+// openObject starts a JSON object. Its fields must be written in the order of
+// their keys, as json.Marshal does for maps.
+func (e *encoder) openObject() {
+	e.buf = append(e.buf, '{')
+}
+
+func (e *encoder) closeObject() {
+	e.buf = append(e.buf, '}')
+}
+
+// key writes the key of an object field, preceded by a comma unless it is
+// the first field of the object. The key must not require escaping.
+func (e *encoder) key(k string) {
+	e.comma('{')
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, k...)
+	e.buf = append(e.buf, '"', ':')
+}
+
+// stringKey writes an object key provided by the user, such as a field name.
+func (e *encoder) stringKey(k string) {
+	e.comma('{')
+	e.string(k)
+	e.buf = append(e.buf, ':')
+}
+
+func (e *encoder) openArray() {
+	e.buf = append(e.buf, '[')
+}
+
+func (e *encoder) closeArray() {
+	e.buf = append(e.buf, ']')
+}
+
+// elem writes the separator preceding an array element.
+func (e *encoder) elem() {
+	e.comma('[')
+}
+
+func (e *encoder) comma(open byte) {
+	if e.buf[len(e.buf)-1] != open {
+		e.buf = append(e.buf, ',')
+	}
+}
+
+func (e *encoder) null() {
+	e.buf = append(e.buf, "null"...)
+}
+
+func (e *encoder) bool(b bool) {
+	e.buf = strconv.AppendBool(e.buf, b)
+}
+
+func (e *encoder) int(i int64) {
+	e.buf = strconv.AppendInt(e.buf, i, 10)
+}
+
+func (e *encoder) uint(u uint64) {
+	e.buf = strconv.AppendUint(e.buf, u, 10)
+}
+
+// float writes a floating point number in the same format as encoding/json.
+func (e *encoder) float(f float64, bits int) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		if bits == 32 {
+			e.marshal(float32(f))
+		} else {
+			e.marshal(f)
+		}
+		return
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+}
+
+const hex = "0123456789abcdef"
+
+// string writes a string in the same format as encoding/json, which escapes
+// HTML characters (except in ordered mode, which is meant to be read).
+// Strings containing control characters or invalid UTF-8, which are escaped
+// differently depending on the Go version, are encoded by encoding/json, see
+// marshalString.
+func (e *encoder) string(s string) {
+	start := len(e.buf)
+	e.buf = append(e.buf, '"')
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			switch b {
+			case '"', '\\':
+				e.buf = append(e.buf, '\\', b)
+			case '\n':
+				e.buf = append(e.buf, '\\', 'n')
+			case '\r':
+				e.buf = append(e.buf, '\\', 'r')
+			case '\t':
+				e.buf = append(e.buf, '\\', 't')
+			case '<', '>', '&':
+				if e.ordered {
+					e.buf = append(e.buf, b)
+					break
+				}
+				e.buf = append(e.buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			default:
+				if b < 0x20 {
+					e.buf = e.buf[:start]
+					e.marshalString(s)
+					return
+				}
+				e.buf = append(e.buf, b)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			e.buf = e.buf[:start]
+			e.marshalString(s)
+			return
+		case r == '\u2028' || r == '\u2029':
+			e.buf = append(e.buf, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			e.buf = append(e.buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	e.buf = append(e.buf, '"')
+}
+
+func (e *encoder) strings(list []string) {
+	if list == nil {
+		e.null()
+		return
+	}
+	e.openArray()
+	for _, s := range list {
+		e.elem()
+		e.string(s)
+	}
+	e.closeArray()
+}
+
+// value writes a value found in a map returned by a Map method, or provided
+// by the user. Values of types other than those returned by the Map methods
+// of the library are encoded by encoding/json.
+func (e *encoder) value(v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		e.null()
+	case string:
+		e.string(val)
+	case bool:
+		e.bool(val)
+	case int:
+		e.int(int64(val))
+	case int8:
+		e.int(int64(val))
+	case int16:
+		e.int(int64(val))
+	case int32:
+		e.int(int64(val))
+	case int64:
+		e.int(val)
+	case uint:
+		e.uint(uint64(val))
+	case uint8:
+		e.uint(uint64(val))
+	case uint16:
+		e.uint(uint64(val))
+	case uint32:
+		e.uint(uint64(val))
+	case uint64:
+		e.uint(val)
+	case float32:
+		e.float(float64(val), 32)
+	case float64:
+		e.float(val, 64)
+	case map[string]interface{}:
+		e.mapValue(val)
+	case map[string]map[string]interface{}:
+		if val == nil {
+			e.null()
+			return
+		}
+		start := len(e.keys)
+		for k := range val {
+			e.keys = append(e.keys, k)
+		}
+		e.openObject()
+		parent := e.parent
+		for _, k := range e.sortKeys(start) {
+			e.stringKey(k)
+			e.parent = k
+			e.mapValue(val[k])
+		}
+		e.parent = parent
+		e.closeObject()
+		e.keys = e.keys[:start]
+	case map[string]string:
+		e.stringMap(val)
+	case []interface{}:
+		if val == nil {
+			e.null()
+			return
+		}
+		e.openArray()
+		for _, elem := range val {
+			e.elem()
+			e.value(elem)
+		}
+		e.closeArray()
+	case []map[string]interface{}:
+		if val == nil {
+			e.null()
+			return
+		}
+		e.openArray()
+		for _, elem := range val {
+			e.elem()
+			e.mapValue(elem)
+		}
+		e.closeArray()
+	case Sort:
+		e.value([]map[string]interface{}(val))
+	case []string:
+		e.strings(val)
+	default:
+		e.marshal(v)
+	}
+}
+
+func (e *encoder) mapValue(m map[string]interface{}) {
+	if m == nil {
+		e.null()
+		return
+	}
+	start := len(e.keys)
+	for k := range m {
+		e.keys = append(e.keys, k)
+	}
+	e.openObject()
+	parent := e.parent
+	for _, k := range e.sortKeys(start) {
+		e.stringKey(k)
+		e.parent = k
+		e.value(m[k])
+	}
+	e.parent = parent
+	e.closeObject()
+	e.keys = e.keys[:start]
+}
+
+func (e *encoder) stringMap(m map[string]string) {
+	if m == nil {
+		e.null()
+		return
+	}
+	start := len(e.keys)
+	for k := range m {
+		e.keys = append(e.keys, k)
+	}
+	e.openObject()
+	for _, k := range e.sortKeys(start) {
+		e.stringKey(k)
+		e.string(m[k])
+	}
+	e.closeObject()
+	e.keys = e.keys[:start]
+}
+
+// sortKeys sorts the map keys collected in e.keys from the provided index,
+// and returns them. The keys of the nested maps are collected after them, and
+// removed once written, so the returned slice remains valid.
+func (e *encoder) sortKeys(start int) []string {
+	keys := e.keys[start:]
+	if e.ordered {
+		orderKeys(keys, e.parent)
+	} else {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// aggs writes a list of aggregations as an object keyed by their names. When
+// several aggregations have the same name, the last one is written, as with
+// the maps built by the Map methods.
+func (e *encoder) aggs(aggs []Aggregation) {
+	var arr [8]int
+	indexes := arr[:0]
+	for i, agg := range aggs {
+		dup := false
+		for j, idx := range indexes {
+			if aggs[idx].Name() == agg.Name() {
+				indexes[j] = i
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			indexes = append(indexes, i)
+		}
+	}
+	// insertion sort, as there are usually few aggregations
+	for i := 1; i < len(indexes); i++ {
+		for j := i; j > 0 && aggs[indexes[j]].Name() < aggs[indexes[j-1]].Name(); j-- {
+			indexes[j], indexes[j-1] = indexes[j-1], indexes[j]
+		}
+	}
+
+	e.openObject()
+	for _, idx := range indexes {
+		e.stringKey(aggs[idx].Name())
+		e.mappable(aggs[idx])
+	}
+	e.closeObject()
+}
+
+// clauses writes a list of queries as an array.
+func (e *encoder) clauses(list []Mappable) {
+	e.openArray()
+	for _, m := range list {
+		e.elem()
+		e.mappable(m)
+	}
+	e.closeArray()
+}
+
+// marshal writes a value using encoding/json.
+func (e *encoder) marshal(v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		if e.err == nil {
+			e.err = err
+		}
+		e.null()
+		return
+	}
+	e.buf = append(e.buf, b...)
+}
+
+// marshalString writes a string using encoding/json. In ordered mode, HTML
+// characters are not escaped, as in the strings written by the encoder.
+func (e *encoder) marshalString(s string) {
+	if !e.ordered {
+		e.marshal(s)
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		if e.err == nil {
+			e.err = err
+		}
+		e.null()
+		return
+	}
+	e.buf = append(e.buf, bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...)
+}
+
+// isScalar reports whether the provided value, stored in a struct converted
+// by the structs package, is written as is by it (structs converts nested
+// structs and maps of structs to maps).
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// overriddenTerm embeds a type encoded by the encoder, but overrides its Map
+// method.
+type overriddenTerm struct {
+	*TermQuery
+}
+
+func (q overriddenTerm) Map() map[string]interface{} {
+	return map[string]interface{}{"match_none": map[string]interface{}{}}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Mappable
+	}{
+		{
+			"strings",
+			Bool().Filter(
+				Term("title", `"quoted" \ <b>tags</b> & more`),
+				Term("title", "tabs\tand\nnew lines\r"),
+				Term("title", "unicode: é, 日本, \u2028, \u2029, 😀"),
+				Term("title", "control \x00\x01\b\f characters"),
+				Term("title", "invalid \xff utf-8"),
+				Term("<field>", "value"),
+			),
+		},
+		{
+			"numbers",
+			Bool().
+				Filter(
+					Term("a", 1e-7),
+					Term("a", 1e21),
+					Term("a", 123456789.125),
+					Term("a", float32(0.1)),
+					Term("a", float32(1e-7)),
+					Term("a", -0.0),
+					Term("a", uint64(math.MaxUint64)),
+					Term("a", int8(-5)),
+					Terms("a", 1.5, uint16(2), int32(-3)),
+				).
+				Boost(1.1).
+				MinimumShouldMatch(-2),
+		},
+		{
+			"terms boost ordering",
+			Bool().Filter(
+				Terms("a", "x").Boost(2),
+				Terms("z", "x").Boost(2),
+				Terms("boost", "x").Boost(2),
+				Terms("nil"),
+			),
+		},
+		{
+			"values converted by the structs package",
+			Bool().Filter(
+				Term("created_at", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+				Term("location", GeoPoint{Lat: 1, Lon: 2}),
+			),
+		},
+		{
+			"aggregations",
+			Search().Aggs(
+				TermsAgg("by_status", "status").
+					Size(10).
+					ShardSize(20.5).
+					ShowTermDocCountError(true).
+					Order(map[string]string{"_key": "asc", "_count": "desc"}).
+					Include("a", "b").
+					Aggs(
+						Avg("score", "score").Missing(0),
+						Max("score", "max_score"),
+						Min("min", "score").Script(InlineScript("doc['score'].value * params.f").Param("f", 2)),
+					),
+				TermsAgg("by_type", "type").Include("a"),
+				FilterAgg("open", Term("status", "open")).Aggs(Sum("total", "score")),
+				NestedAgg("packages", "packages").Aggs(ValueCount("count", "packages.name")),
+				Stats("stats", "score").Missing(map[string]interface{}{"a": 1}),
+				Cardinality("users", "user").PrecisionThreshold(100),
+			),
+		},
+		{
+			"search request",
+			Search().
+				Query(Bool().Must(MatchAll().Boost(2)).MustNot(Exists("fixed_at"), MatchNone())).
+				PostFilter(Range("score").Gte(7)).
+				From(10).
+				Size(20).
+				Sort("created_at", OrderDesc).
+				Sort("_score", OrderAsc).
+				SearchAfter("a", 1, nil).
+				SourceIncludes("title", "description").
+				SourceExcludes("body").
+				Highlight(Highlight().Field("title")).
+				Explain(true).
+				Version(false).
+				SeqNoPrimaryTerm(true).
+				DocvalueFields("created_at").
+				DocvalueFieldFormat("updated_at", "epoch_millis").
+				StoredFields("_none_").
+				ScriptField("b", InlineScript("1")).
+				ScriptField("a", InlineScript("2")).
+				Timeout(1500 * time.Millisecond),
+		},
+		{
+			"count request",
+			Count(Term("status", "open")),
+		},
+		{
+			"embedded types",
+			Bool().Filter(overriddenTerm{Term("a", 1)}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exp, err := json.Marshal(test.q.Map())
+			if err != nil {
+				t.Fatalf("failed marshaling: %s", err)
+			}
+
+			var buf bytes.Buffer
+			if err := EncodeJSON(&buf, test.q); err != nil {
+				t.Fatalf("failed encoding: %s", err)
+			}
+			if !bytes.Equal(buf.Bytes(), exp) {
+				t.Errorf("expected %s, got %s", exp, buf.Bytes())
+			}
+
+			got, err := AppendJSON([]byte("prefix "), test.q)
+			if err != nil {
+				t.Fatalf("failed appending: %s", err)
+			}
+			if string(got) != "prefix "+string(exp) {
+				t.Errorf("expected %s, got %s", exp, got)
+			}
+		})
+	}
+}
+
+func TestEncodeJSONErrors(t *testing.T) {
+	for _, q := range []Mappable{
+		Term("a", math.NaN()),
+		Bool().Filter(Terms("a", math.Inf(1))),
+		Avg("avg", "score").Missing(func() {}),
+	} {
+		if _, err := json.Marshal(q.Map()); err == nil {
+			t.Fatalf("expected json.Marshal to fail for %#v", q)
+		}
+		if err := EncodeJSON(&bytes.Buffer{}, q); err == nil {
+			t.Errorf("expected EncodeJSON to fail for %#v", q)
+		}
+	}
+}
+
+// benchmarkRequest returns a representative search request, with a bool query
+// and aggregations.
+func benchmarkRequest() *SearchRequest {
+	return Search().
+		Query(Bool().
+			Must(Match("title", "openssl")).
+			Filter(
+				Term("tenant", "acme"),
+				Terms("severity", "high", "critical"),
+				Range("score").Gte(7),
+				Bool().Should(Exists("cve"), Term("kev", true)),
+			).
+			MustNot(Exists("fixed_at"))).
+		Aggs(
+			TermsAgg("by_severity", "severity").Size(10).Aggs(
+				Avg("avg_score", "score"),
+				Max("max_score", "score"),
+			),
+			FilterAgg("fixable", Exists("fixed_version")).Aggs(
+				TermsAgg("by_package", "package").Size(20),
+			),
+		).
+		Sort("score", OrderDesc).
+		SourceIncludes("title", "severity", "score").
+		Size(50)
+}
+
+func BenchmarkMapMarshal(b *testing.B) {
+	req := benchmarkRequest()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(req.Map()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	req := benchmarkRequest()
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := EncodeJSON(&buf, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendJSON(b *testing.B) {
+	req := benchmarkRequest()
+	var buf []byte
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = AppendJSON(buf[:0], req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
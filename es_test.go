@@ -1,6 +1,7 @@
 package esquery
 
 import (
+	"bytes"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -25,6 +26,14 @@ func runMapTests(t *testing.T, tests []mapTest) {
 			if !ok {
 				t.Errorf("expected %s, got %s", exp, got)
 			}
+
+			// the streaming encoder must write the same JSON as the map
+			encoded, err := AppendJSON(nil, test.q)
+			if err != nil {
+				t.Errorf("failed encoding: %s", err)
+			} else if !bytes.Equal(encoded, got) {
+				t.Errorf("expected encoding %s, got %s", got, encoded)
+			}
 		})
 	}
 }
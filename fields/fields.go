@@ -0,0 +1,487 @@
+// Package fields provides typed descriptors of index fields, whose methods
+// build the esquery queries and aggregations that suit the type of the
+// field. For example, a Keyword field can be used in a term query or a terms
+// aggregation, while a Text field only provides full text queries, so that
+// a term query on a text field does not compile.
+//
+// Descriptors are usually not created manually, but generated from index
+// mappings by the esquery-gen command:
+//
+//	//go:generate esquery-gen -in mapping.json -out fields.go
+//
+// which generates a constant or variable for every field of the mapping,
+// with subfields (multi-fields, object properties and nested fields) as
+// struct fields:
+//
+//	esquery.Search().
+//	    Query(findings.Title.Match("openssl")).
+//	    Aggs(findings.Status.TermsAgg("by_status"))
+//
+// Type safety does not extend to nested fields: Nested.Query accepts any
+// esquery.Mappable, and the descriptors of a nested field's children can be
+// used outside of a nested query on its path, where they match nothing.
+package fields
+
+import "github.com/aquasecurity/esquery"
+
+// Field is a field of a type not supported by the other descriptors. It only
+// provides the exists query.
+type Field string
+
+// Name returns the full path of the field.
+func (f Field) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Field) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+//----------------------------------------------------------------------------//
+
+// Keyword is a field of type "keyword" (or "constant_keyword" and
+// "wildcard").
+type Keyword string
+
+// Name returns the full path of the field.
+func (f Keyword) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Keyword) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Term creates a new query of type "term" on the field.
+func (f Keyword) Term(value string) *esquery.TermQuery {
+	return esquery.Term(string(f), value)
+}
+
+// Terms creates a new query of type "terms" on the field.
+func (f Keyword) Terms(values ...string) *esquery.TermsQuery {
+	return esquery.Terms(string(f), stringValues(values)...)
+}
+
+// Prefix creates a new query of type "prefix" on the field.
+func (f Keyword) Prefix(value string) *esquery.PrefixQuery {
+	return esquery.Prefix(string(f), value)
+}
+
+// Wildcard creates a new query of type "wildcard" on the field.
+func (f Keyword) Wildcard(value string) *esquery.RegexpQuery {
+	return esquery.Wildcard(string(f), value)
+}
+
+// Regexp creates a new query of type "regexp" on the field.
+func (f Keyword) Regexp(value string) *esquery.RegexpQuery {
+	return esquery.Regexp(string(f), value)
+}
+
+// Fuzzy creates a new query of type "fuzzy" on the field.
+func (f Keyword) Fuzzy(value string) *esquery.FuzzyQuery {
+	return esquery.Fuzzy(string(f), value)
+}
+
+// TermsAgg creates a new aggregation of type "terms" on the field.
+func (f Keyword) TermsAgg(name string) *esquery.TermsAggregation {
+	return esquery.TermsAgg(name, string(f))
+}
+
+// Cardinality creates a new aggregation of type "cardinality" on the field.
+func (f Keyword) Cardinality(name string) *esquery.CardinalityAgg {
+	return esquery.Cardinality(name, string(f))
+}
+
+// ValueCount creates a new aggregation of type "value_count" on the field.
+func (f Keyword) ValueCount(name string) *esquery.ValueCountAgg {
+	return esquery.ValueCount(name, string(f))
+}
+
+// StringStats creates a new aggregation of type "string_stats" on the field.
+func (f Keyword) StringStats(name string) *esquery.StringStatsAgg {
+	return esquery.StringStats(name, string(f))
+}
+
+//----------------------------------------------------------------------------//
+
+// Text is a field of type "text" (or "match_only_text"). Text fields are
+// analyzed, so they only provide full text queries; keyword multi-fields
+// should be used for exact matches and aggregations.
+type Text string
+
+// Name returns the full path of the field.
+func (f Text) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Text) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Match creates a new query of type "match" on the field.
+func (f Text) Match(query string) *esquery.MatchQuery {
+	return esquery.Match(string(f), query)
+}
+
+// MatchPhrase creates a new query of type "match_phrase" on the field.
+func (f Text) MatchPhrase(query string) *esquery.MatchQuery {
+	return esquery.MatchPhrase(string(f), query)
+}
+
+// MatchPhrasePrefix creates a new query of type "match_phrase_prefix" on the
+// field.
+func (f Text) MatchPhrasePrefix(query string) *esquery.MatchQuery {
+	return esquery.MatchPhrasePrefix(string(f), query)
+}
+
+// MatchBoolPrefix creates a new query of type "match_bool_prefix" on the
+// field.
+func (f Text) MatchBoolPrefix(query string) *esquery.MatchQuery {
+	return esquery.MatchBoolPrefix(string(f), query)
+}
+
+//----------------------------------------------------------------------------//
+
+// Numeric is a field of a floating point type ("double", "float",
+// "half_float" or "scaled_float"). Integer types use Integer and
+// UnsignedLong, whose term queries don't round large values.
+type Numeric string
+
+// Name returns the full path of the field.
+func (f Numeric) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Numeric) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Term creates a new query of type "term" on the field.
+func (f Numeric) Term(value float64) *esquery.TermQuery {
+	return esquery.Term(string(f), value)
+}
+
+// Terms creates a new query of type "terms" on the field.
+func (f Numeric) Terms(values ...float64) *esquery.TermsQuery {
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return esquery.Terms(string(f), vals...)
+}
+
+// Range creates a new query of type "range" on the field.
+func (f Numeric) Range() *esquery.RangeQuery {
+	return esquery.Range(string(f))
+}
+
+// TermsAgg creates a new aggregation of type "terms" on the field.
+func (f Numeric) TermsAgg(name string) *esquery.TermsAggregation {
+	return esquery.TermsAgg(name, string(f))
+}
+
+// Avg creates a new aggregation of type "avg" on the field.
+func (f Numeric) Avg(name string) *esquery.AvgAgg {
+	return esquery.Avg(name, string(f))
+}
+
+// Sum creates a new aggregation of type "sum" on the field.
+func (f Numeric) Sum(name string) *esquery.SumAgg {
+	return esquery.Sum(name, string(f))
+}
+
+// Min creates a new aggregation of type "min" on the field.
+func (f Numeric) Min(name string) *esquery.MinAgg {
+	return esquery.Min(name, string(f))
+}
+
+// Max creates a new aggregation of type "max" on the field.
+func (f Numeric) Max(name string) *esquery.MaxAgg {
+	return esquery.Max(name, string(f))
+}
+
+// Stats creates a new aggregation of type "stats" on the field.
+func (f Numeric) Stats(name string) *esquery.StatsAgg {
+	return esquery.Stats(name, string(f))
+}
+
+// Percentiles creates a new aggregation of type "percentiles" on the field.
+func (f Numeric) Percentiles(name string) *esquery.PercentilesAgg {
+	return esquery.Percentiles(name, string(f))
+}
+
+// Cardinality creates a new aggregation of type "cardinality" on the field.
+func (f Numeric) Cardinality(name string) *esquery.CardinalityAgg {
+	return esquery.Cardinality(name, string(f))
+}
+
+// ValueCount creates a new aggregation of type "value_count" on the field.
+func (f Numeric) ValueCount(name string) *esquery.ValueCountAgg {
+	return esquery.ValueCount(name, string(f))
+}
+
+//----------------------------------------------------------------------------//
+
+// Integer is a field of a signed integer type ("long", "integer", "short" or
+// "byte").
+type Integer string
+
+// Name returns the full path of the field.
+func (f Integer) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Integer) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Term creates a new query of type "term" on the field.
+func (f Integer) Term(value int64) *esquery.TermQuery {
+	return esquery.Term(string(f), value)
+}
+
+// Terms creates a new query of type "terms" on the field.
+func (f Integer) Terms(values ...int64) *esquery.TermsQuery {
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return esquery.Terms(string(f), vals...)
+}
+
+// Range creates a new query of type "range" on the field.
+func (f Integer) Range() *esquery.RangeQuery {
+	return Numeric(f).Range()
+}
+
+// TermsAgg creates a new aggregation of type "terms" on the field.
+func (f Integer) TermsAgg(name string) *esquery.TermsAggregation {
+	return Numeric(f).TermsAgg(name)
+}
+
+// Avg creates a new aggregation of type "avg" on the field.
+func (f Integer) Avg(name string) *esquery.AvgAgg {
+	return Numeric(f).Avg(name)
+}
+
+// Sum creates a new aggregation of type "sum" on the field.
+func (f Integer) Sum(name string) *esquery.SumAgg {
+	return Numeric(f).Sum(name)
+}
+
+// Min creates a new aggregation of type "min" on the field.
+func (f Integer) Min(name string) *esquery.MinAgg {
+	return Numeric(f).Min(name)
+}
+
+// Max creates a new aggregation of type "max" on the field.
+func (f Integer) Max(name string) *esquery.MaxAgg {
+	return Numeric(f).Max(name)
+}
+
+// Stats creates a new aggregation of type "stats" on the field.
+func (f Integer) Stats(name string) *esquery.StatsAgg {
+	return Numeric(f).Stats(name)
+}
+
+// Percentiles creates a new aggregation of type "percentiles" on the field.
+func (f Integer) Percentiles(name string) *esquery.PercentilesAgg {
+	return Numeric(f).Percentiles(name)
+}
+
+// Cardinality creates a new aggregation of type "cardinality" on the field.
+func (f Integer) Cardinality(name string) *esquery.CardinalityAgg {
+	return Numeric(f).Cardinality(name)
+}
+
+// ValueCount creates a new aggregation of type "value_count" on the field.
+func (f Integer) ValueCount(name string) *esquery.ValueCountAgg {
+	return Numeric(f).ValueCount(name)
+}
+
+//----------------------------------------------------------------------------//
+
+// UnsignedLong is a field of type "unsigned_long".
+type UnsignedLong string
+
+// Name returns the full path of the field.
+func (f UnsignedLong) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f UnsignedLong) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Term creates a new query of type "term" on the field.
+func (f UnsignedLong) Term(value uint64) *esquery.TermQuery {
+	return esquery.Term(string(f), value)
+}
+
+// Terms creates a new query of type "terms" on the field.
+func (f UnsignedLong) Terms(values ...uint64) *esquery.TermsQuery {
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return esquery.Terms(string(f), vals...)
+}
+
+// Range creates a new query of type "range" on the field.
+func (f UnsignedLong) Range() *esquery.RangeQuery {
+	return Numeric(f).Range()
+}
+
+// TermsAgg creates a new aggregation of type "terms" on the field.
+func (f UnsignedLong) TermsAgg(name string) *esquery.TermsAggregation {
+	return Numeric(f).TermsAgg(name)
+}
+
+// Avg creates a new aggregation of type "avg" on the field.
+func (f UnsignedLong) Avg(name string) *esquery.AvgAgg {
+	return Numeric(f).Avg(name)
+}
+
+// Sum creates a new aggregation of type "sum" on the field.
+func (f UnsignedLong) Sum(name string) *esquery.SumAgg {
+	return Numeric(f).Sum(name)
+}
+
+// Min creates a new aggregation of type "min" on the field.
+func (f UnsignedLong) Min(name string) *esquery.MinAgg {
+	return Numeric(f).Min(name)
+}
+
+// Max creates a new aggregation of type "max" on the field.
+func (f UnsignedLong) Max(name string) *esquery.MaxAgg {
+	return Numeric(f).Max(name)
+}
+
+// Stats creates a new aggregation of type "stats" on the field.
+func (f UnsignedLong) Stats(name string) *esquery.StatsAgg {
+	return Numeric(f).Stats(name)
+}
+
+// Percentiles creates a new aggregation of type "percentiles" on the field.
+func (f UnsignedLong) Percentiles(name string) *esquery.PercentilesAgg {
+	return Numeric(f).Percentiles(name)
+}
+
+// Cardinality creates a new aggregation of type "cardinality" on the field.
+func (f UnsignedLong) Cardinality(name string) *esquery.CardinalityAgg {
+	return Numeric(f).Cardinality(name)
+}
+
+// ValueCount creates a new aggregation of type "value_count" on the field.
+func (f UnsignedLong) ValueCount(name string) *esquery.ValueCountAgg {
+	return Numeric(f).ValueCount(name)
+}
+
+//----------------------------------------------------------------------------//
+
+// Date is a field of type "date" or "date_nanos".
+type Date string
+
+// Name returns the full path of the field.
+func (f Date) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Date) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Term creates a new query of type "term" on the field, with a date or date
+// math expression.
+func (f Date) Term(value string) *esquery.TermQuery {
+	return esquery.Term(string(f), value)
+}
+
+// Range creates a new query of type "range" on the field.
+func (f Date) Range() *esquery.RangeQuery {
+	return esquery.Range(string(f))
+}
+
+// DistanceFeature creates a new query of type "distance_feature" on the
+// field, boosting documents closer to the provided origin (e.g. "now").
+func (f Date) DistanceFeature(origin, pivot string) *esquery.DistanceFeatureQuery {
+	return esquery.DistanceFeature(string(f), origin, pivot)
+}
+
+// Min creates a new aggregation of type "min" on the field.
+func (f Date) Min(name string) *esquery.MinAgg {
+	return esquery.Min(name, string(f))
+}
+
+// Max creates a new aggregation of type "max" on the field.
+func (f Date) Max(name string) *esquery.MaxAgg {
+	return esquery.Max(name, string(f))
+}
+
+// ValueCount creates a new aggregation of type "value_count" on the field.
+func (f Date) ValueCount(name string) *esquery.ValueCountAgg {
+	return esquery.ValueCount(name, string(f))
+}
+
+//----------------------------------------------------------------------------//
+
+// Boolean is a field of type "boolean".
+type Boolean string
+
+// Name returns the full path of the field.
+func (f Boolean) Name() string {
+	return string(f)
+}
+
+// Exists creates a new query of type "exists" on the field.
+func (f Boolean) Exists() *esquery.ExistsQuery {
+	return esquery.Exists(string(f))
+}
+
+// Term creates a new query of type "term" on the field.
+func (f Boolean) Term(value bool) *esquery.TermQuery {
+	return esquery.Term(string(f), value)
+}
+
+// TermsAgg creates a new aggregation of type "terms" on the field.
+func (f Boolean) TermsAgg(name string) *esquery.TermsAggregation {
+	return esquery.TermsAgg(name, string(f))
+}
+
+//----------------------------------------------------------------------------//
+
+// Nested is a field of type "nested". Its children must be queried inside a
+// nested query or aggregation on its path.
+type Nested string
+
+// Name returns the full path of the field.
+func (f Nested) Name() string {
+	return string(f)
+}
+
+// Query creates a new query of type "nested" on the field's path, with the
+// provided query on its children.
+func (f Nested) Query(q esquery.Mappable) *esquery.NestedQuery {
+	return esquery.Nested(string(f), q)
+}
+
+// Agg creates a new aggregation of type "nested" on the field's path. Its
+// sub-aggregations run on the field's children.
+func (f Nested) Agg(name string) *esquery.NestedAggregation {
+	return esquery.NestedAgg(name, string(f))
+}
+
+func stringValues(values []string) []interface{} {
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		vals[i] = v
+	}
+	return vals
+}
@@ -0,0 +1,63 @@
+package fields
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aquasecurity/esquery"
+)
+
+func TestDescriptors(t *testing.T) {
+	const (
+		status  Keyword      = "status"
+		title   Text         = "title"
+		score   Numeric      = "score"
+		id      Integer      = "id"
+		hash    UnsignedLong = "hash"
+		created Date         = "created"
+		fixed   Boolean      = "fixed"
+		pkgs    Nested       = "packages"
+	)
+
+	tests := []struct {
+		name     string
+		got      esquery.Mappable
+		expected esquery.Mappable
+	}{
+		{"keyword term", status.Term("open"), esquery.Term("status", "open")},
+		{"keyword terms", status.Terms("open", "new"), esquery.Terms("status", "open", "new")},
+		{"keyword terms agg", status.TermsAgg("by_status"), esquery.TermsAgg("by_status", "status")},
+		{"text match", title.Match("openssl"), esquery.Match("title", "openssl")},
+		{"text match phrase", title.MatchPhrase("open ssl"), esquery.MatchPhrase("title", "open ssl")},
+		{"numeric range", score.Range().Gte(7.0), esquery.Range("score").Gte(7.0)},
+		{"numeric terms", score.Terms(1, 2), esquery.Terms("score", 1.0, 2.0)},
+		{"numeric avg", score.Avg("avg_score"), esquery.Avg("avg_score", "score")},
+		{"integer term", id.Term(1<<53 + 1), esquery.Term("id", int64(1<<53+1))},
+		{"integer terms", id.Terms(1, 2), esquery.Terms("id", int64(1), int64(2))},
+		{"integer max", id.Max("max_id"), esquery.Max("max_id", "id")},
+		{"unsigned long term", hash.Term(1<<63 + 1), esquery.Term("hash", uint64(1<<63+1))},
+		{"unsigned long range", hash.Range().Lt(uint64(1 << 63)), esquery.Range("hash").Lt(uint64(1 << 63))},
+		{"date range", created.Range().Gte("now-1d"), esquery.Range("created").Gte("now-1d")},
+		{"boolean term", fixed.Term(true), esquery.Term("fixed", true)},
+		{"field exists", Field("location").Exists(), esquery.Exists("location")},
+		{
+			"nested query",
+			pkgs.Query(Keyword("packages.name").Term("openssl")),
+			esquery.Nested("packages", esquery.Term("packages.name", "openssl")),
+		},
+		{
+			"nested agg",
+			pkgs.Agg("packages").Aggs(Keyword("packages.name").TermsAgg("by_name")),
+			esquery.NestedAgg("packages", "packages").Aggs(esquery.TermsAgg("by_name", "packages.name")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, expected := test.got.Map(), test.expected.Map()
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package esquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// FromStruct creates a bool query from the fields of the provided struct (or
+// pointer to a struct), such as a request struct of a filtering API. Only
+// fields with an "esquery" tag are used, in the following format:
+//
+//	esquery:"<field>[,<operator>][,<clause>]"
+//
+// where <field> is the name of the document field to query (defaulting to
+// the name of the struct field), <operator> is one of:
+//
+//   - term: a term query with the field's value (the default for single
+//     values)
+//   - terms: a terms query with the field's values (the default for slices)
+//   - match, match_phrase, prefix, wildcard: the query of the same type
+//   - gt, gte, lt, lte: a bound of a range query. Bounds on the same document
+//     field (and clause) are merged into a single range query
+//   - exists: for boolean fields, an exists query if the value is true, or
+//     an exists query in the must_not clause if the value is false
+//
+// and <clause> is the clause of the bool query the query is added to: filter
+// (the default, except for match and match_phrase, which default to must),
+// must, must_not or should. If there are should clauses, at least one of them
+// must match.
+//
+// Fields with a zero value (including nil pointers and empty slices) are
+// skipped, so pointers should be used to tell unset values from zero values.
+// Embedded structs are traversed, including unexported ones (but not pointers
+// to unexported structs). Fields tagged "-" are ignored.
+func FromStruct(v interface{}) (*BoolQuery, error) {
+	if !structs.IsStruct(v) {
+		return nil, fmt.Errorf("esquery: FromStruct expects a struct, got %T", v)
+	}
+
+	b := structQuery{
+		query:  Bool(),
+		ranges: make(map[string]*RangeQuery),
+	}
+	err := b.addFields(structs.Fields(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.query.should) > 0 {
+		b.query.MinimumShouldMatch(1)
+	}
+	return b.query, nil
+}
+
+// structQuery accumulates the queries generated from the fields of a struct.
+type structQuery struct {
+	query  *BoolQuery
+	ranges map[string]*RangeQuery
+}
+
+func (b *structQuery) addFields(fields []*structs.Field) error {
+	for _, f := range fields {
+		tag := f.Tag("esquery")
+		if tag == "-" {
+			continue
+		}
+		if tag == "" && f.IsEmbedded() {
+			// like encoding/json, the exported fields of unexported embedded
+			// structs are promoted
+			err := b.addEmbedded(f)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if tag == "" || !f.IsExported() {
+			continue
+		}
+
+		if f.IsZero() {
+			continue
+		}
+		err := b.addField(f, tag)
+		if err != nil {
+			return fmt.Errorf("esquery: field %s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (b *structQuery) addEmbedded(f *structs.Field) error {
+	switch f.Kind() {
+	case reflect.Struct:
+		return b.addFields(f.Fields())
+	case reflect.Ptr:
+		// pointers to unexported structs cannot be dereferenced
+		if f.IsExported() && !f.IsZero() && structs.IsStruct(f.Value()) {
+			return b.addFields(structs.Fields(f.Value()))
+		}
+	}
+	return nil
+}
+
+func (b *structQuery) addField(f *structs.Field, tag string) error {
+	parts := strings.Split(tag, ",")
+	if len(parts) > 3 {
+		return fmt.Errorf("invalid tag %q", tag)
+	}
+
+	name := parts[0]
+	if name == "" {
+		name = f.Name()
+	}
+
+	// dereference pointers, nil pointers were already skipped
+	value := reflect.ValueOf(f.Value())
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	isList := value.Kind() == reflect.Slice || value.Kind() == reflect.Array
+	if isList && value.Len() == 0 {
+		return nil
+	}
+
+	op := ""
+	if len(parts) > 1 {
+		op = parts[1]
+	}
+	if op == "" {
+		op = "term"
+		if isList {
+			op = "terms"
+		}
+	}
+
+	clause := "filter"
+	if op == "match" || op == "match_phrase" {
+		clause = "must"
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		clause = parts[2]
+	}
+
+	add, err := b.clause(clause)
+	if err != nil {
+		return err
+	}
+
+	if isList && op != "terms" {
+		return fmt.Errorf("operator %q does not accept a list of values", op)
+	}
+
+	switch op {
+	case "term":
+		add(Term(name, value.Interface()))
+	case "terms":
+		values := make([]interface{}, value.Len())
+		for i := range values {
+			values[i] = value.Index(i).Interface()
+		}
+		if !isList {
+			values = []interface{}{value.Interface()}
+		}
+		add(Terms(name, values...))
+	case "match", "match_phrase", "prefix", "wildcard":
+		s, ok := value.Interface().(string)
+		if !ok && op != "match" {
+			return fmt.Errorf("operator %q requires a string value", op)
+		}
+		switch op {
+		case "match":
+			add(Match(name, value.Interface()))
+		case "match_phrase":
+			add(MatchPhrase(name, s))
+		case "prefix":
+			add(Prefix(name, s))
+		case "wildcard":
+			add(Wildcard(name, s))
+		}
+	case "gt", "gte", "lt", "lte":
+		key := clause + "/" + name
+		r, ok := b.ranges[key]
+		if !ok {
+			r = Range(name)
+			b.ranges[key] = r
+			add(r)
+		}
+		val := value.Interface()
+		switch op {
+		case "gt":
+			r.Gt(val)
+		case "gte":
+			r.Gte(val)
+		case "lt":
+			r.Lt(val)
+		case "lte":
+			r.Lte(val)
+		}
+	case "exists":
+		exists, ok := value.Interface().(bool)
+		if !ok {
+			return fmt.Errorf("operator %q requires a boolean value", op)
+		}
+		if exists {
+			add(Exists(name))
+		} else {
+			b.query.MustNot(Exists(name))
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", op)
+	}
+
+	return nil
+}
+
+// clause returns a function adding queries to the provided clause of the
+// bool query.
+func (b *structQuery) clause(name string) (func(...Mappable) *BoolQuery, error) {
+	switch name {
+	case "filter":
+		return b.query.Filter, nil
+	case "must":
+		return b.query.Must, nil
+	case "must_not":
+		return b.query.MustNot, nil
+	case "should":
+		return b.query.Should, nil
+	default:
+		return nil, fmt.Errorf("unknown clause %q", name)
+	}
+}
@@ -0,0 +1,192 @@
+package esquery
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type pagination struct {
+	Page int `json:"page"`
+}
+
+type findingsFilter struct {
+	pagination
+	*TenantFilter
+	scope
+
+	Severity []string  `esquery:"severity"`
+	Status   string    `esquery:"status"`
+	MinScore *float64  `esquery:"score,gte"`
+	MaxScore *float64  `esquery:"score,lt"`
+	Since    time.Time `esquery:"created_at,gte"`
+	Title    string    `esquery:"title,match"`
+	Phrase   string    `esquery:"description,match_phrase,should"`
+	Path     string    `esquery:"path,prefix,should"`
+	Fixed    *bool     `esquery:"fixed,term,must_not"`
+	HasCVE   *bool     `esquery:"cve,exists"`
+	Ignored  string    `esquery:"-"`
+	Untagged string
+	internal string
+}
+
+type TenantFilter struct {
+	Tenant string `esquery:"tenant"`
+}
+
+type scope struct {
+	Region string `esquery:"region"`
+	zone   string
+}
+
+func TestFromStruct(t *testing.T) {
+	minScore := 7.0
+	maxScore := 9.5
+	fixed := false
+	hasCVE := false
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	fullQuery, err := FromStruct(&findingsFilter{
+		pagination:   pagination{Page: 2},
+		TenantFilter: &TenantFilter{Tenant: "aqua"},
+		scope:        scope{Region: "eu", zone: "eu-1"},
+		Severity:     []string{"high", "critical"},
+		Status:       "open",
+		MinScore:     &minScore,
+		MaxScore:     &maxScore,
+		Since:        since,
+		Title:        "openssl",
+		Phrase:       "buffer overflow",
+		Path:         "/usr/lib",
+		Fixed:        &fixed,
+		HasCVE:       &hasCVE,
+		Ignored:      "ignored",
+		Untagged:     "untagged",
+		internal:     "internal",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	emptyQuery, err := FromStruct(findingsFilter{
+		Severity: []string{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	runMapTests(t, []mapTest{
+		{
+			"all fields set",
+			fullQuery,
+			map[string]interface{}{
+				"bool": map[string]interface{}{
+					"filter": []map[string]interface{}{
+						{"term": map[string]interface{}{
+							"tenant": map[string]interface{}{"value": "aqua"},
+						}},
+						{"term": map[string]interface{}{
+							"region": map[string]interface{}{"value": "eu"},
+						}},
+						{"terms": map[string]interface{}{
+							"severity": []string{"high", "critical"},
+						}},
+						{"term": map[string]interface{}{
+							"status": map[string]interface{}{"value": "open"},
+						}},
+						{"range": map[string]interface{}{
+							"score": map[string]interface{}{
+								"gte": 7.0,
+								"lt":  9.5,
+							},
+						}},
+						{"range": map[string]interface{}{
+							"created_at": map[string]interface{}{
+								"gte": since,
+							},
+						}},
+					},
+					"must": []map[string]interface{}{
+						{"match": map[string]interface{}{
+							"title": map[string]interface{}{"query": "openssl"},
+						}},
+					},
+					"should": []map[string]interface{}{
+						{"match_phrase": map[string]interface{}{
+							"description": map[string]interface{}{"query": "buffer overflow"},
+						}},
+						{"prefix": map[string]interface{}{
+							"path": map[string]interface{}{"value": "/usr/lib"},
+						}},
+					},
+					"must_not": []map[string]interface{}{
+						{"term": map[string]interface{}{
+							"fixed": map[string]interface{}{"value": false},
+						}},
+						{"exists": map[string]interface{}{
+							"field": "cve",
+						}},
+					},
+					"minimum_should_match": 1,
+				},
+			},
+		},
+		{
+			"zero values are skipped",
+			emptyQuery,
+			map[string]interface{}{
+				"bool": map[string]interface{}{},
+			},
+		},
+	})
+}
+
+func TestFromStructErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		err  string
+	}{
+		{"not a struct", "severity", "esquery: FromStruct expects a struct, got string"},
+		{
+			"unknown operator",
+			struct {
+				A string `esquery:"a,near"`
+			}{"x"},
+			`esquery: field A: unknown operator "near"`,
+		},
+		{
+			"unknown clause",
+			struct {
+				A string `esquery:"a,term,maybe"`
+			}{"x"},
+			`esquery: field A: unknown clause "maybe"`,
+		},
+		{
+			"list with a single-value operator",
+			struct {
+				A []string `esquery:"a,prefix"`
+			}{[]string{"x"}},
+			`esquery: field A: operator "prefix" does not accept a list of values`,
+		},
+		{
+			"exists on a non-boolean",
+			struct {
+				A string `esquery:"a,exists"`
+			}{"x"},
+			`esquery: field A: operator "exists" requires a boolean value`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromStruct(test.v)
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error %q, got %q", test.err, err)
+			}
+		})
+	}
+}
@@ -1,13 +1,27 @@
 package esquery
 
-import (
-	"github.com/fatih/structs"
-)
+import "sort"
+
+// Clone returns a deep copy of the highlight.
+func (q *QueryHighlight) Clone() *QueryHighlight {
+	c := *q
+	c.highlightQuery = cloneMappable(q.highlightQuery)
+	if q.fields != nil {
+		c.fields = make(map[string]*QueryHighlight, len(q.fields))
+		for name, h := range q.fields {
+			c.fields[name] = h.Clone()
+		}
+	}
+	c.params.PreTags = cloneStrings(q.params.PreTags)
+	c.params.PostTags = cloneStrings(q.params.PostTags)
+	c.params.MatchedFields = cloneStrings(q.params.MatchedFields)
+	return &c
+}
 
 // Map returns a map representation of the highlight; implementing the
 // Mappable interface.
 func (q *QueryHighlight) Map() map[string]interface{} {
-	results := structs.Map(q.params)
+	results := q.params.toMap()
 	if q.highlightQuery != nil {
 		results["query"] = q.highlightQuery.Map()
 	}
@@ -21,6 +35,23 @@ func (q *QueryHighlight) Map() map[string]interface{} {
 	return results
 }
 
+// Validate validates the highlight query and the options of each field,
+// implementing the Validator interface.
+func (q *QueryHighlight) Validate() error {
+	var v validation
+	v.optional("/query", q.highlightQuery)
+
+	names := make([]string, 0, len(q.fields))
+	for name := range q.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v.optional(jsonPath("fields", name), q.fields[name])
+	}
+	return v.err()
+}
+
 type QueryHighlight struct {
 	highlightQuery Mappable                   `structs:"highlight_query,omitempty"`
 	fields         map[string]*QueryHighlight `structs:"fields"`
@@ -50,6 +81,70 @@ type highlighParams struct {
 	TagsSchema            HighlightTagsSchema      `structs:"tags_schema,string,omitempty"`
 }
 
+// toMap returns a map representation of the parameters, as generated by the
+// structs package from their tags, without using reflection.
+func (params highlighParams) toMap() map[string]interface{} {
+	m := make(map[string]interface{})
+	if params.PreTags != nil {
+		m["pre_tags"] = params.PreTags
+	}
+	if params.PostTags != nil {
+		m["post_tags"] = params.PostTags
+	}
+	if params.FragmentSize != 0 {
+		m["fragment_size"] = params.FragmentSize
+	}
+	if params.NumberOfFragments != 0 {
+		m["number_of_fragments"] = params.NumberOfFragments
+	}
+	if params.Type != 0 {
+		m["type"] = params.Type.String()
+	}
+	if params.BoundaryChars != "" {
+		m["boundary_chars"] = params.BoundaryChars
+	}
+	if params.BoundaryMaxScan != 0 {
+		m["boundary_max_scan"] = params.BoundaryMaxScan
+	}
+	if params.BoundaryScanner != 0 {
+		m["boundary_scanner"] = params.BoundaryScanner.String()
+	}
+	if params.BoundaryScannerLocale != "" {
+		m["boundary_scanner_locale"] = params.BoundaryScannerLocale
+	}
+	if params.Encoder != 0 {
+		m["encoder"] = params.Encoder.String()
+	}
+	if params.ForceSource != nil {
+		m["force_source"] = params.ForceSource
+	}
+	if params.Fragmenter != 0 {
+		m["fragmenter"] = params.Fragmenter.String()
+	}
+	if params.FragmentOffset != 0 {
+		m["fragment_offset"] = params.FragmentOffset
+	}
+	if params.MatchedFields != nil {
+		m["matched_fields"] = params.MatchedFields
+	}
+	if params.NoMatchSize != 0 {
+		m["no_match_size"] = params.NoMatchSize
+	}
+	if params.Order != 0 {
+		m["order"] = params.Order.String()
+	}
+	if params.PhraseLimit != 0 {
+		m["phrase_limit"] = params.PhraseLimit
+	}
+	if params.RequireFieldMatch != nil {
+		m["require_field_match"] = params.RequireFieldMatch
+	}
+	if params.TagsSchema != 0 {
+		m["tags_schema"] = params.TagsSchema.String()
+	}
+	return m
+}
+
 // Highlight creates a new "query" of type "highlight"
 func Highlight() *QueryHighlight {
 	return newHighlight()
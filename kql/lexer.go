@@ -0,0 +1,313 @@
+package kql
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokLiteral
+	tokQuoted
+	tokLParen
+	tokRParen
+	tokLBrace
+	tokRBrace
+	tokColon
+	tokCompare
+	tokAnd
+	tokOr
+	tokNot
+)
+
+// String returns a human readable description of the token kind, used in
+// error messages.
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokLiteral:
+		return "value"
+	case tokQuoted:
+		return "quoted string"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokLBrace:
+		return `"{"`
+	case tokRBrace:
+		return `"}"`
+	case tokColon:
+		return `":"`
+	case tokCompare:
+		return "comparison operator"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	default:
+		return "token"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	pos  int
+
+	// text is the unescaped text of literals and quoted strings, and the
+	// operator of comparisons.
+	text string
+
+	// pattern is the text of a literal in the syntax of ElasticSearch's
+	// wildcard query: unescaped "*" characters are wildcards, while escaped
+	// "*" characters, "?" characters and backslashes are escaped.
+	pattern string
+
+	// wildcard denotes whether a literal contains unescaped "*" characters.
+	wildcard bool
+}
+
+// isSpecial returns whether the character ends a literal.
+func isSpecial(c byte) bool {
+	switch c {
+	case '(', ')', '{', '}', ':', '<', '>', '"':
+		return true
+	}
+	return false
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+// lex splits the query into tokens.
+func lex(input string) ([]token, error) {
+	l := &lexer{input: input}
+
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// skipSpace returns the position of the first non-whitespace character at or
+// after the provided position.
+func (l *lexer) skipSpace(pos int) int {
+	for pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+// keywordAt returns the kind and length of the keyword at the provided
+// position, if any. Keywords are case insensitive, and must be followed by
+// whitespace, an opening parenthesis or the end of the query.
+func (l *lexer) keywordAt(pos int) (tokenKind, int) {
+	for _, kw := range []struct {
+		word string
+		kind tokenKind
+	}{
+		{"and", tokAnd},
+		{"or", tokOr},
+		{"not", tokNot},
+	} {
+		end := pos + len(kw.word)
+		if end > len(l.input) || !strings.EqualFold(l.input[pos:end], kw.word) {
+			continue
+		}
+		if end == len(l.input) || l.input[end] == '(' {
+			return kw.kind, len(kw.word)
+		}
+		if r, _ := utf8.DecodeRuneInString(l.input[end:]); unicode.IsSpace(r) {
+			return kw.kind, len(kw.word)
+		}
+	}
+	return tokEOF, 0
+}
+
+func (l *lexer) next() (token, error) {
+	l.pos = l.skipSpace(l.pos)
+
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	single := func(kind tokenKind) (token, error) {
+		l.pos++
+		return token{kind: kind, pos: start, text: l.input[start:l.pos]}, nil
+	}
+
+	switch l.input[l.pos] {
+	case '(':
+		return single(tokLParen)
+	case ')':
+		return single(tokRParen)
+	case '{':
+		return single(tokLBrace)
+	case '}':
+		return single(tokRBrace)
+	case ':':
+		return single(tokColon)
+	case '<', '>':
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+		}
+		return token{kind: tokCompare, pos: start, text: l.input[start:l.pos]}, nil
+	case '"':
+		return l.quoted()
+	}
+
+	if kind, n := l.keywordAt(l.pos); n > 0 {
+		l.pos += n
+		return token{kind: kind, pos: start, text: l.input[start:l.pos]}, nil
+	}
+
+	return l.literal()
+}
+
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.input):
+			switch e := l.input[l.pos+1]; e {
+			case '"', '\\':
+				b.WriteByte(e)
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'n':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(c)
+				b.WriteByte(e)
+			}
+			l.pos += 2
+		case c == '"':
+			l.pos++
+			return token{kind: tokQuoted, pos: start, text: b.String()}, nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, errorf(start, "unterminated quoted string")
+}
+
+// literal lexes an unquoted value. Unquoted values may contain whitespace,
+// they end at a special character, at a keyword or at the end of the query.
+func (l *lexer) literal() (token, error) {
+	start := l.pos
+
+	var text, pattern strings.Builder
+	var wildcard bool
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+
+		if unicode.IsSpace(r) {
+			next := l.skipSpace(l.pos)
+			if next == len(l.input) || isSpecial(l.input[next]) {
+				break
+			}
+			if _, n := l.keywordAt(next); n > 0 {
+				break
+			}
+			text.WriteString(l.input[l.pos:next])
+			pattern.WriteString(l.input[l.pos:next])
+			l.pos = next
+			continue
+		}
+
+		if r < utf8.RuneSelf && isSpecial(byte(r)) {
+			break
+		}
+
+		switch r {
+		case '\\':
+			if err := l.escape(&text, &pattern); err != nil {
+				return token{}, err
+			}
+			continue
+		case '*':
+			wildcard = true
+			pattern.WriteByte('*')
+		case '?':
+			pattern.WriteString(`\?`)
+		default:
+			pattern.WriteRune(r)
+		}
+		text.WriteRune(r)
+		l.pos += size
+	}
+
+	return token{
+		kind:     tokLiteral,
+		pos:      start,
+		text:     text.String(),
+		pattern:  pattern.String(),
+		wildcard: wildcard,
+	}, nil
+}
+
+// escape lexes an escape sequence inside an unquoted value.
+func (l *lexer) escape(text, pattern *strings.Builder) error {
+	start := l.pos
+	rest := l.input[l.pos+1:]
+
+	for _, kw := range []string{"and", "or", "not"} {
+		if len(rest) >= len(kw) && strings.EqualFold(rest[:len(kw)], kw) {
+			text.WriteString(rest[:len(kw)])
+			pattern.WriteString(rest[:len(kw)])
+			l.pos += 1 + len(kw)
+			return nil
+		}
+	}
+
+	if rest == "" {
+		return errorf(start, "escape character at end of query")
+	}
+
+	switch c := rest[0]; c {
+	case '\\', '*':
+		text.WriteByte(c)
+		pattern.WriteByte('\\')
+		pattern.WriteByte(c)
+	case '(', ')', '{', '}', ':', '<', '>', '"':
+		text.WriteByte(c)
+		pattern.WriteByte(c)
+	case 't', 'r', 'n':
+		ws := map[byte]byte{'t': '\t', 'r': '\r', 'n': '\n'}[c]
+		text.WriteByte(ws)
+		pattern.WriteByte(ws)
+	default:
+		return errorf(start, "invalid escape sequence")
+	}
+	l.pos += 2
+	return nil
+}
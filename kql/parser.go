@@ -0,0 +1,401 @@
+// Package kql translates queries written in the Kibana Query Language (KQL)
+// into esquery trees, following the semantics of Kibana's own translation so
+// that expressions copied from a Kibana filter bar return the same results.
+//
+// The following syntax is supported:
+//
+//	response:200                    match query
+//	message:"remote code"           match_phrase query
+//	host.name:web*                  wildcard query
+//	host.name:*                     exists query
+//	bytes >= 1000                   range query ("<", "<=", ">", ">=")
+//	response:(200 or 404)           values combined with and/or/not
+//	items:{ name:x and price > 5 }  nested query on the "items" path
+//	a and b, a or b, not a          boolean operators (case insensitive)
+//	(a or b) and c                  grouping
+//	"remote code", remote*          multi_match and query_string queries on
+//	                                the index's default fields
+//
+// Boolean operators are translated the way Kibana does: "and" produces the
+// filter clauses of a bool query, "or" produces should clauses with
+// minimum_should_match set to 1, and "not" produces a must_not clause.
+package kql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aquasecurity/esquery"
+)
+
+// Error is the error type returned when a query cannot be parsed. Its
+// position points at the offending token.
+type Error struct {
+	// Pos is the byte offset in the query at which the error was detected.
+	Pos int
+
+	// Msg describes the error.
+	Msg string
+}
+
+// Error returns a string representation of the error, implementing the error
+// interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("kql: %s at position %d", e.Msg, e.Pos)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parser translates KQL queries into esquery trees. The zero value is a
+// parser that forbids leading wildcards.
+type Parser struct {
+	// AllowLeadingWildcard allows values to start with "*", which is
+	// expensive on large indices. Kibana allows them by default.
+	AllowLeadingWildcard bool
+}
+
+// Parse translates the provided query using a zero-value Parser.
+func Parse(query string) (esquery.Mappable, error) {
+	var p Parser
+	return p.Parse(query)
+}
+
+// Parse translates the provided query. An empty query matches all documents.
+// Errors are always of type *Error.
+func (p *Parser) Parse(query string) (esquery.Mappable, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &parseState{Parser: p, tokens: tokens}
+	if st.peek().kind == tokEOF {
+		return esquery.MatchAll(), nil
+	}
+
+	q, err := st.orQuery("")
+	if err != nil {
+		return nil, err
+	}
+	if t := st.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "expected AND, OR or end of query, found %s", t.kind)
+	}
+	return q, nil
+}
+
+type parseState struct {
+	*Parser
+	tokens []token
+	pos    int
+}
+
+func (st *parseState) peek() token {
+	return st.tokens[st.pos]
+}
+
+func (st *parseState) peekAt(offset int) token {
+	if st.pos+offset >= len(st.tokens) {
+		return st.tokens[len(st.tokens)-1]
+	}
+	return st.tokens[st.pos+offset]
+}
+
+func (st *parseState) next() token {
+	t := st.tokens[st.pos]
+	if t.kind != tokEOF {
+		st.pos++
+	}
+	return t
+}
+
+// or combines queries of which at least one must match.
+func or(queries []esquery.Mappable) esquery.Mappable {
+	if len(queries) == 1 {
+		return queries[0]
+	}
+	return esquery.Bool().Should(queries...).MinimumShouldMatch(1)
+}
+
+// and combines queries that must all match.
+func and(queries []esquery.Mappable) esquery.Mappable {
+	if len(queries) == 1 {
+		return queries[0]
+	}
+	return esquery.Bool().Filter(queries...)
+}
+
+// not negates a query.
+func not(q esquery.Mappable) esquery.Mappable {
+	return esquery.Bool().MustNot(q)
+}
+
+// orQuery parses queries separated by OR operators. Field names are prefixed
+// with the provided nested path, if any.
+func (st *parseState) orQuery(path string) (esquery.Mappable, error) {
+	var queries []esquery.Mappable
+	for {
+		q, err := st.andQuery(path)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+		if st.peek().kind != tokOr {
+			return or(queries), nil
+		}
+		st.next()
+	}
+}
+
+func (st *parseState) andQuery(path string) (esquery.Mappable, error) {
+	var queries []esquery.Mappable
+	for {
+		q, err := st.notQuery(path)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+		if st.peek().kind != tokAnd {
+			return and(queries), nil
+		}
+		st.next()
+	}
+}
+
+func (st *parseState) notQuery(path string) (esquery.Mappable, error) {
+	if st.peek().kind != tokNot {
+		return st.subQuery(path)
+	}
+	st.next()
+	q, err := st.notQuery(path)
+	if err != nil {
+		return nil, err
+	}
+	return not(q), nil
+}
+
+func (st *parseState) subQuery(path string) (esquery.Mappable, error) {
+	t := st.peek()
+	switch {
+	case t.kind == tokLParen:
+		st.next()
+		q, err := st.orQuery(path)
+		if err != nil {
+			return nil, err
+		}
+		if t := st.next(); t.kind != tokRParen {
+			return nil, errorf(t.pos, `expected AND, OR or ")", found %s`, t.kind)
+		}
+		return q, nil
+	case t.kind == tokLiteral && st.peekAt(1).kind == tokColon:
+		return st.fieldValue(path)
+	case t.kind == tokLiteral && st.peekAt(1).kind == tokCompare:
+		return st.fieldRange(path)
+	case t.kind == tokLiteral || t.kind == tokQuoted:
+		st.next()
+		return st.is(nil, t)
+	}
+	return nil, errorf(t.pos, "expected a query, found %s", t.kind)
+}
+
+// field is a field name referenced by a query.
+type field struct {
+	name     string
+	pos      int
+	wildcard bool
+}
+
+func (st *parseState) field(path string) field {
+	t := st.next()
+	name := t.text
+	if path != "" {
+		name = path + "." + name
+	}
+	return field{name: name, pos: t.pos, wildcard: t.wildcard}
+}
+
+// fieldValue parses a query of the form field:value, field:(values) or
+// field:{nested query}.
+func (st *parseState) fieldValue(path string) (esquery.Mappable, error) {
+	f := st.field(path)
+	st.next() // the colon
+
+	if open := st.peek(); open.kind == tokLBrace {
+		if f.wildcard {
+			return nil, errorf(f.pos, "wildcards are not supported in nested paths")
+		}
+		st.next()
+		q, err := st.orQuery(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if t := st.next(); t.kind != tokRBrace {
+			return nil, errorf(t.pos, `expected AND, OR or "}", found %s`, t.kind)
+		}
+		return esquery.Nested(f.name, q).ScoreMode(esquery.NestedScoreNone), nil
+	}
+
+	// boolean operators only combine values inside parentheses
+	t := st.next()
+	switch t.kind {
+	case tokLParen:
+		return st.valueList(&f)
+	case tokLiteral, tokQuoted:
+		return st.is(&f, t)
+	}
+	return nil, errorf(t.pos, "expected a value, found %s", t.kind)
+}
+
+// valueList parses the remainder of a parenthesized list of values combined
+// with boolean operators.
+func (st *parseState) valueList(f *field) (esquery.Mappable, error) {
+	q, err := st.orValues(f)
+	if err != nil {
+		return nil, err
+	}
+	if t := st.next(); t.kind != tokRParen {
+		return nil, errorf(t.pos, `expected AND, OR or ")", found %s`, t.kind)
+	}
+	return q, nil
+}
+
+func (st *parseState) orValues(f *field) (esquery.Mappable, error) {
+	var queries []esquery.Mappable
+	for {
+		q, err := st.andValues(f)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+		if st.peek().kind != tokOr {
+			return or(queries), nil
+		}
+		st.next()
+	}
+}
+
+func (st *parseState) andValues(f *field) (esquery.Mappable, error) {
+	var queries []esquery.Mappable
+	for {
+		q, err := st.notValues(f)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+		if st.peek().kind != tokAnd {
+			return and(queries), nil
+		}
+		st.next()
+	}
+}
+
+func (st *parseState) notValues(f *field) (esquery.Mappable, error) {
+	t := st.next()
+	switch t.kind {
+	case tokNot:
+		q, err := st.notValues(f)
+		if err != nil {
+			return nil, err
+		}
+		return not(q), nil
+	case tokLParen:
+		return st.valueList(f)
+	case tokLiteral, tokQuoted:
+		return st.is(f, t)
+	}
+	return nil, errorf(t.pos, "expected a value, found %s", t.kind)
+}
+
+// is translates a single value searched in the provided field, or in the
+// index's default fields if the field is nil.
+func (st *parseState) is(f *field, v token) (esquery.Mappable, error) {
+	quoted := v.kind == tokQuoted
+	if !quoted && v.wildcard && v.pattern == "*" {
+		if f == nil || f.name == "*" {
+			return esquery.MatchAll(), nil
+		}
+		return esquery.Exists(f.name), nil
+	}
+
+	if !quoted && v.wildcard && !st.AllowLeadingWildcard && v.pattern[0] == '*' {
+		return nil, errorf(v.pos, "leading wildcards are not allowed")
+	}
+
+	switch {
+	case f == nil:
+		switch {
+		case quoted:
+			return esquery.MultiMatch(v.text).Type(esquery.MatchTypePhrase).Lenient(true), nil
+		case v.wildcard:
+			return esquery.QueryString(queryStringPattern(v.pattern)), nil
+		}
+		return esquery.MultiMatch(v.text).Lenient(true), nil
+	case f.wildcard:
+		// field name patterns are expanded by ElasticSearch, which only
+		// supports them in queries that accept a list of fields
+		switch {
+		case quoted:
+			return esquery.MultiMatch(v.text).Fields(f.name).Type(esquery.MatchTypePhrase).Lenient(true), nil
+		case v.wildcard:
+			return esquery.QueryString(queryStringPattern(v.pattern)).Fields(f.name), nil
+		}
+		return esquery.MultiMatch(v.text).Fields(f.name).Lenient(true), nil
+	case quoted:
+		return esquery.MatchPhrase(f.name, v.text), nil
+	case v.wildcard:
+		return esquery.Wildcard(f.name, v.pattern), nil
+	}
+	return esquery.Match(f.name, v.text), nil
+}
+
+// fieldRange parses a query of the form field <op> value.
+func (st *parseState) fieldRange(path string) (esquery.Mappable, error) {
+	f := st.field(path)
+	if f.wildcard {
+		return nil, errorf(f.pos, "wildcards are not supported in range field names")
+	}
+	op := st.next()
+
+	v := st.next()
+	if (v.kind != tokLiteral || v.wildcard) && v.kind != tokQuoted {
+		return nil, errorf(v.pos, "expected a value, found %s", v.kind)
+	}
+
+	q := esquery.Range(f.name)
+	switch op.text {
+	case ">":
+		q.Gt(v.text)
+	case ">=":
+		q.Gte(v.text)
+	case "<":
+		q.Lt(v.text)
+	case "<=":
+		q.Lte(v.text)
+	}
+	return q, nil
+}
+
+// queryStringPattern converts a wildcard pattern into the query_string
+// syntax, escaping the characters that are special to it.
+func queryStringPattern(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			// the pattern's escape sequences are valid in query_string
+			b.WriteByte(c)
+			b.WriteByte(pattern[i+1])
+			i++
+		case c == '*':
+			b.WriteByte(c)
+		case strings.IndexByte(`+-=&|><!(){}[]^"~?:/ `, c) >= 0:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
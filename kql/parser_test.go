@@ -0,0 +1,173 @@
+package kql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aquasecurity/esquery"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		exp    esquery.Mappable
+	}{
+		{
+			"wildcard and negation",
+			Parser{},
+			`host.name: web* and not response: 500`,
+			esquery.Bool().Filter(
+				esquery.Wildcard("host.name", "web*"),
+				esquery.Bool().MustNot(esquery.Match("response", "500")),
+			),
+		},
+		{
+			"or, grouping and case insensitive operators",
+			Parser{},
+			`(status:open OR status:pending) AND severity >= 7`,
+			esquery.Bool().Filter(
+				esquery.Bool().Should(
+					esquery.Match("status", "open"),
+					esquery.Match("status", "pending"),
+				).MinimumShouldMatch(1),
+				esquery.Range("severity").Gte("7"),
+			),
+		},
+		{
+			"multi-word values and phrases",
+			Parser{},
+			`message: quick brown fox or title:"remote code"`,
+			esquery.Bool().Should(
+				esquery.Match("message", "quick brown fox"),
+				esquery.MatchPhrase("title", "remote code"),
+			).MinimumShouldMatch(1),
+		},
+		{
+			"value lists",
+			Parser{},
+			`response:(200 or 404) and tags:(a and not b)`,
+			esquery.Bool().Filter(
+				esquery.Bool().Should(
+					esquery.Match("response", "200"),
+					esquery.Match("response", "404"),
+				).MinimumShouldMatch(1),
+				esquery.Bool().Filter(
+					esquery.Match("tags", "a"),
+					esquery.Bool().MustNot(esquery.Match("tags", "b")),
+				),
+			),
+		},
+		{
+			"nested queries",
+			Parser{},
+			`items:{ name: x and price > 5 }`,
+			esquery.Nested("items", esquery.Bool().Filter(
+				esquery.Match("items.name", "x"),
+				esquery.Range("items.price").Gt("5"),
+			)).ScoreMode(esquery.NestedScoreNone),
+		},
+		{
+			"exists and match all",
+			Parser{},
+			`owner:* or *:*`,
+			esquery.Bool().Should(
+				esquery.Exists("owner"),
+				esquery.MatchAll(),
+			).MinimumShouldMatch(1),
+		},
+		{
+			"unfielded values",
+			Parser{},
+			`kimchy and "remote code" and web\*server*`,
+			esquery.Bool().Filter(
+				esquery.MultiMatch("kimchy").Lenient(true),
+				esquery.MultiMatch("remote code").Type(esquery.MatchTypePhrase).Lenient(true),
+				esquery.QueryString(`web\*server*`),
+			),
+		},
+		{
+			"field name patterns",
+			Parser{},
+			`host.*: web and host.*: "web 1" and host.*: web?*`,
+			esquery.Bool().Filter(
+				esquery.MultiMatch("web").Fields("host.*").Lenient(true),
+				esquery.MultiMatch("web 1").Fields("host.*").Type(esquery.MatchTypePhrase).Lenient(true),
+				esquery.QueryString(`web\?*`).Fields("host.*"),
+			),
+		},
+		{
+			"escaped characters and keywords",
+			Parser{},
+			`path: c\:\\temp\* and title: cats \and dogs`,
+			esquery.Bool().Filter(
+				esquery.Match("path", `c:\temp*`),
+				esquery.Match("title", "cats and dogs"),
+			),
+		},
+		{
+			"leading wildcards",
+			Parser{AllowLeadingWildcard: true},
+			`host:*web`,
+			esquery.Wildcard("host", "*web"),
+		},
+		{
+			"empty query",
+			Parser{},
+			"  ",
+			esquery.MatchAll(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := test.parser.Parse(test.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			exp, _ := json.Marshal(test.exp.Map())
+			got, _ := json.Marshal(q.Map())
+			if string(exp) != string(got) {
+				t.Errorf("expected %s, got %s", exp, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		pos   int
+		msg   string
+	}{
+		{"unterminated quoted string", `title:"quick`, 6, "unterminated quoted string"},
+		{"missing parenthesis", `a:1 and (b:2 or c:3`, 19, `expected AND, OR or ")", found end of query`},
+		{"missing brace", `items:{ name:x`, 14, `expected AND, OR or "}", found end of query`},
+		{"dangling operator", `a:1 and`, 7, "expected a query, found end of query"},
+		{"missing value", `a:`, 2, "expected a value, found end of query"},
+		{"trailing tokens", `a:1 b:2`, 5, `expected AND, OR or end of query, found ":"`},
+		{"wildcard range", `a > 1*`, 4, "expected a value, found value"},
+		{"wildcard range field", `a* > 1`, 0, "wildcards are not supported in range field names"},
+		{"invalid escape", `a:\x`, 2, "invalid escape sequence"},
+		{"leading wildcard", `host:*web`, 5, "leading wildcards are not allowed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.query)
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected a *Error, got %T", err)
+			}
+			if e.Pos != test.pos || e.Msg != test.msg {
+				t.Errorf("expected %q at %d, got %q at %d", test.msg, test.pos, e.Msg, e.Pos)
+			}
+		})
+	}
+}
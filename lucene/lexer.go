@@ -0,0 +1,320 @@
+package lucene
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokTerm
+	tokPhrase
+	tokRegexp
+	tokLParen
+	tokRParen
+	tokColon
+	tokCaret
+	tokTilde
+	tokPlus
+	tokMinus
+	tokNot
+	tokAnd
+	tokOr
+	tokTo
+	tokRangeStart
+	tokRangeEnd
+	tokCompare
+)
+
+// String returns a human readable description of the token kind, used in
+// error messages.
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokTerm:
+		return "term"
+	case tokPhrase:
+		return "phrase"
+	case tokRegexp:
+		return "regular expression"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokColon:
+		return `":"`
+	case tokCaret:
+		return `"^"`
+	case tokTilde:
+		return `"~"`
+	case tokPlus:
+		return `"+"`
+	case tokMinus:
+		return `"-"`
+	case tokNot:
+		return "NOT"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokTo:
+		return "TO"
+	case tokRangeStart:
+		return "range"
+	case tokRangeEnd:
+		return "end of range"
+	case tokCompare:
+		return "comparison operator"
+	default:
+		return "token"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	pos  int
+
+	// text is the unescaped text of terms, phrases and regular expressions,
+	// the number attached to "^" and "~", and the operator of comparisons
+	// and range delimiters.
+	text string
+
+	// pattern is the text of a term with escaped wildcard characters kept
+	// escaped, for use in wildcard queries.
+	pattern string
+
+	// wildcard denotes whether a term contains unescaped wildcard
+	// characters.
+	wildcard bool
+}
+
+// isSpecial returns whether the character ends a term.
+func isSpecial(r rune, inRange bool) bool {
+	switch r {
+	case '(', ')', '^', '~', '[', ']', '{', '}', '"':
+		return true
+	case ':':
+		return !inRange
+	}
+	return false
+}
+
+type lexer struct {
+	input   string
+	pos     int
+	inRange bool
+	tokens  []token
+}
+
+// lex splits the query into tokens.
+func lex(input string) ([]token, error) {
+	l := &lexer{input: input}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+func (l *lexer) peekRune(offset int) rune {
+	if l.pos+offset >= len(l.input) {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos+offset:])
+	return r
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	single := func(kind tokenKind) (token, error) {
+		l.pos++
+		return token{kind: kind, pos: start, text: l.input[start:l.pos]}, nil
+	}
+
+	switch c := l.input[l.pos]; c {
+	case '(':
+		return single(tokLParen)
+	case ')':
+		return single(tokRParen)
+	case ':':
+		return single(tokColon)
+	case '+':
+		return single(tokPlus)
+	case '-':
+		// a minus sign inside a range or after a comparison operator is the
+		// sign of a negative bound, not the prohibit operator
+		if l.inRange || l.afterCompare() {
+			return l.term()
+		}
+		return single(tokMinus)
+	case '[', '{':
+		l.inRange = true
+		return single(tokRangeStart)
+	case ']', '}':
+		l.inRange = false
+		return single(tokRangeEnd)
+	case '!':
+		return single(tokNot)
+	case '&', '|':
+		if l.peekRune(1) == rune(c) {
+			l.pos += 2
+			if c == '&' {
+				return token{kind: tokAnd, pos: start, text: "&&"}, nil
+			}
+			return token{kind: tokOr, pos: start, text: "||"}, nil
+		}
+	case '>', '<':
+		l.pos++
+		if l.peekRune(0) == '=' {
+			l.pos++
+		}
+		return token{kind: tokCompare, pos: start, text: l.input[start:l.pos]}, nil
+	case '^', '~':
+		l.pos++
+		for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+			l.pos++
+		}
+		kind := tokCaret
+		if c == '~' {
+			kind = tokTilde
+		}
+		return token{kind: kind, pos: start, text: l.input[start+1 : l.pos]}, nil
+	case '"':
+		return l.phrase()
+	case '/':
+		return l.regexp()
+	}
+
+	return l.term()
+}
+
+// afterCompare returns whether the last token is a comparison operator.
+func (l *lexer) afterCompare() bool {
+	return len(l.tokens) > 0 && l.tokens[len(l.tokens)-1].kind == tokCompare
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (l *lexer) phrase() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.input):
+			b.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+		case c == '"':
+			l.pos++
+			return token{kind: tokPhrase, pos: start, text: b.String()}, nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, errorf(start, "unterminated phrase")
+}
+
+func (l *lexer) regexp() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '/':
+			b.WriteByte('/')
+			l.pos += 2
+		case c == '/':
+			l.pos++
+			return token{kind: tokRegexp, pos: start, text: b.String()}, nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, errorf(start, "unterminated regular expression")
+}
+
+func (l *lexer) term() (token, error) {
+	start := l.pos
+
+	var text, pattern strings.Builder
+	var wildcard bool
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if unicode.IsSpace(r) || isSpecial(r, l.inRange) {
+			break
+		}
+		if r == '\\' {
+			if l.pos+1 >= len(l.input) {
+				return token{}, errorf(l.pos, "escape character at end of query")
+			}
+			escaped, escSize := utf8.DecodeRuneInString(l.input[l.pos+1:])
+			text.WriteRune(escaped)
+			if escaped == '*' || escaped == '?' || escaped == '\\' {
+				pattern.WriteByte('\\')
+			}
+			pattern.WriteRune(escaped)
+			l.pos += 1 + escSize
+			continue
+		}
+		if r == '*' || r == '?' {
+			wildcard = true
+		}
+		text.WriteRune(r)
+		pattern.WriteRune(r)
+		l.pos += size
+	}
+
+	raw := l.input[start:l.pos]
+	tok := token{
+		kind:     tokTerm,
+		pos:      start,
+		text:     text.String(),
+		pattern:  pattern.String(),
+		wildcard: wildcard,
+	}
+	switch raw {
+	case "AND":
+		tok.kind = tokAnd
+	case "OR":
+		tok.kind = tokOr
+	case "NOT":
+		tok.kind = tokNot
+	case "TO":
+		if l.inRange {
+			tok.kind = tokTo
+		}
+	}
+
+	return tok, nil
+}
@@ -0,0 +1,526 @@
+// Package lucene parses queries written in the Lucene query string syntax
+// (as accepted by ElasticSearch's "query_string" query) into esquery trees.
+//
+// Parsing queries client-side, rather than passing them to ElasticSearch via
+// esquery.QueryString, allows validating and rewriting user input before it
+// reaches the cluster: leading wildcards can be forbidden, fields can be
+// restricted to an allow-list, and additional filters (e.g. restricting the
+// results to a tenant) can be injected.
+//
+// The following syntax is supported:
+//
+//	status:open                     match query (term query for keyword fields)
+//	title:"remote code"~2           match_phrase query with optional slop
+//	host:web*                       wildcard query
+//	name:jon~1                      fuzzy query
+//	path:/joh?n(ath[oa]n)/          regexp query
+//	severity:[7 TO 10]              range query, "{}" for exclusive bounds
+//	severity:>=7                    range query
+//	_exists_:title                  exists query
+//	a AND b, a && b                 conjunction
+//	a OR b, a || b                  disjunction
+//	NOT a, !a, -a                   negation
+//	+a                              required clause
+//	(a OR b)^2, title:(a b)         grouping and boosting
+//
+// Clauses that are not separated by an operator are combined using the
+// parser's default operator (OR unless configured otherwise).
+package lucene
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aquasecurity/esquery"
+)
+
+// Error is the error type returned when a query cannot be parsed, or when it
+// violates one of the restrictions configured in the Parser.
+type Error struct {
+	// Pos is the byte offset in the query at which the error was detected.
+	Pos int
+
+	// Msg describes the error.
+	Msg string
+}
+
+// Error returns a string representation of the error, implementing the error
+// interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("lucene: %s at position %d", e.Msg, e.Pos)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parser parses Lucene query strings into esquery trees. The zero value is a
+// parser with no default field, the OR default operator, and no restrictions.
+type Parser struct {
+	// DefaultField is the field searched by terms that do not specify one.
+	// If empty, such terms are an error.
+	DefaultField string
+
+	// DefaultOperator is the operator used between clauses that are not
+	// separated by an explicit operator.
+	DefaultOperator esquery.MatchOperator
+
+	// AllowLeadingWildcard allows wildcard terms to start with "*" or "?",
+	// which is expensive on large indices.
+	AllowLeadingWildcard bool
+
+	// AllowedFields, if not empty, is the list of fields the query may
+	// reference.
+	AllowedFields []string
+
+	// KeywordFields is the list of fields for which plain terms produce term
+	// queries rather than match queries.
+	KeywordFields []string
+
+	// Filters are added as filter clauses of every parsed query, e.g. to
+	// restrict the results to a single tenant.
+	Filters []esquery.Mappable
+}
+
+// Parse parses the provided query using a Parser with the provided default
+// field and no restrictions.
+func Parse(query, defaultField string) (esquery.Mappable, error) {
+	p := Parser{DefaultField: defaultField}
+	return p.Parse(query)
+}
+
+// Parse parses the provided query. Errors are always of type *Error.
+func (p *Parser) Parse(query string) (esquery.Mappable, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &parseState{Parser: p, tokens: tokens}
+
+	var q esquery.Mappable
+	if st.peek().kind == tokEOF {
+		q = esquery.MatchAll()
+	} else {
+		q, err = st.clauseList(p.DefaultField)
+		if err != nil {
+			return nil, err
+		}
+		if t := st.peek(); t.kind != tokEOF {
+			return nil, errorf(t.pos, "unexpected %s", t.kind)
+		}
+	}
+
+	if len(p.Filters) > 0 {
+		q = esquery.Bool().Must(q).Filter(p.Filters...)
+	}
+
+	return q, nil
+}
+
+type occur uint8
+
+const (
+	occurShould occur = iota
+	occurMust
+	occurMustNot
+)
+
+type clause struct {
+	occur occur
+	query esquery.Mappable
+}
+
+type parseState struct {
+	*Parser
+	tokens []token
+	pos    int
+}
+
+func (st *parseState) peek() token {
+	return st.tokens[st.pos]
+}
+
+func (st *parseState) peekAt(offset int) token {
+	if st.pos+offset >= len(st.tokens) {
+		return st.tokens[len(st.tokens)-1]
+	}
+	return st.tokens[st.pos+offset]
+}
+
+func (st *parseState) next() token {
+	t := st.tokens[st.pos]
+	if t.kind != tokEOF {
+		st.pos++
+	}
+	return t
+}
+
+func (st *parseState) expect(kind tokenKind) (token, error) {
+	t := st.next()
+	if t.kind != kind {
+		return t, errorf(t.pos, "expected %s, found %s", kind, t.kind)
+	}
+	return t, nil
+}
+
+// clauseList parses a sequence of clauses, until the end of the query or a
+// closing parenthesis, and combines them into a single query. Unfielded terms
+// search the provided field.
+func (st *parseState) clauseList(field string) (esquery.Mappable, error) {
+	// conjunctions holds groups of clauses separated by OR operators
+	var conjunctions [][]clause
+	var current []clause
+
+	for {
+		t := st.peek()
+		if t.kind == tokEOF || t.kind == tokRParen {
+			if len(current) == 0 {
+				return nil, errorf(t.pos, "expected a query, found %s", t.kind)
+			}
+			break
+		}
+
+		if len(current) > 0 {
+			op := st.DefaultOperator
+			switch t.kind {
+			case tokAnd:
+				op = esquery.OperatorAnd
+				st.next()
+			case tokOr:
+				op = esquery.OperatorOr
+				st.next()
+			}
+			if op == esquery.OperatorOr {
+				conjunctions = append(conjunctions, current)
+				current = nil
+			}
+		} else if t.kind == tokAnd || t.kind == tokOr {
+			return nil, errorf(t.pos, "unexpected %s", t.kind)
+		}
+
+		c, err := st.clause(field)
+		if err != nil {
+			return nil, err
+		}
+		current = append(current, c)
+	}
+	conjunctions = append(conjunctions, current)
+
+	clauses := make([]clause, len(conjunctions))
+	for i, conj := range conjunctions {
+		clauses[i] = conjunction(conj)
+	}
+
+	return combine(clauses), nil
+}
+
+// conjunction combines clauses that must all match into a single clause.
+func conjunction(clauses []clause) clause {
+	if len(clauses) == 1 {
+		return clauses[0]
+	}
+
+	q := esquery.Bool()
+	for _, c := range clauses {
+		if c.occur == occurMustNot {
+			q.MustNot(c.query)
+		} else {
+			q.Must(c.query)
+		}
+	}
+	return clause{occur: occurShould, query: q}
+}
+
+// combine combines clauses using Lucene's semantics: required clauses must
+// match, prohibited clauses must not match, and the remaining clauses are
+// optional unless there are no required clauses.
+func combine(clauses []clause) esquery.Mappable {
+	if len(clauses) == 1 && clauses[0].occur != occurMustNot {
+		return clauses[0].query
+	}
+
+	q := esquery.Bool()
+	for _, c := range clauses {
+		switch c.occur {
+		case occurMust:
+			q.Must(c.query)
+		case occurMustNot:
+			q.MustNot(c.query)
+		default:
+			q.Should(c.query)
+		}
+	}
+	return q
+}
+
+func (st *parseState) clause(field string) (clause, error) {
+	c := clause{occur: occurShould}
+	switch st.peek().kind {
+	case tokPlus:
+		c.occur = occurMust
+		st.next()
+	case tokMinus, tokNot:
+		c.occur = occurMustNot
+		st.next()
+	}
+
+	t := st.peek()
+	var err error
+	switch {
+	case t.kind == tokLParen:
+		c.query, err = st.group(field)
+	case t.kind == tokTerm && st.peekAt(1).kind == tokColon:
+		c.query, err = st.fielded()
+	case field == "" && isValue(t.kind):
+		return c, errorf(t.pos, "no field specified and no default field configured")
+	default:
+		c.query, err = st.value(field)
+	}
+
+	return c, err
+}
+
+// group parses a parenthesized list of clauses searching the provided field,
+// followed by an optional boost.
+func (st *parseState) group(field string) (esquery.Mappable, error) {
+	open := st.next()
+	q, err := st.clauseList(field)
+	if err != nil {
+		return nil, err
+	}
+	if t := st.next(); t.kind != tokRParen {
+		return nil, errorf(open.pos, "missing closing parenthesis")
+	}
+	return st.boost(q)
+}
+
+// fielded parses a clause of the form field:value.
+func (st *parseState) fielded() (esquery.Mappable, error) {
+	name := st.next()
+	st.next() // the colon
+
+	if name.text == "_exists_" {
+		t, err := st.expect(tokTerm)
+		if err != nil {
+			return nil, err
+		}
+		if err := st.checkField(t.text, t.pos); err != nil {
+			return nil, err
+		}
+		return st.boost(esquery.Exists(t.text))
+	}
+
+	if err := st.checkField(name.text, name.pos); err != nil {
+		return nil, err
+	}
+
+	if st.peek().kind == tokLParen {
+		return st.group(name.text)
+	}
+	return st.value(name.text)
+}
+
+func (st *parseState) checkField(field string, pos int) error {
+	if len(st.AllowedFields) == 0 {
+		return nil
+	}
+	for _, allowed := range st.AllowedFields {
+		if field == allowed {
+			return nil
+		}
+	}
+	return errorf(pos, "field %q is not allowed", field)
+}
+
+func (st *parseState) isKeyword(field string) bool {
+	for _, f := range st.KeywordFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isValue(kind tokenKind) bool {
+	switch kind {
+	case tokTerm, tokPhrase, tokRegexp, tokRangeStart, tokCompare:
+		return true
+	}
+	return false
+}
+
+// value parses a value searching the provided field, followed by an
+// optional boost.
+func (st *parseState) value(field string) (esquery.Mappable, error) {
+	var q esquery.Mappable
+	var err error
+
+	t := st.peek()
+	switch t.kind {
+	case tokTerm:
+		q, err = st.term(field)
+	case tokPhrase:
+		st.next()
+		phrase := esquery.MatchPhrase(field, t.text)
+		if st.peek().kind == tokTilde {
+			slop, err := st.number(st.next(), 16)
+			if err != nil {
+				return nil, err
+			}
+			phrase.Slop(uint16(slop))
+		}
+		q = phrase
+	case tokRegexp:
+		st.next()
+		q = esquery.Regexp(field, t.text)
+	case tokRangeStart:
+		q, err = st.rangeQuery(field)
+	case tokCompare:
+		q, err = st.comparison(field)
+	default:
+		return nil, errorf(t.pos, "expected a value, found %s", t.kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st.boost(q)
+}
+
+func (st *parseState) term(field string) (esquery.Mappable, error) {
+	t := st.next()
+
+	if st.peek().kind == tokTilde {
+		tilde := st.next()
+		fuzz := tilde.text
+		if fuzz == "" {
+			fuzz = "AUTO"
+		} else if _, err := st.number(tilde, 8); err != nil {
+			return nil, err
+		}
+		return esquery.Fuzzy(field, t.text).Fuzziness(fuzz), nil
+	}
+
+	if t.text == "*" {
+		return esquery.Exists(field), nil
+	}
+
+	if t.wildcard {
+		if !st.AllowLeadingWildcard && (t.pattern[0] == '*' || t.pattern[0] == '?') {
+			return nil, errorf(t.pos, "leading wildcards are not allowed")
+		}
+		return esquery.Wildcard(field, t.pattern), nil
+	}
+
+	if st.isKeyword(field) {
+		return esquery.Term(field, t.text), nil
+	}
+	return esquery.Match(field, t.text), nil
+}
+
+// rangeValue parses a bound of a range, returning nil for unbounded ("*").
+func (st *parseState) rangeValue() (interface{}, error) {
+	t := st.next()
+	switch t.kind {
+	case tokTerm:
+		if t.text == "*" {
+			return nil, nil
+		}
+		return t.text, nil
+	case tokPhrase:
+		return t.text, nil
+	}
+	return nil, errorf(t.pos, "expected a range bound, found %s", t.kind)
+}
+
+func (st *parseState) rangeQuery(field string) (esquery.Mappable, error) {
+	open := st.next()
+
+	from, err := st.rangeValue()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := st.expect(tokTo); err != nil {
+		return nil, err
+	}
+	to, err := st.rangeValue()
+	if err != nil {
+		return nil, err
+	}
+
+	closing := st.next()
+	if closing.kind != tokRangeEnd {
+		return nil, errorf(open.pos, "missing end of range")
+	}
+
+	q := esquery.Range(field)
+	if from != nil {
+		if open.text == "[" {
+			q.Gte(from)
+		} else {
+			q.Gt(from)
+		}
+	}
+	if to != nil {
+		if closing.text == "]" {
+			q.Lte(to)
+		} else {
+			q.Lt(to)
+		}
+	}
+	return q, nil
+}
+
+func (st *parseState) comparison(field string) (esquery.Mappable, error) {
+	op := st.next()
+
+	t := st.next()
+	if t.kind != tokTerm && t.kind != tokPhrase {
+		return nil, errorf(t.pos, "expected a value, found %s", t.kind)
+	}
+
+	q := esquery.Range(field)
+	switch op.text {
+	case ">":
+		q.Gt(t.text)
+	case ">=":
+		q.Gte(t.text)
+	case "<":
+		q.Lt(t.text)
+	case "<=":
+		q.Lte(t.text)
+	}
+	return q, nil
+}
+
+// boost parses an optional boost following a query. As not all query types
+// support boosting, boosted queries are wrapped in a bool query.
+func (st *parseState) boost(q esquery.Mappable) (esquery.Mappable, error) {
+	if st.peek().kind != tokCaret {
+		return q, nil
+	}
+
+	t := st.next()
+	if t.text == "" {
+		return nil, errorf(t.pos, "missing boost value")
+	}
+	b, err := strconv.ParseFloat(t.text, 32)
+	if err != nil {
+		return nil, errorf(t.pos, "invalid boost value %q", t.text)
+	}
+
+	return esquery.Bool().Must(q).Boost(float32(b)), nil
+}
+
+// number parses the number attached to a "~" token.
+func (st *parseState) number(t token, bitSize int) (uint64, error) {
+	if t.text == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(t.text, 10, bitSize)
+	if err != nil {
+		return 0, errorf(t.pos, "invalid number %q", t.text)
+	}
+	return n, nil
+}
@@ -0,0 +1,240 @@
+package lucene
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aquasecurity/esquery"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		exp    esquery.Mappable
+	}{
+		{
+			"fielded terms with groups, ranges and phrases",
+			Parser{},
+			`status:open AND (severity:[7 TO 10] OR tag:"remote code")`,
+			esquery.Bool().Must(
+				esquery.Match("status", "open"),
+				esquery.Bool().Should(
+					esquery.Range("severity").Gte("7").Lte("10"),
+					esquery.MatchPhrase("tag", "remote code"),
+				),
+			),
+		},
+		{
+			"default field and default operator",
+			Parser{DefaultField: "message"},
+			`quick brown`,
+			esquery.Bool().Should(
+				esquery.Match("message", "quick"),
+				esquery.Match("message", "brown"),
+			),
+		},
+		{
+			"AND default operator",
+			Parser{DefaultField: "message", DefaultOperator: esquery.OperatorAnd},
+			`quick brown OR fox`,
+			esquery.Bool().Should(
+				esquery.Bool().Must(
+					esquery.Match("message", "quick"),
+					esquery.Match("message", "brown"),
+				),
+				esquery.Match("message", "fox"),
+			),
+		},
+		{
+			"required and prohibited clauses",
+			Parser{DefaultField: "message"},
+			`quick +brown -fox`,
+			esquery.Bool().
+				Should(esquery.Match("message", "quick")).
+				Must(esquery.Match("message", "brown")).
+				MustNot(esquery.Match("message", "fox")),
+		},
+		{
+			"negation",
+			Parser{},
+			`NOT status:closed`,
+			esquery.Bool().MustNot(esquery.Match("status", "closed")),
+		},
+		{
+			"symbolic operators",
+			Parser{},
+			`a:1 && (b:2 || !c:3)`,
+			esquery.Bool().Must(
+				esquery.Match("a", "1"),
+				esquery.Bool().
+					Should(esquery.Match("b", "2")).
+					MustNot(esquery.Match("c", "3")),
+			),
+		},
+		{
+			"field group",
+			Parser{},
+			`title:(quick brown)`,
+			esquery.Bool().Should(
+				esquery.Match("title", "quick"),
+				esquery.Match("title", "brown"),
+			),
+		},
+		{
+			"wildcards, fuzzy, regexp and exists",
+			Parser{DefaultOperator: esquery.OperatorAnd},
+			`host:web* name:jon~1 user:kimchy~ path:/joh?n(ath[oa]n)/ _exists_:title owner:*`,
+			esquery.Bool().Must(
+				esquery.Wildcard("host", "web*"),
+				esquery.Fuzzy("name", "jon").Fuzziness("1"),
+				esquery.Fuzzy("user", "kimchy").Fuzziness("AUTO"),
+				esquery.Regexp("path", "joh?n(ath[oa]n)"),
+				esquery.Exists("title"),
+				esquery.Exists("owner"),
+			),
+		},
+		{
+			"escaped wildcard characters",
+			Parser{},
+			`file:report\*2020* name:what\?`,
+			esquery.Bool().Should(
+				esquery.Wildcard("file", `report\*2020*`),
+				esquery.Match("name", "what?"),
+			),
+		},
+		{
+			"open and exclusive ranges, comparisons",
+			Parser{DefaultOperator: esquery.OperatorAnd},
+			`date:{2020-01-01T00:00:00 TO *] score:>=7 age:<30`,
+			esquery.Bool().Must(
+				esquery.Range("date").Gt("2020-01-01T00:00:00"),
+				esquery.Range("score").Gte("7"),
+				esquery.Range("age").Lt("30"),
+			),
+		},
+		{
+			"negative bounds",
+			Parser{},
+			`severity:[-5 TO 5] severity:>-5 -severity:<=-10`,
+			esquery.Bool().
+				Should(
+					esquery.Range("severity").Gte("-5").Lte("5"),
+					esquery.Range("severity").Gt("-5"),
+				).
+				MustNot(esquery.Range("severity").Lte("-10")),
+		},
+		{
+			"phrase slop and boosts",
+			Parser{},
+			`title:"quick fox"~3^2 (a:1 b:2)^0.5`,
+			esquery.Bool().Should(
+				esquery.Bool().Must(esquery.MatchPhrase("title", "quick fox").Slop(3)).Boost(2),
+				esquery.Bool().Must(
+					esquery.Bool().Should(
+						esquery.Match("a", "1"),
+						esquery.Match("b", "2"),
+					),
+				).Boost(0.5),
+			),
+		},
+		{
+			"keyword fields and injected filters",
+			Parser{
+				KeywordFields: []string{"status"},
+				Filters:       []esquery.Mappable{esquery.Term("tenant", "acme")},
+			},
+			`status:open`,
+			esquery.Bool().
+				Must(esquery.Term("status", "open")).
+				Filter(esquery.Term("tenant", "acme")),
+		},
+		{
+			"empty query",
+			Parser{},
+			"   ",
+			esquery.MatchAll(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := test.parser.Parse(test.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			exp, _ := json.Marshal(test.exp.Map())
+			got, _ := json.Marshal(q.Map())
+			if string(exp) != string(got) {
+				t.Errorf("expected %s, got %s", exp, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		pos    int
+		msg    string
+	}{
+		{"unterminated phrase", Parser{}, `title:"quick`, 6, "unterminated phrase"},
+		{"unterminated regexp", Parser{}, `path:/abc`, 5, "unterminated regular expression"},
+		{"missing parenthesis", Parser{}, `a:1 AND (b:2 OR c:3`, 8, "missing closing parenthesis"},
+		{"unexpected parenthesis", Parser{}, `a:1)`, 3, `unexpected ")"`},
+		{"dangling operator", Parser{}, `a:1 AND`, 7, "expected a value, found end of query"},
+		{"leading operator", Parser{}, `OR a:1`, 0, "unexpected OR"},
+		{"no default field", Parser{}, `a:1 b`, 4, "no field specified and no default field configured"},
+		{"missing TO", Parser{}, `a:[1 2]`, 5, "expected TO, found term"},
+		{"missing boost", Parser{}, `a:1^`, 3, "missing boost value"},
+		{"leading wildcard", Parser{}, `host:*web`, 5, "leading wildcards are not allowed"},
+		{
+			"disallowed field",
+			Parser{AllowedFields: []string{"title"}},
+			`title:a AND secret:b`,
+			12,
+			`field "secret" is not allowed`,
+		},
+		{
+			"disallowed exists field",
+			Parser{AllowedFields: []string{"title"}},
+			`_exists_:secret`,
+			9,
+			`field "secret" is not allowed`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.parser.Parse(test.query)
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected a *Error, got %T", err)
+			}
+			if e.Pos != test.pos || e.Msg != test.msg {
+				t.Errorf("expected %q at %d, got %q at %d", test.msg, test.pos, e.Msg, e.Pos)
+			}
+		})
+	}
+}
+
+func TestParseAllowLeadingWildcard(t *testing.T) {
+	p := Parser{AllowLeadingWildcard: true}
+	q, err := p.Parse("host:*web")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp, _ := json.Marshal(esquery.Wildcard("host", "*web").Map())
+	got, _ := json.Marshal(q.Map())
+	if string(exp) != string(got) {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
@@ -0,0 +1,559 @@
+package esquery
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Optimize returns a simplified version of the provided query, matching the
+// same documents with the same scores. Bool queries are rewritten as
+// follows:
+//
+//   - nested bool queries are flattened into their parent where possible,
+//     e.g. a bool query with only filter clauses in a filter clause;
+//   - bool queries with a single clause are replaced by that clause, and
+//     empty bool queries by a match_all query;
+//   - duplicate filter and must_not clauses are removed, as well as match_all
+//     queries in filter clauses and match_none queries in must_not and should
+//     clauses;
+//   - range queries with only lower bounds (or only upper bounds) on the same
+//     field in filter clauses are merged into a single range query;
+//   - bool queries that cannot match any document, because a required clause
+//     is a match_none query or is also a must_not clause, are replaced by a
+//     match_none query.
+//
+// Rewrites that would change the scores of documents are only applied where
+// scores are not computed: in the filter and must_not clauses of bool
+// queries, the filter of constant_score queries and the negative part of
+// boosting queries. There, must clauses are moved to the filter clause,
+// match_all queries are removed from must clauses, should clauses are
+// removed if they are optional and merged if they are not (e.g. term queries
+// on the same field are merged into a terms query), and duplicate should
+// clauses are removed. Use OptimizeFilter for queries whose scores are
+// ignored, e.g. the queries of count requests, or those of search requests
+// sorted on fields.
+//
+// Other compound queries are traversed, and search and count requests have
+// their query and post filter optimized. The query of a function_score or
+// script_score query with a minimum score is always optimized preserving
+// scores, since they decide which documents match. The provided query is not modified;
+// the returned query may share its unmodified parts.
+func Optimize(q Mappable) Mappable {
+	return optimize(q, false)
+}
+
+// OptimizeFilter returns a simplified version of the provided query, matching
+// the same documents but without preserving their scores. See Optimize for
+// the list of rewrites.
+func OptimizeFilter(q Mappable) Mappable {
+	return optimize(q, true)
+}
+
+// optimize simplifies a query, which is in filter context if filter is true,
+// i.e. whose scores are not used.
+func optimize(q Mappable, filter bool) Mappable {
+	if isNil(q) {
+		return q
+	}
+
+	switch n := q.(type) {
+	case *BoolQuery:
+		return optimizeBool(n, filter)
+	case *ConstantScoreQuery:
+		inner := optimize(n.filter, true)
+		if filter {
+			return inner
+		}
+		c := *n
+		c.filter = inner
+		return &c
+	case *BoostingQuery:
+		c := *n
+		c.Pos = optimize(n.Pos, filter)
+		c.Neg = optimize(n.Neg, true)
+		return &c
+	case *DisMaxQuery:
+		c := *n
+		c.queries = make([]Mappable, len(n.queries))
+		for i, sub := range n.queries {
+			c.queries[i] = optimize(sub, filter)
+		}
+		return &c
+	case *NestedQuery:
+		c := *n
+		c.query = optimize(n.query, filter)
+		return &c
+	case *FunctionScoreQuery:
+		// with a minimum score, the scores of the inner query decide which
+		// documents match
+		c := *n
+		c.query = optimize(n.query, filter && n.minScore == nil)
+		return &c
+	case *ScriptScoreQuery:
+		c := *n
+		c.query = optimize(n.query, filter && n.minScore == nil)
+		return &c
+	case *SearchRequest:
+		c := *n
+		c.hitOptions = n.hitOptions.clone()
+		c.aggs = append([]Aggregation(nil), n.aggs...)
+		c.searchAfter = append([]interface{}(nil), n.searchAfter...)
+		c.query = optimize(n.query, filter)
+		c.postFilter = optimize(n.postFilter, true)
+		return &c
+	case *CountRequest:
+		c := *n
+		c.query = optimize(n.query, true)
+		return &c
+	}
+
+	return q
+}
+
+func optimizeBool(q *BoolQuery, filter bool) Mappable {
+	var must, filters, mustNot, should []Mappable
+
+	// the number of should clauses documents must match. Unless set, it is
+	// one if there are no must and filter clauses, zero otherwise.
+	hadRequired := len(q.must)+len(q.filter) > 0
+	required := int(q.minimumShouldMatch)
+	if required == 0 && !hadRequired && len(q.should) > 0 {
+		required = 1
+	}
+
+	for _, c := range q.must {
+		c = optimize(c, filter)
+		sub, ok := c.(*BoolQuery)
+		switch {
+		case ok && filter && isConjunction(sub):
+			filters = append(filters, sub.filter...)
+			mustNot = append(mustNot, sub.mustNot...)
+		case ok && !filter && isConjunction(sub) && sub.boost == 0 &&
+			len(sub.must)+len(sub.filter) > 0:
+			// a bool query without must and filter clauses scores its
+			// documents with a constant score, it cannot be flattened
+			must = append(must, sub.must...)
+			filters = append(filters, sub.filter...)
+			mustNot = append(mustNot, sub.mustNot...)
+		case filter:
+			filters = append(filters, c)
+		default:
+			must = append(must, c)
+		}
+	}
+
+	for _, c := range q.filter {
+		c = optimize(c, true)
+		if sub, ok := c.(*BoolQuery); ok && isConjunction(sub) {
+			filters = append(filters, sub.filter...)
+			mustNot = append(mustNot, sub.mustNot...)
+			continue
+		}
+		filters = append(filters, c)
+	}
+
+	for _, c := range q.mustNot {
+		mustNot = append(mustNot, optimize(c, true))
+	}
+
+	for _, c := range q.should {
+		should = append(should, optimize(c, filter))
+	}
+
+	for _, c := range append(must, filters...) {
+		if isMatchNone(c) {
+			return MatchNone()
+		}
+	}
+	for _, c := range mustNot {
+		if isMatchAll(c) {
+			return MatchNone()
+		}
+	}
+
+	filters = removeClauses(filters, isMatchAll)
+	mustNot = removeClauses(mustNot, isMatchNone)
+	should = removeClauses(should, isMatchNone)
+	if filter {
+		must = removeClauses(must, isMatchAll)
+	}
+
+	if required > len(should) {
+		return MatchNone()
+	}
+
+	filters = dedupeClauses(filters)
+	mustNot = dedupeClauses(mustNot)
+	if filter {
+		switch required {
+		case 0:
+			// optional should clauses only affect scores
+			should = nil
+		case 1:
+			should = mergeTerms(dedupeClauses(should))
+		}
+	}
+
+	// x AND NOT x
+	negated := make(map[string]bool, len(mustNot))
+	for _, c := range mustNot {
+		if key, ok := clauseKey(c); ok {
+			negated[key] = true
+		}
+	}
+	for _, c := range append(must, filters...) {
+		if key, ok := clauseKey(c); ok && negated[key] {
+			return MatchNone()
+		}
+	}
+
+	filters = mergeRanges(filters)
+
+	res := &BoolQuery{
+		must:               must,
+		filter:             filters,
+		mustNot:            mustNot,
+		should:             should,
+		minimumShouldMatch: q.minimumShouldMatch,
+	}
+	if !filter {
+		res.boost = q.boost
+	}
+
+	// removing clauses must not change whether should clauses are required
+	if len(should) > 0 && q.minimumShouldMatch == 0 {
+		if hadRequired && len(must)+len(filters) == 0 {
+			res.filter = append(res.filter, MatchAll())
+		} else if !hadRequired && len(must)+len(filters) > 0 {
+			res.minimumShouldMatch = 1
+		}
+	}
+
+	return unwrapBool(res, filter)
+}
+
+// isConjunction returns whether an optimized bool query can be flattened into
+// the must or filter clause of its parent.
+func isConjunction(q *BoolQuery) bool {
+	return len(q.should) == 0 && q.minimumShouldMatch == 0
+}
+
+// unwrapBool replaces a bool query with a single clause with that clause, and
+// an empty bool query with a match_all query.
+func unwrapBool(q *BoolQuery, filter bool) Mappable {
+	n := len(q.must) + len(q.filter) + len(q.mustNot) + len(q.should)
+	switch {
+	case n == 0:
+		all := MatchAll()
+		if !filter {
+			all.Boost(q.boost)
+		}
+		return all
+	case n > 1, !filter && q.boost != 0:
+		return q
+	case len(q.must) == 1:
+		return q.must[0]
+	case len(q.filter) == 1 && filter:
+		return q.filter[0]
+	case len(q.should) == 1 && q.minimumShouldMatch <= 1:
+		return q.should[0]
+	}
+	return q
+}
+
+func isMatchAll(q Mappable) bool {
+	m, ok := q.(*MatchAllQuery)
+	return ok && m.all
+}
+
+func isMatchNone(q Mappable) bool {
+	m, ok := q.(*MatchAllQuery)
+	return ok && !m.all
+}
+
+func removeClauses(clauses []Mappable, remove func(Mappable) bool) []Mappable {
+	var res []Mappable
+	for _, c := range clauses {
+		if !remove(c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// clauseKey returns a key identifying a clause by its JSON representation.
+func clauseKey(q Mappable) (string, bool) {
+	data, err := json.Marshal(q.Map())
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func dedupeClauses(clauses []Mappable) []Mappable {
+	var res []Mappable
+	seen := make(map[string]bool, len(clauses))
+	for _, c := range clauses {
+		key, ok := clauseKey(c)
+		if ok && seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, c)
+	}
+	return res
+}
+
+// mergeTerms merges the term and terms queries on the same field of a list of
+// should clauses, of which documents must match at least one, into a single
+// terms query.
+func mergeTerms(clauses []Mappable) []Mappable {
+	var res []Mappable
+	first := make(map[string]int)
+	merged := make(map[int]*TermsQuery)
+
+	for _, c := range clauses {
+		var field string
+		var values []interface{}
+		switch t := c.(type) {
+		case *TermQuery:
+			field, values = t.field, []interface{}{t.params.Value}
+		case *TermsQuery:
+			field, values = t.field, t.values
+		default:
+			res = append(res, c)
+			continue
+		}
+
+		i, ok := first[field]
+		if !ok {
+			first[field] = len(res)
+			res = append(res, c)
+			continue
+		}
+
+		terms, ok := merged[i]
+		if !ok {
+			terms = Terms(field)
+			switch t := res[i].(type) {
+			case *TermQuery:
+				terms.values = append(terms.values, t.params.Value)
+			case *TermsQuery:
+				terms.values = append(terms.values, t.values...)
+			}
+			merged[i] = terms
+			res[i] = terms
+		}
+		terms.values = append(terms.values, values...)
+	}
+
+	return res
+}
+
+// mergeRanges merges the range queries on the same field of a list of filter
+// clauses. Only range queries with the same options and with only lower
+// bounds (or only upper bounds) are merged: on multi-valued fields, a range
+// query with both bounds is not equivalent to two range queries with one
+// bound each, as the bounds may match different values.
+func mergeRanges(clauses []Mappable) []Mappable {
+	var res []Mappable
+	first := make(map[rangeKey]int)
+
+	for _, c := range clauses {
+		r, ok := c.(*RangeQuery)
+		if !ok {
+			res = append(res, c)
+			continue
+		}
+
+		lower := r.params.Gt != nil || r.params.Gte != nil
+		upper := r.params.Lt != nil || r.params.Lte != nil
+		if lower == upper {
+			res = append(res, c)
+			continue
+		}
+
+		key := rangeKey{
+			field:    r.field,
+			lower:    lower,
+			format:   r.params.Format,
+			relation: r.params.Relation,
+			timeZone: r.params.TimeZone,
+		}
+		i, ok := first[key]
+		if !ok {
+			first[key] = len(res)
+			res = append(res, c)
+			continue
+		}
+
+		if m, ok := mergeRange(res[i].(*RangeQuery), r, lower); ok {
+			res[i] = m
+		} else {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+type rangeKey struct {
+	field    string
+	lower    bool
+	format   string
+	relation RangeRelation
+	timeZone string
+}
+
+// mergeRange returns the most restrictive of two range queries with only
+// lower bounds (or only upper bounds), if their bounds can be compared.
+func mergeRange(a, b *RangeQuery, lower bool) (*RangeQuery, bool) {
+	aVal, aExcl := a.params.Lt, a.params.Lt != nil
+	bVal, bExcl := b.params.Lt, b.params.Lt != nil
+	if !aExcl {
+		aVal = a.params.Lte
+	}
+	if !bExcl {
+		bVal = b.params.Lte
+	}
+	if lower {
+		aVal, aExcl = a.params.Gt, a.params.Gt != nil
+		bVal, bExcl = b.params.Gt, b.params.Gt != nil
+		if !aExcl {
+			aVal = a.params.Gte
+		}
+		if !bExcl {
+			bVal = b.params.Gte
+		}
+	}
+
+	cmp, ok := compareValues(aVal, bVal)
+	if !ok {
+		return nil, false
+	}
+	if !lower {
+		cmp = -cmp
+	}
+	switch {
+	case cmp > 0, cmp == 0 && aExcl:
+		return a, true
+	default:
+		return b, true
+	}
+}
+
+// compareValues compares two numbers or times, returning -1, 0 or 1 if a is
+// lower than, equal to or greater than b, respectively. Other values can
+// only be compared when equal.
+func compareValues(a, b interface{}) (int, bool) {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		switch {
+		case !ok:
+			return 0, false
+		case at.Before(bt):
+			return -1, true
+		case at.After(bt):
+			return 1, true
+		}
+		return 0, true
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if numberKind(av) != reflect.Invalid && numberKind(bv) != reflect.Invalid {
+		return compareNumbers(av, bv)
+	}
+
+	return 0, reflect.DeepEqual(a, b)
+}
+
+// numberKind returns reflect.Int, reflect.Uint or reflect.Float64 for signed
+// integers, unsigned integers and floating point numbers respectively, and
+// reflect.Invalid for other values.
+func numberKind(v reflect.Value) reflect.Kind {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	}
+	return reflect.Invalid
+}
+
+// compareNumbers compares two numbers like compareValues. Integers are
+// compared exactly; they are only converted to floating point numbers when
+// compared to one, in which case the numbers cannot be compared if the
+// conversion loses precision.
+func compareNumbers(a, b reflect.Value) (int, bool) {
+	ak, bk := numberKind(a), numberKind(b)
+	switch {
+	case ak == reflect.Int && bk == reflect.Int:
+		return compareInts(a.Int(), b.Int()), true
+	case ak == reflect.Uint && bk == reflect.Uint:
+		return compareUints(a.Uint(), b.Uint()), true
+	case ak == reflect.Int && bk == reflect.Uint:
+		if a.Int() < 0 {
+			return -1, true
+		}
+		return compareUints(uint64(a.Int()), b.Uint()), true
+	case ak == reflect.Uint && bk == reflect.Int:
+		cmp, ok := compareNumbers(b, a)
+		return -cmp, ok
+	}
+
+	af, aok := exactFloat(a)
+	bf, bok := exactFloat(b)
+	switch {
+	case !aok || !bok:
+		return 0, false
+	case af < bf:
+		return -1, true
+	case af > bf:
+		return 1, true
+	case af == bf:
+		return 0, true
+	}
+	// NaN
+	return 0, false
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// exactFloat converts a number to a float64, reporting whether the conversion
+// is exact.
+func exactFloat(v reflect.Value) (float64, bool) {
+	switch numberKind(v) {
+	case reflect.Int:
+		i := v.Int()
+		f := float64(i)
+		return f, f < 0x1p63 && int64(f) == i
+	case reflect.Uint:
+		u := v.Uint()
+		f := float64(u)
+		return f, f < 0x1p64 && uint64(f) == u
+	case reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
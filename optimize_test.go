@@ -0,0 +1,312 @@
+package esquery
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   bool
+		q        Mappable
+		expected Mappable
+	}{
+		{
+			"nested filters are flattened",
+			false,
+			Bool().
+				Must(Bool().Filter(Term("status", "open"))).
+				Filter(Bool().Filter(Term("fixed", false)).MustNot(Exists("ignored"))),
+			Bool().
+				Filter(Term("status", "open"), Term("fixed", false)).
+				MustNot(Exists("ignored")),
+		},
+		{
+			"nested must clauses are flattened",
+			false,
+			Bool().
+				Must(Match("title", "openssl"), Bool().Must(Match("body", "overflow"))),
+			Bool().
+				Must(Match("title", "openssl"), Match("body", "overflow")),
+		},
+		{
+			"boosted bool queries are not flattened when scoring",
+			false,
+			Bool().
+				Must(Match("title", "openssl"), Bool().Must(Match("body", "overflow")).Boost(2)),
+			Bool().
+				Must(Match("title", "openssl"), Bool().Must(Match("body", "overflow")).Boost(2)),
+		},
+		{
+			"single clauses are unwrapped",
+			false,
+			Bool().Must(Bool().Must(Match("title", "openssl"))),
+			Match("title", "openssl"),
+		},
+		{
+			"filter clauses are not unwrapped when scoring",
+			false,
+			Bool().Filter(Term("status", "open")),
+			Bool().Filter(Term("status", "open")),
+		},
+		{
+			"empty bool query",
+			false,
+			Bool().Filter(Bool()).Boost(2),
+			MatchAll().Boost(2),
+		},
+		{
+			"match_all is kept in must clauses when scoring",
+			false,
+			Bool().Must(MatchAll(), Match("title", "openssl")).Filter(MatchAll()),
+			Bool().Must(MatchAll(), Match("title", "openssl")),
+		},
+		{
+			"match_all is removed from must clauses in filter context",
+			true,
+			Bool().Must(MatchAll(), Term("status", "open")).MustNot(Exists("fixed_at")),
+			Bool().Filter(Term("status", "open")).MustNot(Exists("fixed_at")),
+		},
+		{
+			"must clauses are moved to the filter clause in filter context",
+			true,
+			Bool().Must(Match("title", "openssl"), Term("status", "open")),
+			Bool().Filter(Match("title", "openssl"), Term("status", "open")),
+		},
+		{
+			"scoring is not used in filter clauses",
+			false,
+			Bool().
+				Must(Match("title", "openssl")).
+				Filter(Bool().Must(MatchAll(), Term("status", "open"))),
+			Bool().
+				Must(Match("title", "openssl")).
+				Filter(Term("status", "open")),
+		},
+		{
+			"duplicate clauses are removed",
+			false,
+			Bool().
+				Must(Match("title", "openssl"), Match("title", "openssl")).
+				Filter(Term("status", "open"), Term("status", "open")).
+				MustNot(Exists("fixed_at"), Exists("fixed_at")),
+			Bool().
+				Must(Match("title", "openssl"), Match("title", "openssl")).
+				Filter(Term("status", "open")).
+				MustNot(Exists("fixed_at")),
+		},
+		{
+			"should terms are merged in filter context",
+			false,
+			Bool().
+				Must(Match("title", "openssl")).
+				Filter(Bool().Should(
+					Term("severity", "high"),
+					Exists("cve"),
+					Terms("severity", "critical", "medium"),
+					Term("severity", "high"),
+				)),
+			Bool().
+				Must(Match("title", "openssl")).
+				Filter(Bool().Should(
+					Terms("severity", "high", "critical", "medium"),
+					Exists("cve"),
+				)),
+		},
+		{
+			"should terms are not merged when scoring",
+			false,
+			Bool().Should(Term("severity", "high"), Term("severity", "critical")),
+			Bool().Should(Term("severity", "high"), Term("severity", "critical")),
+		},
+		{
+			"should terms are not merged if several must match",
+			true,
+			Bool().
+				Should(Term("tag", "a"), Term("tag", "b"), Term("tag", "c")).
+				MinimumShouldMatch(2),
+			Bool().
+				Should(Term("tag", "a"), Term("tag", "b"), Term("tag", "c")).
+				MinimumShouldMatch(2),
+		},
+		{
+			"optional should clauses are removed in filter context",
+			true,
+			Bool().Filter(Term("status", "open")).Should(Term("severity", "high")),
+			Term("status", "open"),
+		},
+		{
+			"should clauses stay optional when scoring",
+			false,
+			Bool().Filter(MatchAll()).Should(Match("title", "openssl")),
+			Bool().Filter(MatchAll()).Should(Match("title", "openssl")),
+		},
+		{
+			"should clauses stay required when scoring",
+			false,
+			Bool().
+				Should(Match("title", "openssl"), Match("body", "openssl")).
+				MustNot(Bool().Filter(Exists("fixed_at"))),
+			Bool().
+				Should(Match("title", "openssl"), Match("body", "openssl")).
+				MustNot(Exists("fixed_at")),
+		},
+		{
+			"ranges with bounds in the same direction are merged",
+			true,
+			Bool().Filter(
+				Range("score").Gte(5),
+				Range("score").Gt(7.5),
+				Range("score").Lt(9),
+				Range("created_at").Gte("now-1d"),
+				Range("created_at").Gte("now-1w"),
+			),
+			Bool().Filter(
+				Range("score").Gt(7.5),
+				Range("score").Lt(9),
+				Range("created_at").Gte("now-1d"),
+				Range("created_at").Gte("now-1w"),
+			),
+		},
+		{
+			"integer bounds are compared exactly",
+			true,
+			Bool().Filter(
+				Range("id").Gte(int64(9007199254740993)),
+				Range("id").Gte(int64(9007199254740992)),
+				Range("size").Lte(uint64(math.MaxUint64)),
+				Range("size").Lte(-1),
+				Range("ts").Gt(int64(9007199254740993)),
+				Range("ts").Gt(1.5),
+				Range("score").Lt(3),
+				Range("score").Lt(2.5),
+			),
+			Bool().Filter(
+				Range("id").Gte(int64(9007199254740993)),
+				Range("size").Lte(-1),
+				Range("ts").Gt(int64(9007199254740993)),
+				Range("ts").Gt(1.5),
+				Range("score").Lt(2.5),
+			),
+		},
+		{
+			"contradictions match nothing",
+			false,
+			Bool().
+				Must(Match("title", "openssl")).
+				Filter(Bool().Must(Term("a", 1))).
+				MustNot(Term("a", 1)),
+			MatchNone(),
+		},
+		{
+			"match_none in a required clause matches nothing",
+			false,
+			Bool().Must(Match("title", "openssl")).Filter(MatchNone()),
+			MatchNone(),
+		},
+		{
+			"match_all in a must_not clause matches nothing",
+			false,
+			Bool().Must(Match("title", "openssl")).MustNot(Bool()),
+			MatchNone(),
+		},
+		{
+			"match_none is removed from should clauses",
+			false,
+			Bool().Should(MatchNone(), Match("title", "openssl")),
+			Match("title", "openssl"),
+		},
+		{
+			"required should clauses that cannot match",
+			false,
+			Bool().Should(MatchNone(), Match("title", "openssl")).MinimumShouldMatch(2),
+			MatchNone(),
+		},
+		{
+			"compound queries are traversed",
+			false,
+			ConstantScore(Bool().Must(Bool().Filter(Term("status", "open")))),
+			ConstantScore(Term("status", "open")),
+		},
+		{
+			"constant_score is unwrapped in filter context",
+			false,
+			Bool().Filter(ConstantScore(Term("status", "open"))),
+			Bool().Filter(Term("status", "open")),
+		},
+		{
+			"function_score without min_score in filter context",
+			true,
+			FunctionScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true))),
+			FunctionScore(Match("title", "openssl")),
+		},
+		{
+			"function_score with min_score in filter context",
+			true,
+			FunctionScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true))).MinScore(2),
+			FunctionScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true))).MinScore(2),
+		},
+		{
+			"function_score with min_score in a filter clause",
+			false,
+			Bool().Filter(FunctionScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true))).MinScore(2)),
+			Bool().Filter(FunctionScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true))).MinScore(2)),
+		},
+		{
+			"script_score with min_score in filter context",
+			true,
+			ScriptScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true)), InlineScript("_score * 2")).MinScore(2),
+			ScriptScore(Bool().Must(Match("title", "openssl")).Should(Term("kev", true)), InlineScript("_score * 2")).MinScore(2),
+		},
+		{
+			"search requests",
+			false,
+			Search().
+				Query(Bool().Must(Bool().Must(Match("title", "openssl")))).
+				PostFilter(Bool().Must(Term("status", "open"))),
+			Search().
+				Query(Match("title", "openssl")).
+				PostFilter(Term("status", "open")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, _ := json.Marshal(test.q.Map())
+
+			var got Mappable
+			if test.filter {
+				got = OptimizeFilter(test.q)
+			} else {
+				got = Optimize(test.q)
+			}
+
+			exp, gotJSON, ok := sameJSON(test.expected.Map(), got.Map())
+			if !ok {
+				t.Errorf("expected %s, got %s", exp, gotJSON)
+			}
+
+			after, _ := json.Marshal(test.q.Map())
+			if string(before) != string(after) {
+				t.Errorf("query was modified from %s to %s", before, after)
+			}
+		})
+	}
+}
+
+func TestOptimizeCopiesRequest(t *testing.T) {
+	req := Search().
+		Aggs(Avg("a", "score")).
+		Aggs(Avg("b", "score")).
+		Aggs(Avg("c", "score"))
+
+	opt := Optimize(req).(*SearchRequest)
+	opt.Aggs(Max("optimized", "score"))
+	req.Aggs(Max("original", "score"))
+
+	if got := opt.aggs[3].Name(); got != "optimized" {
+		t.Errorf("expected the optimized request to keep its aggregation, got %q", got)
+	}
+}
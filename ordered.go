@@ -0,0 +1,147 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// MarshalOrdered returns the JSON encoding of the provided query, aggregation
+// or request, with object keys in a stable order that follows ElasticSearch's
+// documentation rather than the alphabetical order of json.Marshal. The output
+// is equivalent to that of json.Marshal(m.Map()), except that HTML characters
+// are not escaped, and is meant for logging, debugging and snapshot files.
+//
+// Keys are ordered as follows:
+//
+//   - names chosen by the user or defined by ElasticSearch for the content of
+//     an object, i.e. query and aggregation types and field names, come first,
+//     so that the type of a query is followed by its parameters;
+//   - path and field, the targets of nested and field queries and
+//     aggregations, follow;
+//   - request options follow in the order query, post_filter, sort,
+//     search_after, from, size, _source, fields options, highlight, other
+//     flags and timeout;
+//   - clauses of compound queries follow in the order must, filter, should,
+//     must_not (and likewise for other compound queries), then parameters
+//     with the main ones (value, field, path, script...) first;
+//   - minimum_should_match, _name and boost come last among parameters, and
+//     sub-aggregations (aggs) come after everything else.
+//
+// Keys that are not known to the library, and keys of objects whose content is
+// entirely user-defined (aggregations, script params, documents, fields of a
+// highlight, etc.) are sorted alphabetically.
+func MarshalOrdered(m Mappable) ([]byte, error) {
+	e := encoder{ordered: true}
+	e.mappable(m)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// PrettyPrint returns the indented JSON encoding of the provided query,
+// aggregation or request, with keys ordered as in MarshalOrdered. It is meant
+// for logging: if the value cannot be encoded, the error message is returned.
+func PrettyPrint(m Mappable) string {
+	data, err := MarshalOrdered(m)
+	if err != nil {
+		return "esquery: " + err.Error()
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return "esquery: " + err.Error()
+	}
+	return buf.String()
+}
+
+// userKeyed contains the keys of objects whose keys are all chosen by the
+// user, and which are therefore ordered alphabetically.
+var userKeyed = map[string]bool{
+	"aggs":          true,
+	"aggregations":  true,
+	"document":      true,
+	"documents":     true,
+	"fields":        true,
+	"mappings":      true,
+	"order":         true,
+	"params":        true,
+	"properties":    true,
+	"script_fields": true,
+}
+
+// keyOrder lists the keys known to the library in the order in which they are
+// written by MarshalOrdered.
+var keyOrder = []string{
+	// targets of queries and aggregations
+	"path", "field",
+
+	// request options
+	"query", "post_filter", "sort", "search_after", "from", "size", "_source",
+	"includes", "excludes", "docvalue_fields", "stored_fields", "script_fields",
+	"highlight", "explain", "version", "seq_no_primary_term", "timeout",
+
+	// clauses of compound queries
+	"must", "filter", "should", "must_not", "positive", "negative",
+	"negative_boost", "queries", "tie_breaker", "functions", "score_mode",
+	"boost_mode", "max_boost", "min_score", "weight",
+
+	// main parameters
+	"value", "values", "ids", "id", "_index", "_id", "index", "routing",
+	"fields", "type", "like", "unlike", "doc", "clauses", "intervals",
+	"end", "in_order", "pattern", "little", "big", "include", "exclude",
+	"script", "source", "lang", "params", "origin", "pivot", "scale", "offset",
+	"decay", "lat", "lon",
+
+	// other parameters
+	"gt", "gte", "lt", "lte", "format", "relation", "time_zone",
+	"default_field", "default_operator", "operator", "analyzer",
+	"quote_analyzer", "quote_field_suffix", "auto_generate_synonyms_phrase_query",
+	"fuzziness", "max_expansions", "prefix_length", "transpositions",
+	"fuzzy_max_expansions", "fuzzy_prefix_length", "fuzzy_transpositions",
+	"fuzzy_rewrite", "rewrite", "lenient", "zero_terms_query", "slop",
+	"phrase_slop", "flags", "allow_leading_wildcard", "analyze_wildcard",
+	"enable_position_increments", "max_determinized_states",
+	"minimum_should_match_field", "minimum_should_match_script",
+	"factor", "modifier", "missing", "keyed", "percents", "tdigest",
+	"compression", "hdr", "number_of_significant_value_digits",
+	"precision_threshold", "show_distribution", "max_children",
+	"pre_tags", "post_tags", "tags_schema", "fragment_size",
+	"number_of_fragments", "fragmenter", "fragment_offset", "no_match_size",
+	"boundary_chars", "boundary_max_scan", "boundary_scanner",
+	"boundary_scanner_locale", "encoder", "force_source", "highlight_query",
+	"matched_fields", "phrase_limit", "require_field_match", "order",
+
+	// common parameters
+	"minimum_should_match", "_name", "boost",
+
+	// sub-aggregations
+	"aggs", "aggregations",
+}
+
+// keyRanks maps the keys of keyOrder to their position, starting at 1 so that
+// unknown keys have the lowest rank.
+var keyRanks = func() map[string]int {
+	ranks := make(map[string]int, len(keyOrder))
+	for i, k := range keyOrder {
+		ranks[k] = i + 1
+	}
+	return ranks
+}()
+
+// orderKeys sorts the keys of an object in the order documented in
+// MarshalOrdered, depending on the key of the object in its parent object.
+func orderKeys(keys []string, parent string) {
+	if userKeyed[parent] {
+		sort.Strings(keys)
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := keyRanks[keys[i]], keyRanks[keys[j]]
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+}
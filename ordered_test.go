@@ -0,0 +1,171 @@
+package esquery
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestMarshalOrdered(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Mappable
+		exp  string
+	}{
+		{
+			"query before aggs",
+			Search().
+				Aggs(Avg("avg_score", "score")).
+				Size(0).
+				Query(Term("status", "open")),
+			`{"query":{"term":{"status":{"value":"open"}}},"size":0,"aggs":{"avg_score":{"avg":{"field":"score"}}}}`,
+		},
+		{
+			"bool clauses and params",
+			Bool().
+				MustNot(Exists("fixed_at")).
+				Should(Term("kev", true)).
+				Filter(Term("tenant", "acme")).
+				Must(MatchAll()).
+				Boost(2).
+				MinimumShouldMatch(1),
+			`{"bool":{"must":[{"match_all":{}}],"filter":[{"term":{"tenant":{"value":"acme"}}}],"should":[{"term":{"kev":{"value":true}}}],"must_not":[{"exists":{"field":"fixed_at"}}],"minimum_should_match":1,"boost":2}}`,
+		},
+		{
+			"query type before params",
+			Range("score").Lte(9).Gte(7).Boost(1.5).Format("number"),
+			`{"range":{"score":{"gte":7,"lte":9,"format":"number","boost":1.5}}}`,
+		},
+		{
+			"path and field first",
+			Nested("packages", Term("packages.name", "<openssl>")).ScoreMode(NestedScoreMax),
+			`{"nested":{"path":"packages","query":{"term":{"packages.name":{"value":"<openssl>"}}},"score_mode":"max"}}`,
+		},
+		{
+			"HTML characters are not escaped",
+			Bool().Filter(Term("a", "<b>&</b>"), Term("a", "<b>\x01</b>")),
+			`{"bool":{"filter":[{"term":{"a":{"value":"<b>&</b>"}}},{"term":{"a":{"value":"<b>\u0001</b>"}}}]}}`,
+		},
+		{
+			"user-defined keys are sorted",
+			TermsAgg("by_status", "status").
+				Order(map[string]string{"_key": "asc", "_count": "desc"}).
+				Aggs(Sum("total", "score"), Max("boost", "boost")),
+			`{"terms":{"field":"status","order":{"_count":"desc","_key":"asc"}},"aggs":{"boost":{"max":{"field":"boost"}},"total":{"sum":{"field":"score"}}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MarshalOrdered(test.q)
+			if err != nil {
+				t.Fatalf("failed encoding: %s", err)
+			}
+			if string(got) != test.exp {
+				t.Errorf("expected %s, got %s", test.exp, got)
+			}
+		})
+	}
+}
+
+// TestMarshalOrderedEquivalent checks that the ordered encoding of a request
+// decodes to the same value as the encoding of its Map.
+func TestMarshalOrderedEquivalent(t *testing.T) {
+	for _, q := range []Mappable{goldenRequest(), benchmarkRequest()} {
+		exp, err := json.Marshal(q.Map())
+		if err != nil {
+			t.Fatalf("failed marshaling: %s", err)
+		}
+		got, err := MarshalOrdered(q)
+		if err != nil {
+			t.Fatalf("failed encoding: %s", err)
+		}
+
+		var expValue, gotValue interface{}
+		_ = json.Unmarshal(exp, &expValue)
+		if err := json.Unmarshal(got, &gotValue); err != nil {
+			t.Fatalf("failed decoding %s: %s", got, err)
+		}
+		if !reflect.DeepEqual(expValue, gotValue) {
+			t.Errorf("expected %s, got %s", exp, got)
+		}
+	}
+}
+
+func TestPrettyPrint(t *testing.T) {
+	got := PrettyPrint(goldenRequest())
+
+	golden := filepath.Join("testdata", "search_request.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, []byte(got+"\n"), 0644); err != nil {
+			t.Fatalf("failed updating golden file: %s", err)
+		}
+	}
+
+	exp, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed reading golden file: %s", err)
+	}
+	if got+"\n" != string(exp) {
+		t.Errorf("output doesn't match %s (run with -update to regenerate it), got:\n%s", golden, got)
+	}
+
+	if got := PrettyPrint(Term("a", math.NaN())); !strings.HasPrefix(got, "esquery: ") {
+		t.Errorf("expected an error message, got %s", got)
+	}
+}
+
+// goldenRequest returns the search request of the golden file, which uses
+// most request options, compound queries and aggregations.
+func goldenRequest() *SearchRequest {
+	return Search().
+		Query(Bool().
+			Must(
+				Match("title", "openssl").Operator(OperatorAnd).Fuzziness("AUTO"),
+				MultiMatch("heartbleed").Fields("title^2", "description"),
+			).
+			Filter(
+				Term("tenant", "acme"),
+				Terms("severity", "high", "critical").Boost(1.2),
+				Range("published_at").Gte("now-30d").Lt("now").TimeZone("+01:00"),
+			).
+			Should(
+				Exists("cve"),
+				Nested("packages", Term("packages.fixable", true)).ScoreMode(NestedScoreMax),
+			).
+			MustNot(Fuzzy("status", "dismised").Fuzziness("1")).
+			MinimumShouldMatch(1).
+			Boost(1.5)).
+		PostFilter(Term("kev", true)).
+		Sort("score", OrderDesc).
+		Sort("_id", OrderAsc).
+		SearchAfter(9.8, "CVE-2014-0160").
+		From(0).
+		Size(25).
+		SourceIncludes("title", "severity", "score").
+		SourceExcludes("raw").
+		Highlight(Highlight().Field("title", Highlight().FragmentSize(100)).PreTags("<b>").PostTags("</b>")).
+		ScriptField("risk", InlineScript("doc['score'].value * params.factor").Param("factor", 2)).
+		Explain(true).
+		Timeout(5*time.Second).
+		Aggs(
+			TermsAgg("by_severity", "severity").
+				Size(10).
+				Order(map[string]string{"_count": "desc"}).
+				Aggs(
+					Avg("avg_score", "score").Missing(0),
+					Max("max_score", "score"),
+				),
+			FilterAgg("fixable", Exists("fixed_version")).Aggs(
+				Cardinality("packages", "package").PrecisionThreshold(100),
+			),
+		)
+}
@@ -0,0 +1,182 @@
+package esquery
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fatih/structs"
+)
+
+type paramsTest struct {
+	name   string
+	params interface{}
+	got    map[string]interface{}
+}
+
+// TestParamsMap checks that the hand-written toMap implementations of the
+// parameter structs generate the same maps as the structs package (to which
+// the scripts of aggregations are added separately).
+func TestParamsMap(t *testing.T) {
+	yes, no := true, false
+	type point struct {
+		Lat float64 `structs:"lat"`
+		Lon float64 `structs:"lon"`
+	}
+
+	matchAll := matchParams{
+		Qry:          "openssl",
+		Anl:          "standard",
+		AutoGenerate: &yes,
+		Fuzz:         "AUTO",
+		MaxExp:       10,
+		PrefLen:      2,
+		Trans:        &no,
+		FuzzyRw:      "constant_score",
+		Lent:         true,
+		Op:           OperatorAnd,
+		MinMatch:     "75%",
+		ZeroTerms:    ZeroTermsAll,
+		Slp:          3,
+	}
+	multiMatchAll := multiMatchParams{
+		Qry:          3,
+		Fields:       []string{"title^2", "body"},
+		Type:         MatchTypeCrossFields,
+		TieBrk:       0.3,
+		Boost:        1.5,
+		Anl:          "standard",
+		AutoGenerate: &no,
+		Fuzz:         "1",
+		MaxExp:       10,
+		PrefLen:      2,
+		Trans:        &yes,
+		FuzzyRw:      "constant_score",
+		Lent:         &yes,
+		Op:           OperatorAnd,
+		MinMatch:     "2",
+		ZeroTerms:    ZeroTermsAll,
+		Slp:          1,
+	}
+	rangeAll := rangeQueryParams{
+		Gt:       0,
+		Gte:      "now-1d",
+		Lt:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Lte:      point{1, 2},
+		Format:   "yyyy-MM-dd",
+		Relation: RangeWithin,
+		TimeZone: "+01:00",
+		Boost:    2,
+	}
+	fuzzyAll := fuzzyQueryParams{
+		Value:          "openssl",
+		Fuzziness:      "AUTO",
+		MaxExpansions:  50,
+		PrefixLength:   1,
+		Transpositions: &no,
+		Rewrite:        "constant_score",
+	}
+	highlightAll := highlighParams{
+		PreTags:               []string{"<b>"},
+		PostTags:              []string{"</b>"},
+		FragmentSize:          150,
+		NumberOfFragments:     3,
+		Type:                  HighlighterFvh,
+		BoundaryChars:         ".,!?",
+		BoundaryMaxScan:       20,
+		BoundaryScanner:       BoundaryScannerWord,
+		BoundaryScannerLocale: "en-US",
+		Encoder:               EncoderHtml,
+		ForceSource:           &yes,
+		Fragmenter:            FragmenterSimple,
+		FragmentOffset:        2,
+		MatchedFields:         []string{"title", "title.plain"},
+		NoMatchSize:           100,
+		Order:                 OrderScore,
+		PhraseLimit:           256,
+		RequireFieldMatch:     &no,
+		TagsSchema:            TagsSchemaStyled,
+	}
+	baseAll := BaseAggParams{
+		Field: "score",
+		Miss:  &point{3, 4},
+		Scr:   InlineScript("_value * 2").Param("f", 2),
+	}
+
+	tests := []paramsTest{
+		{"match: empty", matchParams{}, matchParams{}.toMap()},
+		{"match: all", matchAll, matchAll.toMap()},
+		{"match: struct query", matchParams{Qry: point{1, 2}}, matchParams{Qry: point{1, 2}}.toMap()},
+		{"multi_match: empty", multiMatchParams{}, multiMatchParams{}.toMap()},
+		{"multi_match: all", multiMatchAll, multiMatchAll.toMap()},
+		{"multi_match: empty fields", multiMatchParams{Fields: []string{}}, multiMatchParams{Fields: []string{}}.toMap()},
+		{"range: empty", rangeQueryParams{}, rangeQueryParams{}.toMap()},
+		{"range: all", rangeAll, rangeAll.toMap()},
+		{"range: nil pointer", rangeQueryParams{Gt: (*point)(nil)}, rangeQueryParams{Gt: (*point)(nil)}.toMap()},
+		{"fuzzy: empty", fuzzyQueryParams{}, fuzzyQueryParams{}.toMap()},
+		{"fuzzy: all", fuzzyAll, fuzzyAll.toMap()},
+		{"highlight: empty", highlighParams{}, highlighParams{}.toMap()},
+		{"highlight: all", highlightAll, highlightAll.toMap()},
+		{"base agg: empty", &BaseAggParams{}, (&BaseAggParams{}).toMap()},
+		{"base agg: all", &baseAll, baseAll.toMap()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exp := structs.Map(test.params)
+			if params, ok := test.params.(*BaseAggParams); ok {
+				params.mapScript(exp)
+			}
+			if !reflect.DeepEqual(exp, test.got) {
+				expJSON, _ := json.Marshal(exp)
+				gotJSON, _ := json.Marshal(test.got)
+				t.Errorf("expected %s, got %s", expJSON, gotJSON)
+			}
+		})
+	}
+}
+
+func BenchmarkParamsMap(b *testing.B) {
+	match := Match("title", "openssl").Operator(OperatorAnd).Fuzziness("AUTO").ZeroTermsQuery(ZeroTermsAll)
+	multiMatch := MultiMatch("openssl").Fields("title^2", "body").Type(MatchTypeBestFields).TieBreaker(0.3)
+	rng := Range("created_at").Gte("now-1d").Lt("now").Format("date_math").Relation(RangeWithin)
+	fuzzy := Fuzzy("title", "opnssl").Fuzziness("2").PrefixLength(1)
+	highlight := Highlight().PreTags("<b>").PostTags("</b>").Type(HighlighterFvh).Order(OrderScore)
+	avg := Avg("avg_score", "score").Missing(0)
+
+	benchmarks := []struct {
+		name    string
+		params  interface{}
+		toMap   func() map[string]interface{}
+		mapping Mappable
+	}{
+		{"match", match.params, match.params.toMap, match},
+		{"multi_match", multiMatch.params, multiMatch.params.toMap, multiMatch},
+		{"range", rng.params, rng.params.toMap, rng},
+		{"fuzzy", fuzzy.params, fuzzy.params.toMap, fuzzy},
+		{"highlight", highlight.params, highlight.params.toMap, highlight},
+		{"base_agg", avg.BaseAggParams, avg.BaseAggParams.toMap, avg},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name+"/structs", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				structs.Map(bm.params)
+			}
+		})
+		b.Run(bm.name+"/hand-written", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.toMap()
+			}
+		})
+		b.Run(bm.name+"/Map", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.mapping.Map()
+			}
+		})
+	}
+}
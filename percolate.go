@@ -0,0 +1,187 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// DefaultPercolatorField is the name of the percolator field queries are
+// registered in, unless specified otherwise.
+const DefaultPercolatorField = "query"
+
+// RegisterQueryRequest represents a request to store a query in an index
+// with a field of type "percolator", so that it can later be matched against
+// documents using a percolate query. See:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/percolator.html
+type RegisterQueryRequest struct {
+	index  string
+	id     string
+	field  string
+	query  Mappable
+	fields map[string]interface{}
+}
+
+// RegisterQuery creates a new request storing the provided query in the
+// provided percolator index, under the provided document ID.
+func RegisterQuery(index, id string, q Mappable) *RegisterQueryRequest {
+	return &RegisterQueryRequest{
+		index: index,
+		id:    id,
+		field: DefaultPercolatorField,
+		query: q,
+	}
+}
+
+// Field sets the name of the percolator field the query is stored in.
+// Defaults to DefaultPercolatorField.
+func (req *RegisterQueryRequest) Field(name string) *RegisterQueryRequest {
+	req.field = name
+	return req
+}
+
+// Set sets an additional field of the stored document, e.g. metadata about
+// the alert the query belongs to.
+func (req *RegisterQueryRequest) Set(name string, value interface{}) *RegisterQueryRequest {
+	if req.fields == nil {
+		req.fields = make(map[string]interface{})
+	}
+	req.fields[name] = value
+	return req
+}
+
+// GetQuery returns the query stored by the request.
+func (req *RegisterQueryRequest) GetQuery() Mappable {
+	return req.query
+}
+
+// Clone returns a deep copy of the request.
+func (req *RegisterQueryRequest) Clone() *RegisterQueryRequest {
+	c := *req
+	c.query = cloneMappable(req.query)
+	c.fields = cloneMap(req.fields)
+	return &c
+}
+
+// Map returns a map representation of the stored document, thus implementing
+// the Mappable interface.
+func (req *RegisterQueryRequest) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(req.fields)+1)
+	for name, value := range req.fields {
+		m[name] = value
+	}
+	if req.query != nil {
+		m[req.field] = req.query.Map()
+	}
+	return m
+}
+
+// Validate validates the stored query, implementing the Validator interface.
+func (req *RegisterQueryRequest) Validate() error {
+	var v validation
+	v.field("", req.field)
+	v.child(jsonPath(req.field), req.query)
+	return v.err()
+}
+
+// Run executes the request using the provided ElasticSearch client. Zero or
+// more index options can be provided as well. It returns the standard Response
+// type of the official Go client. The request is validated first, see
+// Validate.
+func (req *RegisterQueryRequest) Run(
+	api *elasticsearch.Client,
+	o ...func(*esapi.IndexRequest),
+) (res *esapi.Response, err error) {
+	return req.RunIndex(api.Index, o...)
+}
+
+// RunIndex is the same as the Run method, except that it accepts a value of
+// type esapi.Index (usually this is the Index field of an elasticsearch.Client
+// object). Since the ElasticSearch client does not provide an interface type
+// for its API (which would allow implementation of mock clients), this provides
+// a workaround. The Index function in the ES client is actually a field of a
+// function type.
+func (req *RegisterQueryRequest) RunIndex(
+	index esapi.Index,
+	o ...func(*esapi.IndexRequest),
+) (res *esapi.Response, err error) {
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = json.NewEncoder(&b).Encode(req.Map())
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append([]func(*esapi.IndexRequest){index.WithDocumentID(req.id)}, o...)
+
+	return index(req.index, &b, opts...)
+}
+
+//----------------------------------------------------------------------------//
+
+// percolatorSlotField is the name of the hit field listing the percolated
+// documents matched by a stored query.
+const percolatorSlotField = "_percolator_document_slot"
+
+// PercolateHit is a hit of a search request containing percolate queries,
+// i.e. a stored query that matched one or more of the percolated documents.
+type PercolateHit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Score  float64         `json:"_score"`
+	Source json.RawMessage `json:"_source"`
+
+	// Slots holds the indexes of the matched documents, as provided to
+	// PercolateQuery.Documents, keyed by the name of the percolate query (an
+	// empty string for unnamed queries).
+	Slots map[string][]int `json:"-"`
+}
+
+// DecodePercolateHits decodes the hits of a search response body (e.g. the
+// Body of the esapi.Response returned by SearchRequest.Run), including the
+// slots of the documents each hit matched.
+func DecodePercolateHits(body io.Reader) ([]PercolateHit, error) {
+	var res struct {
+		Hits struct {
+			Hits []struct {
+				PercolateHit
+				Fields map[string]json.RawMessage `json:"fields"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	err := json.NewDecoder(body).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]PercolateHit, len(res.Hits.Hits))
+	for i, h := range res.Hits.Hits {
+		hits[i] = h.PercolateHit
+		for field, value := range h.Fields {
+			if !strings.HasPrefix(field, percolatorSlotField) {
+				continue
+			}
+			name := strings.TrimPrefix(strings.TrimPrefix(field, percolatorSlotField), "_")
+
+			var slots []int
+			err = json.Unmarshal(value, &slots)
+			if err != nil {
+				return nil, err
+			}
+			if hits[i].Slots == nil {
+				hits[i].Slots = make(map[string][]int)
+			}
+			hits[i].Slots[name] = slots
+		}
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,116 @@
+package esquery
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+func TestRegisterQuery(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"register a query with metadata",
+			RegisterQuery("alerts", "1", Match("message", "bonsai tree")).
+				Field("rule").
+				Set("owner", "kimchy"),
+			map[string]interface{}{
+				"rule": map[string]interface{}{
+					"match": map[string]interface{}{
+						"message": map[string]interface{}{
+							"query": "bonsai tree",
+						},
+					},
+				},
+				"owner": "kimchy",
+			},
+		},
+	})
+
+	t.Run("RunIndex", func(t *testing.T) {
+		var gotIndex, gotID, gotBody string
+		index := esapi.Index(func(index string, body io.Reader, o ...func(*esapi.IndexRequest)) (*esapi.Response, error) {
+			var req esapi.IndexRequest
+			for _, f := range o {
+				f(&req)
+			}
+			b, _ := ioutil.ReadAll(body)
+			gotIndex, gotID, gotBody = index, req.DocumentID, string(b)
+			return &esapi.Response{StatusCode: 201}, nil
+		})
+
+		_, err := RegisterQuery("alerts", "1", Term("status", "open")).RunIndex(index)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		expBody := `{"query":{"term":{"status":{"value":"open"}}}}` + "\n"
+		if gotIndex != "alerts" || gotID != "1" || gotBody != expBody {
+			t.Errorf("unexpected request to %q/%q: %s", gotIndex, gotID, gotBody)
+		}
+	})
+}
+
+func TestDecodePercolateHits(t *testing.T) {
+	body := `{
+		"hits": {
+			"hits": [
+				{
+					"_index": "alerts",
+					"_id": "1",
+					"_score": 1.5,
+					"_source": {"query": {"match_all": {}}},
+					"fields": {"_percolator_document_slot": [0, 2]}
+				},
+				{
+					"_index": "alerts",
+					"_id": "2",
+					"_score": 1,
+					"_source": {},
+					"fields": {"_percolator_document_slot_incoming": [1]}
+				},
+				{
+					"_index": "alerts",
+					"_id": "3",
+					"_score": 0.5,
+					"_source": {}
+				}
+			]
+		}
+	}`
+
+	hits, err := DecodePercolateHits(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := []PercolateHit{
+		{
+			Index:  "alerts",
+			ID:     "1",
+			Score:  1.5,
+			Source: json.RawMessage(`{"query": {"match_all": {}}}`),
+			Slots:  map[string][]int{"": {0, 2}},
+		},
+		{
+			Index:  "alerts",
+			ID:     "2",
+			Score:  1,
+			Source: json.RawMessage(`{}`),
+			Slots:  map[string][]int{"incoming": {1}},
+		},
+		{
+			Index:  "alerts",
+			ID:     "3",
+			Score:  0.5,
+			Source: json.RawMessage(`{}`),
+		},
+	}
+	if !reflect.DeepEqual(exp, hits) {
+		t.Errorf("expected %+v, got %+v", exp, hits)
+	}
+}
@@ -11,6 +11,7 @@ type BoolQuery struct {
 	should             []Mappable
 	minimumShouldMatch int16
 	boost              float32
+	frozen             bool
 }
 
 // Bool creates a new compound query of type "bool".
@@ -21,6 +22,7 @@ func Bool() *BoolQuery {
 // Must adds one or more queries of type "must" to the bool query. Must can be
 // called multiple times, queries will be appended to existing ones.
 func (q *BoolQuery) Must(must ...Mappable) *BoolQuery {
+	q = q.mutable()
 	q.must = append(q.must, must...)
 	return q
 }
@@ -28,6 +30,7 @@ func (q *BoolQuery) Must(must ...Mappable) *BoolQuery {
 // Filter adds one or more queries of type "filter" to the bool query. Filter
 // can be called multiple times, queries will be appended to existing ones.
 func (q *BoolQuery) Filter(filter ...Mappable) *BoolQuery {
+	q = q.mutable()
 	q.filter = append(q.filter, filter...)
 	return q
 }
@@ -35,6 +38,7 @@ func (q *BoolQuery) Filter(filter ...Mappable) *BoolQuery {
 // Must adds one or more queries of type "must_not" to the bool query. MustNot
 // can be called multiple times, queries will be appended to existing ones.
 func (q *BoolQuery) MustNot(mustnot ...Mappable) *BoolQuery {
+	q = q.mutable()
 	q.mustNot = append(q.mustNot, mustnot...)
 	return q
 }
@@ -42,6 +46,7 @@ func (q *BoolQuery) MustNot(mustnot ...Mappable) *BoolQuery {
 // Should adds one or more queries of type "should" to the bool query. Should can be
 // called multiple times, queries will be appended to existing ones.
 func (q *BoolQuery) Should(should ...Mappable) *BoolQuery {
+	q = q.mutable()
 	q.should = append(q.should, should...)
 	return q
 }
@@ -49,16 +54,77 @@ func (q *BoolQuery) Should(should ...Mappable) *BoolQuery {
 // MinimumShouldMatch sets the number or percentage of should clauses returned
 // documents must match.
 func (q *BoolQuery) MinimumShouldMatch(val int16) *BoolQuery {
+	q = q.mutable()
 	q.minimumShouldMatch = val
 	return q
 }
 
 // Boost sets the boost value for the query.
 func (q *BoolQuery) Boost(val float32) *BoolQuery {
+	q = q.mutable()
 	q.boost = val
 	return q
 }
 
+// GetMust returns the queries of the "must" clause.
+func (q *BoolQuery) GetMust() []Mappable {
+	if q.frozen {
+		return cloneMappables(q.must)
+	}
+	return q.must
+}
+
+// GetFilter returns the queries of the "filter" clause.
+func (q *BoolQuery) GetFilter() []Mappable {
+	if q.frozen {
+		return cloneMappables(q.filter)
+	}
+	return q.filter
+}
+
+// GetMustNot returns the queries of the "must_not" clause.
+func (q *BoolQuery) GetMustNot() []Mappable {
+	if q.frozen {
+		return cloneMappables(q.mustNot)
+	}
+	return q.mustNot
+}
+
+// GetShould returns the queries of the "should" clause.
+func (q *BoolQuery) GetShould() []Mappable {
+	if q.frozen {
+		return cloneMappables(q.should)
+	}
+	return q.should
+}
+
+// Clone returns a deep copy of the query.
+func (q *BoolQuery) Clone() *BoolQuery {
+	c := *q
+	c.frozen = false
+	c.must = cloneMappables(q.must)
+	c.filter = cloneMappables(q.filter)
+	c.mustNot = cloneMappables(q.mustNot)
+	c.should = cloneMappables(q.should)
+	return &c
+}
+
+// Freeze makes the query and the bool queries nested in it immutable, so that
+// its setters return a modified copy instead (see SearchRequest.Freeze).
+func (q *BoolQuery) Freeze() *BoolQuery {
+	*q = *q.Clone()
+	freeze(q)
+	return q
+}
+
+// mutable returns the query if it is not frozen, or a mutable copy otherwise.
+func (q *BoolQuery) mutable() *BoolQuery {
+	if q.frozen {
+		return q.Clone()
+	}
+	return q
+}
+
 // Map returns a map representation of the bool query, thus implementing
 // the Mappable interface.
 func (q *BoolQuery) Map() map[string]interface{} {
@@ -106,3 +172,51 @@ func (q *BoolQuery) Map() map[string]interface{} {
 		"bool": structs.Map(data),
 	}
 }
+
+// writeJSON writes the query in the same way as Map, see EncodeJSON.
+func (q *BoolQuery) writeJSON(e *encoder) {
+	e.openObject()
+	e.key("bool")
+	e.openObject()
+	if q.boost != 0 {
+		e.key("boost")
+		e.float(float64(q.boost), 32)
+	}
+	if len(q.filter) > 0 {
+		e.key("filter")
+		e.clauses(q.filter)
+	}
+	if q.minimumShouldMatch != 0 {
+		e.key("minimum_should_match")
+		e.int(int64(q.minimumShouldMatch))
+	}
+	if len(q.must) > 0 {
+		e.key("must")
+		e.clauses(q.must)
+	}
+	if len(q.mustNot) > 0 {
+		e.key("must_not")
+		e.clauses(q.mustNot)
+	}
+	if len(q.should) > 0 {
+		e.key("should")
+		e.clauses(q.should)
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate validates the query and all of its clauses, implementing the
+// Validator interface.
+func (q *BoolQuery) Validate() error {
+	var v validation
+	v.children("/bool/must", q.must)
+	v.children("/bool/filter", q.filter)
+	v.children("/bool/must_not", q.mustNot)
+	v.children("/bool/should", q.should)
+	v.check(
+		q.minimumShouldMatch == 0 || len(q.should) > 0,
+		"/bool/minimum_should_match", "is set but the query has no should clauses",
+	)
+	return v.err()
+}
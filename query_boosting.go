@@ -34,6 +34,14 @@ func (q *BoostingQuery) NegativeBoost(b float32) *BoostingQuery {
 	return q
 }
 
+// Clone returns a deep copy of the query.
+func (q *BoostingQuery) Clone() *BoostingQuery {
+	c := *q
+	c.Pos = cloneMappable(q.Pos)
+	c.Neg = cloneMappable(q.Neg)
+	return &c
+}
+
 // Map returns a map representation of the boosting query, thus implementing
 // the Mappable interface.
 func (q *BoostingQuery) Map() map[string]interface{} {
@@ -45,3 +53,16 @@ func (q *BoostingQuery) Map() map[string]interface{} {
 		},
 	}
 }
+
+// Validate validates the query and its positive and negative parts,
+// implementing the Validator interface.
+func (q *BoostingQuery) Validate() error {
+	var v validation
+	v.child("/boosting/positive", q.Pos)
+	v.child("/boosting/negative", q.Neg)
+	v.check(
+		q.NegBoost >= 0 && q.NegBoost <= 1,
+		"/boosting/negative_boost", "must be between 0 and 1",
+	)
+	return v.err()
+}
@@ -0,0 +1,112 @@
+package esquery
+
+import "github.com/fatih/structs"
+
+// CombinedFieldsQuery represents a query of type "combined_fields", as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-combined-fields-query.html
+type CombinedFieldsQuery struct {
+	params combinedFieldsParams
+}
+
+// GetFields returns the names of the fields the query applies to, without
+// their boosts.
+func (q *CombinedFieldsQuery) GetFields() []string {
+	return fieldNames(q.params.Fields)
+}
+
+// Clone returns a deep copy of the query.
+func (q *CombinedFieldsQuery) Clone() *CombinedFieldsQuery {
+	c := *q
+	c.params.Fields = cloneStrings(q.params.Fields)
+	return &c
+}
+
+// Map returns a map representation of the query; implementing the
+// Mappable interface.
+func (q *CombinedFieldsQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"combined_fields": structs.Map(q.params),
+	}
+}
+
+// Validate checks that the query has at least one field, implementing the
+// Validator interface.
+func (q *CombinedFieldsQuery) Validate() error {
+	var v validation
+	v.check(len(q.params.Fields) > 0, "/combined_fields/fields", "at least one field is required")
+	return v.err()
+}
+
+type combinedFieldsParams struct {
+	Qry          string        `structs:"query"`
+	Fields       []string      `structs:"fields"`
+	Op           MatchOperator `structs:"operator,string,omitempty"`
+	MinMatch     string        `structs:"minimum_should_match,omitempty"`
+	AutoGenerate *bool         `structs:"auto_generate_synonyms_phrase_query,omitempty"`
+	ZeroTerms    ZeroTerms     `structs:"zero_terms_query,string,omitempty"`
+	Boost        float32       `structs:"boost,omitempty"`
+}
+
+// CombinedFields creates a new query of type "combined_fields", searching the
+// provided text in the provided fields as if they were a single field.
+// Fields can include a boost (e.g. "title^2"), see also BoostedFields.
+func CombinedFields(query string, fields ...string) *CombinedFieldsQuery {
+	return &CombinedFieldsQuery{
+		params: combinedFieldsParams{
+			Qry:    query,
+			Fields: fields,
+		},
+	}
+}
+
+// Query sets the text to search for.
+func (q *CombinedFieldsQuery) Query(query string) *CombinedFieldsQuery {
+	q.params.Qry = query
+	return q
+}
+
+// Fields adds fields to search in.
+func (q *CombinedFieldsQuery) Fields(a ...string) *CombinedFieldsQuery {
+	q.params.Fields = append(q.params.Fields, a...)
+	return q
+}
+
+// BoostedFields adds fields with their boosts to the fields used in the query.
+func (q *CombinedFieldsQuery) BoostedFields(fields ...BoostedField) *CombinedFieldsQuery {
+	return q.Fields(boostedFields(fields)...)
+}
+
+// Operator sets the boolean logic used to interpret text in the query value.
+func (q *CombinedFieldsQuery) Operator(op MatchOperator) *CombinedFieldsQuery {
+	q.params.Op = op
+	return q
+}
+
+// MinimumShouldMatch sets the minimum number of clauses that must match for a
+// document to be returned.
+func (q *CombinedFieldsQuery) MinimumShouldMatch(s string) *CombinedFieldsQuery {
+	q.params.MinMatch = s
+	return q
+}
+
+// AutoGenerateSynonymsPhraseQuery sets the "auto_generate_synonyms_phrase_query"
+// boolean.
+func (q *CombinedFieldsQuery) AutoGenerateSynonymsPhraseQuery(b bool) *CombinedFieldsQuery {
+	q.params.AutoGenerate = &b
+	return q
+}
+
+// ZeroTermsQuery sets the "zero_terms_query" option to use. This indicates
+// whether no documents are returned if the analyzer removes all tokens, such
+// as when using a stop filter.
+func (q *CombinedFieldsQuery) ZeroTermsQuery(s ZeroTerms) *CombinedFieldsQuery {
+	q.params.ZeroTerms = s
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *CombinedFieldsQuery) Boost(b float32) *CombinedFieldsQuery {
+	q.params.Boost = b
+	return q
+}
@@ -0,0 +1,40 @@
+package esquery
+
+import "testing"
+
+func TestCombinedFields(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"combined_fields with defaults",
+			CombinedFields("database systems", "title", "abstract"),
+			map[string]interface{}{
+				"combined_fields": map[string]interface{}{
+					"query":  "database systems",
+					"fields": []string{"title", "abstract"},
+				},
+			},
+		},
+		{
+			"combined_fields with all params",
+			CombinedFields("database systems").
+				BoostedFields(FieldBoost("title", 2), FieldBoost("abstract", 0)).
+				Fields("body").
+				Operator(OperatorAnd).
+				MinimumShouldMatch("3").
+				AutoGenerateSynonymsPhraseQuery(false).
+				ZeroTermsQuery(ZeroTermsAll).
+				Boost(1.5),
+			map[string]interface{}{
+				"combined_fields": map[string]interface{}{
+					"query":                               "database systems",
+					"fields":                              []string{"title^2", "abstract", "body"},
+					"operator":                            "AND",
+					"minimum_should_match":                "3",
+					"auto_generate_synonyms_phrase_query": false,
+					"zero_terms_query":                    "all",
+					"boost":                               1.5,
+				},
+			},
+		},
+	})
+}
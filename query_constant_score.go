@@ -24,6 +24,18 @@ func (q *ConstantScoreQuery) Boost(b float32) *ConstantScoreQuery {
 	return q
 }
 
+// GetFilter returns the filter query.
+func (q *ConstantScoreQuery) GetFilter() Mappable {
+	return q.filter
+}
+
+// Clone returns a deep copy of the query.
+func (q *ConstantScoreQuery) Clone() *ConstantScoreQuery {
+	c := *q
+	c.filter = cloneMappable(q.filter)
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *ConstantScoreQuery) Map() map[string]interface{} {
@@ -34,3 +46,11 @@ func (q *ConstantScoreQuery) Map() map[string]interface{} {
 		}{q.filter.Map(), q.boost}),
 	}
 }
+
+// Validate validates the query and its filter, implementing the Validator
+// interface.
+func (q *ConstantScoreQuery) Validate() error {
+	var v validation
+	v.child("/constant_score/filter", q.filter)
+	return v.err()
+}
@@ -23,6 +23,18 @@ func (q *DisMaxQuery) TieBreaker(b float32) *DisMaxQuery {
 	return q
 }
 
+// GetQueries returns the queries of the dis_max query.
+func (q *DisMaxQuery) GetQueries() []Mappable {
+	return q.queries
+}
+
+// Clone returns a deep copy of the query.
+func (q *DisMaxQuery) Clone() *DisMaxQuery {
+	c := *q
+	c.queries = cloneMappables(q.queries)
+	return &c
+}
+
 // Map returns a map representation of the dis_max query, thus implementing
 // the Mappable interface.
 func (q *DisMaxQuery) Map() map[string]interface{} {
@@ -37,3 +49,12 @@ func (q *DisMaxQuery) Map() map[string]interface{} {
 		}{inner, q.tieBreaker}),
 	}
 }
+
+// Validate validates the query and its sub-queries, implementing the
+// Validator interface.
+func (q *DisMaxQuery) Validate() error {
+	var v validation
+	v.check(len(q.queries) > 0, "/dis_max/queries", "at least one query is required")
+	v.children("/dis_max/queries", q.queries)
+	return v.err()
+}
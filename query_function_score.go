@@ -0,0 +1,797 @@
+package esquery
+
+// FunctionScoreQuery represents a compound query of type "function_score", as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-function-score-query.html
+type FunctionScoreQuery struct {
+	query     Mappable
+	functions []ScoreFunction
+	scoreMode FunctionScoreMode
+	boostMode FunctionBoostMode
+	maxBoost  *float32
+	minScore  *float32
+	boost     float32
+}
+
+// FunctionScore creates a new compound query of type "function_score",
+// modifying the scores of documents matching the provided query.
+func FunctionScore(query Mappable) *FunctionScoreQuery {
+	return &FunctionScoreQuery{
+		query: query,
+	}
+}
+
+// Functions adds one or more score functions to the query. Functions can be
+// called multiple times, functions will be appended to existing ones.
+func (q *FunctionScoreQuery) Functions(functions ...ScoreFunction) *FunctionScoreQuery {
+	q.functions = append(q.functions, functions...)
+	return q
+}
+
+// ScoreMode sets how the scores computed by the functions are combined.
+func (q *FunctionScoreQuery) ScoreMode(mode FunctionScoreMode) *FunctionScoreQuery {
+	q.scoreMode = mode
+	return q
+}
+
+// BoostMode sets how the combined function score is combined with the score
+// of the query.
+func (q *FunctionScoreQuery) BoostMode(mode FunctionBoostMode) *FunctionScoreQuery {
+	q.boostMode = mode
+	return q
+}
+
+// MaxBoost sets the maximum value of the combined function score.
+func (q *FunctionScoreQuery) MaxBoost(b float32) *FunctionScoreQuery {
+	q.maxBoost = &b
+	return q
+}
+
+// MinScore sets the minimum score documents must have in order to be
+// returned.
+func (q *FunctionScoreQuery) MinScore(s float32) *FunctionScoreQuery {
+	q.minScore = &s
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *FunctionScoreQuery) Boost(b float32) *FunctionScoreQuery {
+	q.boost = b
+	return q
+}
+
+// GetQuery returns the query whose score is modified.
+func (q *FunctionScoreQuery) GetQuery() Mappable {
+	return q.query
+}
+
+// GetFunctions returns the score functions.
+func (q *FunctionScoreQuery) GetFunctions() []ScoreFunction {
+	return q.functions
+}
+
+// Clone returns a deep copy of the query.
+func (q *FunctionScoreQuery) Clone() *FunctionScoreQuery {
+	c := *q
+	c.query = cloneMappable(q.query)
+	if q.functions != nil {
+		c.functions = make([]ScoreFunction, len(q.functions))
+		for i, f := range q.functions {
+			c.functions[i] = cloneMappable(f).(ScoreFunction)
+		}
+	}
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *FunctionScoreQuery) Map() map[string]interface{} {
+	params := make(map[string]interface{})
+	if q.query != nil {
+		params["query"] = q.query.Map()
+	}
+	if len(q.functions) > 0 {
+		functions := make([]map[string]interface{}, len(q.functions))
+		for i, f := range q.functions {
+			functions[i] = f.Map()
+		}
+		params["functions"] = functions
+	}
+	if q.scoreMode != 0 {
+		params["score_mode"] = q.scoreMode.String()
+	}
+	if q.boostMode != 0 {
+		params["boost_mode"] = q.boostMode.String()
+	}
+	if q.maxBoost != nil {
+		params["max_boost"] = *q.maxBoost
+	}
+	if q.minScore != nil {
+		params["min_score"] = *q.minScore
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"function_score": params,
+	}
+}
+
+// Validate validates the query, its inner query and its functions,
+// implementing the Validator interface.
+func (q *FunctionScoreQuery) Validate() error {
+	var v validation
+	v.optional("/function_score/query", q.query)
+	for i, f := range q.functions {
+		v.child(jsonPath("function_score", "functions", i), f)
+	}
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// ScoreFunction is the interface implemented by the functions accepted by a
+// function_score query: WeightFunction, RandomScoreFunction,
+// FieldValueFactorFunction, DecayFunction and ScriptScoreFunction.
+type ScoreFunction interface {
+	Mappable
+	scoreFunction()
+}
+
+// scoreFunctionBase contains the options shared by all score functions.
+type scoreFunctionBase struct {
+	filter Mappable
+	weight *float32
+}
+
+func (f *scoreFunctionBase) scoreFunction() {}
+
+// GetFilter returns the query restricting the documents the function applies
+// to, if any.
+func (f *scoreFunctionBase) GetFilter() Mappable {
+	return f.filter
+}
+
+// mapInto adds the shared options that are set to the provided map.
+func (f *scoreFunctionBase) mapInto(m map[string]interface{}) {
+	if f.filter != nil {
+		m["filter"] = f.filter.Map()
+	}
+	if f.weight != nil {
+		m["weight"] = *f.weight
+	}
+}
+
+// validateInto validates the shared options, recording errors in the provided
+// validation.
+func (f *scoreFunctionBase) validateInto(v *validation) {
+	v.optional("/filter", f.filter)
+}
+
+//----------------------------------------------------------------------------//
+
+// WeightFunction represents a score function of type "weight", which
+// multiplies the score by a constant.
+type WeightFunction struct {
+	scoreFunctionBase
+}
+
+// Weight creates a new score function of type "weight" with the provided
+// weight.
+func Weight(w float32) *WeightFunction {
+	f := &WeightFunction{}
+	f.weight = &w
+	return f
+}
+
+// Filter sets a filter that documents must match for the function to apply.
+func (f *WeightFunction) Filter(filter Mappable) *WeightFunction {
+	f.filter = filter
+	return f
+}
+
+// Clone returns a deep copy of the function.
+func (f *WeightFunction) Clone() *WeightFunction {
+	c := *f
+	c.filter = cloneMappable(f.filter)
+	return &c
+}
+
+// Map returns a map representation of the function, thus implementing the
+// Mappable interface.
+func (f *WeightFunction) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	f.mapInto(m)
+	return m
+}
+
+// Validate validates the function's filter, implementing the Validator
+// interface.
+func (f *WeightFunction) Validate() error {
+	var v validation
+	f.validateInto(&v)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// RandomScoreFunction represents a score function of type "random_score",
+// which generates scores uniformly distributed between 0 and 1.
+type RandomScoreFunction struct {
+	scoreFunctionBase
+	seed  interface{}
+	field string
+}
+
+// RandomScore creates a new score function of type "random_score".
+func RandomScore() *RandomScoreFunction {
+	return &RandomScoreFunction{}
+}
+
+// Seed sets the seed of the random scores, making them reproducible.
+func (f *RandomScoreFunction) Seed(seed interface{}) *RandomScoreFunction {
+	f.seed = seed
+	return f
+}
+
+// Field sets the field whose values are combined with the seed.
+func (f *RandomScoreFunction) Field(field string) *RandomScoreFunction {
+	f.field = field
+	return f
+}
+
+// Filter sets a filter that documents must match for the function to apply.
+func (f *RandomScoreFunction) Filter(filter Mappable) *RandomScoreFunction {
+	f.filter = filter
+	return f
+}
+
+// Weight sets a weight to multiply the function's score by.
+func (f *RandomScoreFunction) Weight(w float32) *RandomScoreFunction {
+	f.weight = &w
+	return f
+}
+
+// Clone returns a deep copy of the function.
+func (f *RandomScoreFunction) Clone() *RandomScoreFunction {
+	c := *f
+	c.filter = cloneMappable(f.filter)
+	return &c
+}
+
+// Map returns a map representation of the function, thus implementing the
+// Mappable interface.
+func (f *RandomScoreFunction) Map() map[string]interface{} {
+	params := make(map[string]interface{})
+	if f.seed != nil {
+		params["seed"] = f.seed
+	}
+	if f.field != "" {
+		params["field"] = f.field
+	}
+
+	m := map[string]interface{}{
+		"random_score": params,
+	}
+	f.mapInto(m)
+	return m
+}
+
+// Validate validates the function's filter, implementing the Validator
+// interface.
+func (f *RandomScoreFunction) Validate() error {
+	var v validation
+	f.validateInto(&v)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// FieldValueFactorFunction represents a score function of type
+// "field_value_factor", which computes the score from a document field.
+type FieldValueFactorFunction struct {
+	scoreFunctionBase
+	field    string
+	factor   *float32
+	modifier FieldValueFactorModifier
+	missing  *float64
+}
+
+// FieldValueFactor creates a new score function of type "field_value_factor"
+// on the provided field.
+func FieldValueFactor(field string) *FieldValueFactorFunction {
+	return &FieldValueFactorFunction{field: field}
+}
+
+// Factor sets the value to multiply the field value with.
+func (f *FieldValueFactorFunction) Factor(factor float32) *FieldValueFactorFunction {
+	f.factor = &factor
+	return f
+}
+
+// Modifier sets the modifier to apply to the field value.
+func (f *FieldValueFactorFunction) Modifier(mod FieldValueFactorModifier) *FieldValueFactorFunction {
+	f.modifier = mod
+	return f
+}
+
+// Missing sets the value to use for documents missing the field.
+func (f *FieldValueFactorFunction) Missing(val float64) *FieldValueFactorFunction {
+	f.missing = &val
+	return f
+}
+
+// Filter sets a filter that documents must match for the function to apply.
+func (f *FieldValueFactorFunction) Filter(filter Mappable) *FieldValueFactorFunction {
+	f.filter = filter
+	return f
+}
+
+// Weight sets a weight to multiply the function's score by.
+func (f *FieldValueFactorFunction) Weight(w float32) *FieldValueFactorFunction {
+	f.weight = &w
+	return f
+}
+
+// Clone returns a deep copy of the function.
+func (f *FieldValueFactorFunction) Clone() *FieldValueFactorFunction {
+	c := *f
+	c.filter = cloneMappable(f.filter)
+	return &c
+}
+
+// Map returns a map representation of the function, thus implementing the
+// Mappable interface.
+func (f *FieldValueFactorFunction) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"field": f.field,
+	}
+	if f.factor != nil {
+		params["factor"] = *f.factor
+	}
+	if f.modifier != 0 {
+		params["modifier"] = f.modifier.String()
+	}
+	if f.missing != nil {
+		params["missing"] = *f.missing
+	}
+
+	m := map[string]interface{}{
+		"field_value_factor": params,
+	}
+	f.mapInto(m)
+	return m
+}
+
+// Validate checks that the function has a field name, implementing the
+// Validator interface.
+func (f *FieldValueFactorFunction) Validate() error {
+	var v validation
+	v.field("/field_value_factor/field", f.field)
+	f.validateInto(&v)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// DecayFunction represents a score function of type "gauss", "linear" or
+// "exp", which scores documents depending on the distance of a numeric, date
+// or geo field from an origin.
+type DecayFunction struct {
+	scoreFunctionBase
+	kind           string
+	field          string
+	origin         interface{}
+	scale          interface{}
+	offset         interface{}
+	decay          *float64
+	multiValueMode SortMode
+}
+
+// GaussDecay creates a new decay function of type "gauss" on the provided
+// field.
+func GaussDecay(field string) *DecayFunction {
+	return &DecayFunction{kind: "gauss", field: field}
+}
+
+// LinearDecay creates a new decay function of type "linear" on the provided
+// field.
+func LinearDecay(field string) *DecayFunction {
+	return &DecayFunction{kind: "linear", field: field}
+}
+
+// ExpDecay creates a new decay function of type "exp" on the provided field.
+func ExpDecay(field string) *DecayFunction {
+	return &DecayFunction{kind: "exp", field: field}
+}
+
+// Origin sets the point from which distances are calculated. This is a number
+// for numeric fields, a date or date math expression for date fields, and a
+// GeoPoint or geo string for geo fields.
+func (f *DecayFunction) Origin(origin interface{}) *DecayFunction {
+	f.origin = origin
+	return f
+}
+
+// Scale sets the distance from the origin at which the score equals the
+// decay value (e.g. 10, "10d" or "2km").
+func (f *DecayFunction) Scale(scale interface{}) *DecayFunction {
+	f.scale = scale
+	return f
+}
+
+// Offset sets a distance from the origin within which documents are not
+// decayed.
+func (f *DecayFunction) Offset(offset interface{}) *DecayFunction {
+	f.offset = offset
+	return f
+}
+
+// Decay sets the score of documents at the scale distance from the origin.
+func (f *DecayFunction) Decay(decay float64) *DecayFunction {
+	f.decay = &decay
+	return f
+}
+
+// MultiValueMode sets which value of multi-valued fields is used to compute
+// the distance. Supported modes are min, max, avg and sum.
+func (f *DecayFunction) MultiValueMode(mode SortMode) *DecayFunction {
+	f.multiValueMode = mode
+	return f
+}
+
+// Filter sets a filter that documents must match for the function to apply.
+func (f *DecayFunction) Filter(filter Mappable) *DecayFunction {
+	f.filter = filter
+	return f
+}
+
+// Weight sets a weight to multiply the function's score by.
+func (f *DecayFunction) Weight(w float32) *DecayFunction {
+	f.weight = &w
+	return f
+}
+
+// Clone returns a deep copy of the function.
+func (f *DecayFunction) Clone() *DecayFunction {
+	c := *f
+	c.filter = cloneMappable(f.filter)
+	return &c
+}
+
+// Map returns a map representation of the function, thus implementing the
+// Mappable interface.
+func (f *DecayFunction) Map() map[string]interface{} {
+	fieldParams := make(map[string]interface{})
+	if f.origin != nil {
+		fieldParams["origin"] = f.origin
+	}
+	if f.scale != nil {
+		fieldParams["scale"] = f.scale
+	}
+	if f.offset != nil {
+		fieldParams["offset"] = f.offset
+	}
+	if f.decay != nil {
+		fieldParams["decay"] = *f.decay
+	}
+
+	params := map[string]interface{}{
+		f.field: fieldParams,
+	}
+	if f.multiValueMode != 0 {
+		params["multi_value_mode"] = f.multiValueMode.String()
+	}
+
+	m := map[string]interface{}{
+		f.kind: params,
+	}
+	f.mapInto(m)
+	return m
+}
+
+// Validate checks that the function has a field name, an origin and a scale,
+// implementing the Validator interface.
+func (f *DecayFunction) Validate() error {
+	var v validation
+	v.field(jsonPath(f.kind), f.field)
+	v.check(f.origin != nil, jsonPath(f.kind, f.field, "origin"), "is required")
+	v.check(f.scale != nil, jsonPath(f.kind, f.field, "scale"), "is required")
+	f.validateInto(&v)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// ScriptScoreFunction represents a score function of type "script_score",
+// which computes the score using a script.
+type ScriptScoreFunction struct {
+	scoreFunctionBase
+	script *Script
+}
+
+// ScriptScoreFunc creates a new score function of type "script_score" with
+// the provided script. For a standalone query of type "script_score", use
+// ScriptScore.
+func ScriptScoreFunc(script *Script) *ScriptScoreFunction {
+	return &ScriptScoreFunction{script: script}
+}
+
+// Filter sets a filter that documents must match for the function to apply.
+func (f *ScriptScoreFunction) Filter(filter Mappable) *ScriptScoreFunction {
+	f.filter = filter
+	return f
+}
+
+// Weight sets a weight to multiply the function's score by.
+func (f *ScriptScoreFunction) Weight(w float32) *ScriptScoreFunction {
+	f.weight = &w
+	return f
+}
+
+// Clone returns a deep copy of the function.
+func (f *ScriptScoreFunction) Clone() *ScriptScoreFunction {
+	c := *f
+	c.filter = cloneMappable(f.filter)
+	c.script = cloneScript(f.script)
+	return &c
+}
+
+// Map returns a map representation of the function, thus implementing the
+// Mappable interface.
+func (f *ScriptScoreFunction) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"script_score": map[string]interface{}{
+			"script": f.script.Map(),
+		},
+	}
+	f.mapInto(m)
+	return m
+}
+
+// Validate validates the function's script and filter, implementing the
+// Validator interface.
+func (f *ScriptScoreFunction) Validate() error {
+	var v validation
+	v.child("/script_score/script", f.script)
+	f.validateInto(&v)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// ScriptScoreQuery represents a query of type "script_score", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-script-score-query.html
+type ScriptScoreQuery struct {
+	query    Mappable
+	script   *Script
+	minScore *float32
+	boost    float32
+}
+
+// ScriptScore creates a new query of type "script_score", computing the score
+// of documents matching the provided query using the provided script.
+func ScriptScore(query Mappable, script *Script) *ScriptScoreQuery {
+	return &ScriptScoreQuery{
+		query:  query,
+		script: script,
+	}
+}
+
+// MinScore sets the minimum score documents must have in order to be
+// returned.
+func (q *ScriptScoreQuery) MinScore(s float32) *ScriptScoreQuery {
+	q.minScore = &s
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *ScriptScoreQuery) Boost(b float32) *ScriptScoreQuery {
+	q.boost = b
+	return q
+}
+
+// GetQuery returns the query whose score is modified.
+func (q *ScriptScoreQuery) GetQuery() Mappable {
+	return q.query
+}
+
+// Clone returns a deep copy of the query.
+func (q *ScriptScoreQuery) Clone() *ScriptScoreQuery {
+	c := *q
+	c.query = cloneMappable(q.query)
+	c.script = cloneScript(q.script)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *ScriptScoreQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"query":  q.query.Map(),
+		"script": q.script.Map(),
+	}
+	if q.minScore != nil {
+		params["min_score"] = *q.minScore
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"script_score": params,
+	}
+}
+
+// Validate validates the query, its inner query and its script, implementing
+// the Validator interface.
+func (q *ScriptScoreQuery) Validate() error {
+	var v validation
+	v.child("/script_score/query", q.query)
+	v.child("/script_score/script", q.script)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// FunctionScoreMode is an enumeration type representing supported values for a
+// function_score query's "score_mode" parameter.
+type FunctionScoreMode uint8
+
+const (
+	_ FunctionScoreMode = iota
+
+	// ScoreModeMultiply is the "multiply" score mode
+	ScoreModeMultiply
+
+	// ScoreModeSum is the "sum" score mode
+	ScoreModeSum
+
+	// ScoreModeAvg is the "avg" score mode
+	ScoreModeAvg
+
+	// ScoreModeFirst is the "first" score mode
+	ScoreModeFirst
+
+	// ScoreModeMax is the "max" score mode
+	ScoreModeMax
+
+	// ScoreModeMin is the "min" score mode
+	ScoreModeMin
+)
+
+// String returns a string representation of the score_mode parameter, as
+// known to ElasticSearch.
+func (a FunctionScoreMode) String() string {
+	switch a {
+	case ScoreModeMultiply:
+		return "multiply"
+	case ScoreModeSum:
+		return "sum"
+	case ScoreModeAvg:
+		return "avg"
+	case ScoreModeFirst:
+		return "first"
+	case ScoreModeMax:
+		return "max"
+	case ScoreModeMin:
+		return "min"
+	default:
+		return ""
+	}
+}
+
+// FunctionBoostMode is an enumeration type representing supported values for
+// a function_score query's "boost_mode" parameter.
+type FunctionBoostMode uint8
+
+const (
+	_ FunctionBoostMode = iota
+
+	// BoostModeMultiply is the "multiply" boost mode
+	BoostModeMultiply
+
+	// BoostModeReplace is the "replace" boost mode
+	BoostModeReplace
+
+	// BoostModeSum is the "sum" boost mode
+	BoostModeSum
+
+	// BoostModeAvg is the "avg" boost mode
+	BoostModeAvg
+
+	// BoostModeMax is the "max" boost mode
+	BoostModeMax
+
+	// BoostModeMin is the "min" boost mode
+	BoostModeMin
+)
+
+// String returns a string representation of the boost_mode parameter, as
+// known to ElasticSearch.
+func (a FunctionBoostMode) String() string {
+	switch a {
+	case BoostModeMultiply:
+		return "multiply"
+	case BoostModeReplace:
+		return "replace"
+	case BoostModeSum:
+		return "sum"
+	case BoostModeAvg:
+		return "avg"
+	case BoostModeMax:
+		return "max"
+	case BoostModeMin:
+		return "min"
+	default:
+		return ""
+	}
+}
+
+// FieldValueFactorModifier is an enumeration type representing supported
+// values for a field_value_factor function's "modifier" parameter.
+type FieldValueFactorModifier uint8
+
+const (
+	_ FieldValueFactorModifier = iota
+
+	// ModifierNone is the "none" modifier
+	ModifierNone
+
+	// ModifierLog is the "log" modifier
+	ModifierLog
+
+	// ModifierLog1p is the "log1p" modifier
+	ModifierLog1p
+
+	// ModifierLog2p is the "log2p" modifier
+	ModifierLog2p
+
+	// ModifierLn is the "ln" modifier
+	ModifierLn
+
+	// ModifierLn1p is the "ln1p" modifier
+	ModifierLn1p
+
+	// ModifierLn2p is the "ln2p" modifier
+	ModifierLn2p
+
+	// ModifierSquare is the "square" modifier
+	ModifierSquare
+
+	// ModifierSqrt is the "sqrt" modifier
+	ModifierSqrt
+
+	// ModifierReciprocal is the "reciprocal" modifier
+	ModifierReciprocal
+)
+
+// String returns a string representation of the modifier parameter, as known
+// to ElasticSearch.
+func (a FieldValueFactorModifier) String() string {
+	switch a {
+	case ModifierNone:
+		return "none"
+	case ModifierLog:
+		return "log"
+	case ModifierLog1p:
+		return "log1p"
+	case ModifierLog2p:
+		return "log2p"
+	case ModifierLn:
+		return "ln"
+	case ModifierLn1p:
+		return "ln1p"
+	case ModifierLn2p:
+		return "ln2p"
+	case ModifierSquare:
+		return "square"
+	case ModifierSqrt:
+		return "sqrt"
+	case ModifierReciprocal:
+		return "reciprocal"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,138 @@
+package esquery
+
+import "testing"
+
+func TestFunctionScore(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"function_score: all functions",
+			FunctionScore(Match("title", "remote code execution")).
+				Functions(
+					Weight(2).Filter(Term("severity", "critical")),
+					RandomScore().Seed(10).Field("_seq_no").Weight(0.5),
+					FieldValueFactor("likes").
+						Factor(1.2).
+						Modifier(ModifierSqrt).
+						Missing(1),
+					GaussDecay("published").
+						Origin("now").
+						Scale("10d").
+						Offset("5d").
+						Decay(0.5).
+						Filter(Exists("published")),
+					LinearDecay("location").
+						Origin(GeoPoint{Lat: 40, Lon: -70}).
+						Scale("2km").
+						MultiValueMode(SortModeAvg),
+					ExpDecay("score").Origin(10).Scale(5),
+					ScriptScoreFunc(InlineScript("Math.log(2 + doc['likes'].value)")),
+				).
+				ScoreMode(ScoreModeSum).
+				BoostMode(BoostModeMultiply).
+				MaxBoost(42).
+				MinScore(2).
+				Boost(5),
+			map[string]interface{}{
+				"function_score": map[string]interface{}{
+					"query": map[string]interface{}{
+						"match": map[string]interface{}{
+							"title": map[string]interface{}{
+								"query": "remote code execution",
+							},
+						},
+					},
+					"functions": []map[string]interface{}{
+						{
+							"filter": map[string]interface{}{
+								"term": map[string]interface{}{
+									"severity": map[string]interface{}{"value": "critical"},
+								},
+							},
+							"weight": 2,
+						},
+						{
+							"random_score": map[string]interface{}{
+								"seed":  10,
+								"field": "_seq_no",
+							},
+							"weight": 0.5,
+						},
+						{
+							"field_value_factor": map[string]interface{}{
+								"field":    "likes",
+								"factor":   1.2,
+								"modifier": "sqrt",
+								"missing":  1,
+							},
+						},
+						{
+							"gauss": map[string]interface{}{
+								"published": map[string]interface{}{
+									"origin": "now",
+									"scale":  "10d",
+									"offset": "5d",
+									"decay":  0.5,
+								},
+							},
+							"filter": map[string]interface{}{
+								"exists": map[string]interface{}{"field": "published"},
+							},
+						},
+						{
+							"linear": map[string]interface{}{
+								"location": map[string]interface{}{
+									"origin": map[string]interface{}{"lat": 40, "lon": -70},
+									"scale":  "2km",
+								},
+								"multi_value_mode": "avg",
+							},
+						},
+						{
+							"exp": map[string]interface{}{
+								"score": map[string]interface{}{
+									"origin": 10,
+									"scale":  5,
+								},
+							},
+						},
+						{
+							"script_score": map[string]interface{}{
+								"script": map[string]interface{}{
+									"source": "Math.log(2 + doc['likes'].value)",
+								},
+							},
+						},
+					},
+					"score_mode": "sum",
+					"boost_mode": "multiply",
+					"max_boost":  42,
+					"min_score":  2,
+					"boost":      5,
+				},
+			},
+		},
+		{
+			"script_score",
+			ScriptScore(
+				Match("message", "elasticsearch"),
+				InlineScript("doc['my-int'].value / 10"),
+			).MinScore(1).Boost(2),
+			map[string]interface{}{
+				"script_score": map[string]interface{}{
+					"query": map[string]interface{}{
+						"match": map[string]interface{}{
+							"message": map[string]interface{}{
+								"query": "elasticsearch",
+							},
+						},
+					},
+					"script": map[string]interface{}{
+						"source": "doc['my-int'].value / 10",
+					},
+					"min_score": 1,
+					"boost":     2,
+				},
+			},
+		},
+	})
+}
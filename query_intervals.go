@@ -0,0 +1,665 @@
+package esquery
+
+// IntervalsQuery represents a query of type "intervals", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-intervals-query.html
+type IntervalsQuery struct {
+	field string
+	rule  IntervalsRule
+}
+
+// Intervals creates a new query of type "intervals" on the provided field,
+// using the provided rule.
+func Intervals(field string, rule IntervalsRule) *IntervalsQuery {
+	return &IntervalsQuery{
+		field: field,
+		rule:  rule,
+	}
+}
+
+// GetField returns the name of the field the query applies to.
+func (q *IntervalsQuery) GetField() string {
+	return q.field
+}
+
+// GetRule returns the rule of the query.
+func (q *IntervalsQuery) GetRule() IntervalsRule {
+	return q.rule
+}
+
+// Clone returns a deep copy of the query.
+func (q *IntervalsQuery) Clone() *IntervalsQuery {
+	c := *q
+	c.rule = cloneIntervalsRule(q.rule)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *IntervalsQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"intervals": map[string]interface{}{
+			q.field: q.rule.Map(),
+		},
+	}
+}
+
+// Validate validates the query and its rule, implementing the Validator
+// interface.
+func (q *IntervalsQuery) Validate() error {
+	var v validation
+	v.field("/intervals", q.field)
+	v.child(jsonPath("intervals", q.field), q.rule)
+	return v.err()
+}
+
+// IntervalsRule is the interface implemented by the rules accepted by an
+// intervals query: IntervalsMatchRule, IntervalsPrefixRule,
+// IntervalsWildcardRule, IntervalsFuzzyRule, IntervalsAllOfRule and
+// IntervalsAnyOfRule.
+type IntervalsRule interface {
+	Mappable
+	intervalsRule()
+}
+
+func intervalsRules(rules []IntervalsRule) []map[string]interface{} {
+	maps := make([]map[string]interface{}, len(rules))
+	for i, r := range rules {
+		maps[i] = r.Map()
+	}
+	return maps
+}
+
+// intervalsRules validates a list of intervals rules, recording their errors
+// under the provided path followed by their index.
+func (v *validation) intervalsRules(path string, rules []IntervalsRule) {
+	v.check(len(rules) > 0, path, "at least one rule is required")
+	for i, r := range rules {
+		v.child(path+jsonPath(i), r)
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsMatchRule represents an intervals rule of type "match", which
+// matches analyzed text.
+type IntervalsMatchRule struct {
+	query    string
+	maxGaps  *int
+	ordered  *bool
+	analyzer string
+	useField string
+	filter   *IntervalsFilter
+}
+
+// IntervalsMatch creates a new intervals rule of type "match" with the
+// provided text.
+func IntervalsMatch(query string) *IntervalsMatchRule {
+	return &IntervalsMatchRule{query: query}
+}
+
+func (r *IntervalsMatchRule) intervalsRule() {}
+
+// MaxGaps sets the maximum number of positions between the matching terms.
+// A value of -1 means there is no restriction.
+func (r *IntervalsMatchRule) MaxGaps(n int) *IntervalsMatchRule {
+	r.maxGaps = &n
+	return r
+}
+
+// Ordered sets whether the matching terms must appear in their specified
+// order.
+func (r *IntervalsMatchRule) Ordered(b bool) *IntervalsMatchRule {
+	r.ordered = &b
+	return r
+}
+
+// Analyzer sets the analyzer used to analyze the terms in the query.
+func (r *IntervalsMatchRule) Analyzer(a string) *IntervalsMatchRule {
+	r.analyzer = a
+	return r
+}
+
+// UseField sets a different field to match intervals from.
+func (r *IntervalsMatchRule) UseField(field string) *IntervalsMatchRule {
+	r.useField = field
+	return r
+}
+
+// Filter sets a filter for the intervals returned by the rule.
+func (r *IntervalsMatchRule) Filter(f *IntervalsFilter) *IntervalsMatchRule {
+	r.filter = f
+	return r
+}
+
+// GetFilter returns the filter of the rule, if any.
+func (r *IntervalsMatchRule) GetFilter() *IntervalsFilter {
+	return r.filter
+}
+
+// Clone returns a deep copy of the rule.
+func (r *IntervalsMatchRule) Clone() *IntervalsMatchRule {
+	c := *r
+	c.filter = cloneIntervalsFilter(r.filter)
+	return &c
+}
+
+// Map returns a map representation of the rule, thus implementing the
+// Mappable interface.
+func (r *IntervalsMatchRule) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"query": r.query,
+	}
+	if r.maxGaps != nil {
+		params["max_gaps"] = *r.maxGaps
+	}
+	if r.ordered != nil {
+		params["ordered"] = *r.ordered
+	}
+	if r.analyzer != "" {
+		params["analyzer"] = r.analyzer
+	}
+	if r.useField != "" {
+		params["use_field"] = r.useField
+	}
+	if r.filter != nil {
+		params["filter"] = r.filter.Map()
+	}
+
+	return map[string]interface{}{
+		"match": params,
+	}
+}
+
+// Validate checks that the rule has a query and validates its filter,
+// implementing the Validator interface.
+func (r *IntervalsMatchRule) Validate() error {
+	var v validation
+	v.check(r.query != "", "/match/query", "is required")
+	v.optional("/match/filter", r.filter)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsPrefixRule represents an intervals rule of type "prefix", which
+// matches terms starting with a prefix.
+type IntervalsPrefixRule struct {
+	prefix   string
+	analyzer string
+	useField string
+}
+
+// IntervalsPrefix creates a new intervals rule of type "prefix" with the
+// provided prefix.
+func IntervalsPrefix(prefix string) *IntervalsPrefixRule {
+	return &IntervalsPrefixRule{prefix: prefix}
+}
+
+func (r *IntervalsPrefixRule) intervalsRule() {}
+
+// Analyzer sets the analyzer used to normalize the prefix.
+func (r *IntervalsPrefixRule) Analyzer(a string) *IntervalsPrefixRule {
+	r.analyzer = a
+	return r
+}
+
+// UseField sets a different field to match intervals from.
+func (r *IntervalsPrefixRule) UseField(field string) *IntervalsPrefixRule {
+	r.useField = field
+	return r
+}
+
+// Clone returns a deep copy of the rule.
+func (r *IntervalsPrefixRule) Clone() *IntervalsPrefixRule {
+	c := *r
+	return &c
+}
+
+// Map returns a map representation of the rule, thus implementing the
+// Mappable interface.
+func (r *IntervalsPrefixRule) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"prefix": r.prefix,
+	}
+	if r.analyzer != "" {
+		params["analyzer"] = r.analyzer
+	}
+	if r.useField != "" {
+		params["use_field"] = r.useField
+	}
+
+	return map[string]interface{}{
+		"prefix": params,
+	}
+}
+
+// Validate checks that the rule has a prefix, implementing the Validator
+// interface.
+func (r *IntervalsPrefixRule) Validate() error {
+	var v validation
+	v.check(r.prefix != "", "/prefix/prefix", "is required")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsWildcardRule represents an intervals rule of type "wildcard", which
+// matches terms using a wildcard pattern.
+type IntervalsWildcardRule struct {
+	pattern  string
+	analyzer string
+	useField string
+}
+
+// IntervalsWildcard creates a new intervals rule of type "wildcard" with the
+// provided pattern.
+func IntervalsWildcard(pattern string) *IntervalsWildcardRule {
+	return &IntervalsWildcardRule{pattern: pattern}
+}
+
+func (r *IntervalsWildcardRule) intervalsRule() {}
+
+// Analyzer sets the analyzer used to normalize the pattern.
+func (r *IntervalsWildcardRule) Analyzer(a string) *IntervalsWildcardRule {
+	r.analyzer = a
+	return r
+}
+
+// UseField sets a different field to match intervals from.
+func (r *IntervalsWildcardRule) UseField(field string) *IntervalsWildcardRule {
+	r.useField = field
+	return r
+}
+
+// Clone returns a deep copy of the rule.
+func (r *IntervalsWildcardRule) Clone() *IntervalsWildcardRule {
+	c := *r
+	return &c
+}
+
+// Map returns a map representation of the rule, thus implementing the
+// Mappable interface.
+func (r *IntervalsWildcardRule) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"pattern": r.pattern,
+	}
+	if r.analyzer != "" {
+		params["analyzer"] = r.analyzer
+	}
+	if r.useField != "" {
+		params["use_field"] = r.useField
+	}
+
+	return map[string]interface{}{
+		"wildcard": params,
+	}
+}
+
+// Validate checks that the rule has a pattern, implementing the Validator
+// interface.
+func (r *IntervalsWildcardRule) Validate() error {
+	var v validation
+	v.check(r.pattern != "", "/wildcard/pattern", "is required")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsFuzzyRule represents an intervals rule of type "fuzzy", which
+// matches terms similar to the provided term.
+type IntervalsFuzzyRule struct {
+	term           string
+	prefixLength   *uint16
+	transpositions *bool
+	fuzziness      string
+	analyzer       string
+	useField       string
+}
+
+// IntervalsFuzzy creates a new intervals rule of type "fuzzy" with the
+// provided term.
+func IntervalsFuzzy(term string) *IntervalsFuzzyRule {
+	return &IntervalsFuzzyRule{term: term}
+}
+
+func (r *IntervalsFuzzyRule) intervalsRule() {}
+
+// PrefixLength sets the number of beginning characters left unchanged when
+// creating expansions.
+func (r *IntervalsFuzzyRule) PrefixLength(l uint16) *IntervalsFuzzyRule {
+	r.prefixLength = &l
+	return r
+}
+
+// Transpositions sets whether edits include transpositions of two adjacent
+// characters.
+func (r *IntervalsFuzzyRule) Transpositions(b bool) *IntervalsFuzzyRule {
+	r.transpositions = &b
+	return r
+}
+
+// Fuzziness sets the maximum edit distance allowed for matching.
+func (r *IntervalsFuzzyRule) Fuzziness(fuzz string) *IntervalsFuzzyRule {
+	r.fuzziness = fuzz
+	return r
+}
+
+// Analyzer sets the analyzer used to normalize the term.
+func (r *IntervalsFuzzyRule) Analyzer(a string) *IntervalsFuzzyRule {
+	r.analyzer = a
+	return r
+}
+
+// UseField sets a different field to match intervals from.
+func (r *IntervalsFuzzyRule) UseField(field string) *IntervalsFuzzyRule {
+	r.useField = field
+	return r
+}
+
+// Clone returns a deep copy of the rule.
+func (r *IntervalsFuzzyRule) Clone() *IntervalsFuzzyRule {
+	c := *r
+	return &c
+}
+
+// Map returns a map representation of the rule, thus implementing the
+// Mappable interface.
+func (r *IntervalsFuzzyRule) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"term": r.term,
+	}
+	if r.prefixLength != nil {
+		params["prefix_length"] = *r.prefixLength
+	}
+	if r.transpositions != nil {
+		params["transpositions"] = *r.transpositions
+	}
+	if r.fuzziness != "" {
+		params["fuzziness"] = r.fuzziness
+	}
+	if r.analyzer != "" {
+		params["analyzer"] = r.analyzer
+	}
+	if r.useField != "" {
+		params["use_field"] = r.useField
+	}
+
+	return map[string]interface{}{
+		"fuzzy": params,
+	}
+}
+
+// Validate checks that the rule has a term, implementing the Validator
+// interface.
+func (r *IntervalsFuzzyRule) Validate() error {
+	var v validation
+	v.check(r.term != "", "/fuzzy/term", "is required")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsAllOfRule represents an intervals rule of type "all_of", which
+// returns intervals spanning a combination of the intervals of other rules.
+type IntervalsAllOfRule struct {
+	intervals []IntervalsRule
+	maxGaps   *int
+	ordered   *bool
+	filter    *IntervalsFilter
+}
+
+// IntervalsAllOf creates a new intervals rule of type "all_of" combining the
+// provided rules.
+func IntervalsAllOf(rules ...IntervalsRule) *IntervalsAllOfRule {
+	return &IntervalsAllOfRule{intervals: rules}
+}
+
+func (r *IntervalsAllOfRule) intervalsRule() {}
+
+// MaxGaps sets the maximum number of positions between the intervals produced
+// by the rules. A value of -1 means there is no restriction.
+func (r *IntervalsAllOfRule) MaxGaps(n int) *IntervalsAllOfRule {
+	r.maxGaps = &n
+	return r
+}
+
+// Ordered sets whether the intervals produced by the rules must appear in
+// their specified order.
+func (r *IntervalsAllOfRule) Ordered(b bool) *IntervalsAllOfRule {
+	r.ordered = &b
+	return r
+}
+
+// Filter sets a filter for the intervals returned by the rule.
+func (r *IntervalsAllOfRule) Filter(f *IntervalsFilter) *IntervalsAllOfRule {
+	r.filter = f
+	return r
+}
+
+// GetIntervals returns the sub-rules of the rule.
+func (r *IntervalsAllOfRule) GetIntervals() []IntervalsRule {
+	return r.intervals
+}
+
+// GetFilter returns the filter of the rule, if any.
+func (r *IntervalsAllOfRule) GetFilter() *IntervalsFilter {
+	return r.filter
+}
+
+// Clone returns a deep copy of the rule.
+func (r *IntervalsAllOfRule) Clone() *IntervalsAllOfRule {
+	c := *r
+	c.intervals = cloneIntervalsRules(r.intervals)
+	c.filter = cloneIntervalsFilter(r.filter)
+	return &c
+}
+
+// Map returns a map representation of the rule, thus implementing the
+// Mappable interface.
+func (r *IntervalsAllOfRule) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"intervals": intervalsRules(r.intervals),
+	}
+	if r.maxGaps != nil {
+		params["max_gaps"] = *r.maxGaps
+	}
+	if r.ordered != nil {
+		params["ordered"] = *r.ordered
+	}
+	if r.filter != nil {
+		params["filter"] = r.filter.Map()
+	}
+
+	return map[string]interface{}{
+		"all_of": params,
+	}
+}
+
+// Validate validates the rule's sub-rules and filter, implementing the
+// Validator interface.
+func (r *IntervalsAllOfRule) Validate() error {
+	var v validation
+	v.intervalsRules("/all_of/intervals", r.intervals)
+	v.optional("/all_of/filter", r.filter)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsAnyOfRule represents an intervals rule of type "any_of", which
+// returns intervals produced by any of its rules.
+type IntervalsAnyOfRule struct {
+	intervals []IntervalsRule
+	filter    *IntervalsFilter
+}
+
+// IntervalsAnyOf creates a new intervals rule of type "any_of" combining the
+// provided rules.
+func IntervalsAnyOf(rules ...IntervalsRule) *IntervalsAnyOfRule {
+	return &IntervalsAnyOfRule{intervals: rules}
+}
+
+func (r *IntervalsAnyOfRule) intervalsRule() {}
+
+// Filter sets a filter for the intervals returned by the rule.
+func (r *IntervalsAnyOfRule) Filter(f *IntervalsFilter) *IntervalsAnyOfRule {
+	r.filter = f
+	return r
+}
+
+// GetIntervals returns the sub-rules of the rule.
+func (r *IntervalsAnyOfRule) GetIntervals() []IntervalsRule {
+	return r.intervals
+}
+
+// GetFilter returns the filter of the rule, if any.
+func (r *IntervalsAnyOfRule) GetFilter() *IntervalsFilter {
+	return r.filter
+}
+
+// Clone returns a deep copy of the rule.
+func (r *IntervalsAnyOfRule) Clone() *IntervalsAnyOfRule {
+	c := *r
+	c.intervals = cloneIntervalsRules(r.intervals)
+	c.filter = cloneIntervalsFilter(r.filter)
+	return &c
+}
+
+// Map returns a map representation of the rule, thus implementing the
+// Mappable interface.
+func (r *IntervalsAnyOfRule) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"intervals": intervalsRules(r.intervals),
+	}
+	if r.filter != nil {
+		params["filter"] = r.filter.Map()
+	}
+
+	return map[string]interface{}{
+		"any_of": params,
+	}
+}
+
+// Validate validates the rule's sub-rules and filter, implementing the
+// Validator interface.
+func (r *IntervalsAnyOfRule) Validate() error {
+	var v validation
+	v.intervalsRules("/any_of/intervals", r.intervals)
+	v.optional("/any_of/filter", r.filter)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// IntervalsFilter represents a filter on the intervals returned by a rule. A
+// filter either relates the intervals to the intervals of another rule, or
+// filters them using a script.
+type IntervalsFilter struct {
+	kind   string
+	rule   IntervalsRule
+	script *Script
+}
+
+func newIntervalsFilter(kind string, rule IntervalsRule) *IntervalsFilter {
+	return &IntervalsFilter{
+		kind: kind,
+		rule: rule,
+	}
+}
+
+// IntervalsAfter creates a filter keeping intervals that occur after an
+// interval of the provided rule.
+func IntervalsAfter(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("after", rule)
+}
+
+// IntervalsBefore creates a filter keeping intervals that occur before an
+// interval of the provided rule.
+func IntervalsBefore(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("before", rule)
+}
+
+// IntervalsContainedBy creates a filter keeping intervals that are contained
+// by an interval of the provided rule.
+func IntervalsContainedBy(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("contained_by", rule)
+}
+
+// IntervalsContaining creates a filter keeping intervals that contain an
+// interval of the provided rule.
+func IntervalsContaining(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("containing", rule)
+}
+
+// IntervalsNotContainedBy creates a filter keeping intervals that are not
+// contained by an interval of the provided rule.
+func IntervalsNotContainedBy(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("not_contained_by", rule)
+}
+
+// IntervalsNotContaining creates a filter keeping intervals that do not
+// contain an interval of the provided rule.
+func IntervalsNotContaining(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("not_containing", rule)
+}
+
+// IntervalsNotOverlapping creates a filter keeping intervals that do not
+// overlap with an interval of the provided rule.
+func IntervalsNotOverlapping(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("not_overlapping", rule)
+}
+
+// IntervalsOverlapping creates a filter keeping intervals that overlap with an
+// interval of the provided rule.
+func IntervalsOverlapping(rule IntervalsRule) *IntervalsFilter {
+	return newIntervalsFilter("overlapping", rule)
+}
+
+// IntervalsScript creates a filter keeping intervals for which the provided
+// script returns true. The script can access the interval via the "interval"
+// variable.
+func IntervalsScript(script *Script) *IntervalsFilter {
+	return &IntervalsFilter{
+		kind:   "script",
+		script: script,
+	}
+}
+
+// GetRule returns the rule of the filter, or nil for script filters.
+func (f *IntervalsFilter) GetRule() IntervalsRule {
+	return f.rule
+}
+
+// Clone returns a deep copy of the filter.
+func (f *IntervalsFilter) Clone() *IntervalsFilter {
+	c := *f
+	c.rule = cloneIntervalsRule(f.rule)
+	c.script = cloneScript(f.script)
+	return &c
+}
+
+// Map returns a map representation of the filter, thus implementing the
+// Mappable interface.
+func (f *IntervalsFilter) Map() map[string]interface{} {
+	if f.script != nil {
+		return map[string]interface{}{
+			f.kind: f.script.Map(),
+		}
+	}
+
+	return map[string]interface{}{
+		f.kind: f.rule.Map(),
+	}
+}
+
+// Validate validates the filter's rule or script, implementing the Validator
+// interface.
+func (f *IntervalsFilter) Validate() error {
+	var v validation
+	if f.kind == "script" {
+		v.child("/script", f.script)
+	} else {
+		v.child(jsonPath(f.kind), f.rule)
+	}
+	return v.err()
+}
@@ -0,0 +1,113 @@
+package esquery
+
+import "testing"
+
+func TestIntervals(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"intervals: all_of with match and any_of",
+			Intervals("my_text", IntervalsAllOf(
+				IntervalsMatch("my favorite food").MaxGaps(0).Ordered(true),
+				IntervalsAnyOf(
+					IntervalsMatch("hot water"),
+					IntervalsMatch("cold porridge"),
+				),
+			).Ordered(true)),
+			map[string]interface{}{
+				"intervals": map[string]interface{}{
+					"my_text": map[string]interface{}{
+						"all_of": map[string]interface{}{
+							"ordered": true,
+							"intervals": []map[string]interface{}{
+								{
+									"match": map[string]interface{}{
+										"query":    "my favorite food",
+										"max_gaps": 0,
+										"ordered":  true,
+									},
+								},
+								{
+									"any_of": map[string]interface{}{
+										"intervals": []map[string]interface{}{
+											{"match": map[string]interface{}{"query": "hot water"}},
+											{"match": map[string]interface{}{"query": "cold porridge"}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"intervals: match with filter",
+			Intervals("my_text", IntervalsMatch("hot porridge").
+				MaxGaps(10).
+				Analyzer("standard").
+				Filter(IntervalsNotContaining(IntervalsMatch("salty")))),
+			map[string]interface{}{
+				"intervals": map[string]interface{}{
+					"my_text": map[string]interface{}{
+						"match": map[string]interface{}{
+							"query":    "hot porridge",
+							"max_gaps": 10,
+							"analyzer": "standard",
+							"filter": map[string]interface{}{
+								"not_containing": map[string]interface{}{
+									"match": map[string]interface{}{"query": "salty"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"intervals: prefix, wildcard and fuzzy",
+			Intervals("my_text", IntervalsAnyOf(
+				IntervalsPrefix("out").Analyzer("standard"),
+				IntervalsWildcard("*ing").UseField("my_text.raw"),
+				IntervalsFuzzy("quikc").Fuzziness("AUTO").PrefixLength(1).Transpositions(true),
+			)),
+			map[string]interface{}{
+				"intervals": map[string]interface{}{
+					"my_text": map[string]interface{}{
+						"any_of": map[string]interface{}{
+							"intervals": []map[string]interface{}{
+								{"prefix": map[string]interface{}{"prefix": "out", "analyzer": "standard"}},
+								{"wildcard": map[string]interface{}{"pattern": "*ing", "use_field": "my_text.raw"}},
+								{"fuzzy": map[string]interface{}{
+									"term":           "quikc",
+									"fuzziness":      "AUTO",
+									"prefix_length":  1,
+									"transpositions": true,
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"intervals: script filter",
+			Intervals("my_text", IntervalsMatch("hot porridge").Filter(IntervalsScript(
+				InlineScript("interval.start > 10 && interval.end < 20 && interval.gaps == 0"),
+			))),
+			map[string]interface{}{
+				"intervals": map[string]interface{}{
+					"my_text": map[string]interface{}{
+						"match": map[string]interface{}{
+							"query": "hot porridge",
+							"filter": map[string]interface{}{
+								"script": map[string]interface{}{
+									"source": "interval.start > 10 && interval.end < 20 && interval.gaps == 0",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
@@ -1,9 +1,5 @@
 package esquery
 
-import (
-	"github.com/fatih/structs"
-)
-
 type matchType uint8
 
 const (
@@ -35,26 +31,48 @@ type MatchQuery struct {
 	params matchParams
 }
 
+// GetField returns the name of the field the query applies to.
+func (q *MatchQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *MatchQuery) Clone() *MatchQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *MatchQuery) Map() map[string]interface{} {
-	var mType string
+	return map[string]interface{}{
+		q.typeName(): map[string]interface{}{
+			q.field: q.params.toMap(),
+		},
+	}
+}
+
+// typeName returns the name of the query's type, as known to ElasticSearch.
+func (q *MatchQuery) typeName() string {
 	switch q.mType {
-	case TypeMatch:
-		mType = "match"
 	case TypeMatchBoolPrefix:
-		mType = "match_bool_prefix"
+		return "match_bool_prefix"
 	case TypeMatchPhrase:
-		mType = "match_phrase"
+		return "match_phrase"
 	case TypeMatchPhrasePrefix:
-		mType = "match_phrase_prefix"
+		return "match_phrase_prefix"
+	default:
+		return "match"
 	}
+}
 
-	return map[string]interface{}{
-		mType: map[string]interface{}{
-			q.field: structs.Map(q.params),
-		},
-	}
+// Validate checks that the query has a field name and a query value,
+// implementing the Validator interface.
+func (q *MatchQuery) Validate() error {
+	var v validation
+	v.field(jsonPath(q.typeName()), q.field)
+	v.check(q.params.Qry != nil, jsonPath(q.typeName(), q.field, "query"), "is required")
+	return v.err()
 }
 
 type matchParams struct {
@@ -73,6 +91,51 @@ type matchParams struct {
 	Slp          uint16        `structs:"slop,omitempty"` // only relevant for match_phrase query
 }
 
+// toMap returns a map representation of the parameters, as generated by the
+// structs package from their tags, without using reflection.
+func (params matchParams) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"query": structValue(params.Qry),
+	}
+	if params.Anl != "" {
+		m["analyzer"] = params.Anl
+	}
+	if params.AutoGenerate != nil {
+		m["auto_generate_synonyms_phrase_query"] = params.AutoGenerate
+	}
+	if params.Fuzz != "" {
+		m["fuzziness"] = params.Fuzz
+	}
+	if params.MaxExp != 0 {
+		m["max_expansions"] = params.MaxExp
+	}
+	if params.PrefLen != 0 {
+		m["prefix_length"] = params.PrefLen
+	}
+	if params.Trans != nil {
+		m["transpositions"] = params.Trans
+	}
+	if params.FuzzyRw != "" {
+		m["fuzzy_rewrite"] = params.FuzzyRw
+	}
+	if params.Lent {
+		m["lenient"] = params.Lent
+	}
+	if params.Op != 0 {
+		m["operator"] = params.Op.String()
+	}
+	if params.MinMatch != "" {
+		m["minimum_should_match"] = params.MinMatch
+	}
+	if params.ZeroTerms != 0 {
+		m["zero_terms_query"] = params.ZeroTerms.String()
+	}
+	if params.Slp != 0 {
+		m["slop"] = params.Slp
+	}
+	return m
+}
+
 // Match creates a new query of type "match" with the provided field name.
 // A comparison value can optionally be provided to quickly create a simple
 // query such as { "match": { "message": "this is a test" } }
@@ -14,6 +14,12 @@ type matchAllParams struct {
 	Boost float32 `structs:"boost,omitempty"`
 }
 
+// Clone returns a deep copy of the query.
+func (q *MatchAllQuery) Clone() *MatchAllQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *MatchAllQuery) Map() map[string]interface{} {
@@ -30,6 +36,23 @@ func (q *MatchAllQuery) Map() map[string]interface{} {
 	}
 }
 
+// writeJSON writes the query in the same way as Map, see EncodeJSON.
+func (q *MatchAllQuery) writeJSON(e *encoder) {
+	e.openObject()
+	if q.all {
+		e.key("match_all")
+	} else {
+		e.key("match_none")
+	}
+	e.openObject()
+	if q.params.Boost != 0 {
+		e.key("boost")
+		e.float(float64(q.params.Boost), 32)
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
 // MatchAll creates a new query of type "match_all".
 func MatchAll() *MatchAllQuery {
 	return &MatchAllQuery{all: true}
@@ -47,3 +70,9 @@ func (q *MatchAllQuery) Boost(b float32) *MatchAllQuery {
 func MatchNone() *MatchAllQuery {
 	return &MatchAllQuery{all: false}
 }
+
+// Validate implements the Validator interface. match_all and match_none
+// queries are always valid.
+func (q *MatchAllQuery) Validate() error {
+	return nil
+}
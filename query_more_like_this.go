@@ -0,0 +1,287 @@
+package esquery
+
+// MoreLikeThisQuery represents a query of type "more_like_this", as described
+// in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-mlt-query.html
+type MoreLikeThisQuery struct {
+	fields             []string
+	like               []LikeItem
+	unlike             []LikeItem
+	minTermFreq        *uint64
+	maxQueryTerms      *uint64
+	minDocFreq         *uint64
+	maxDocFreq         *uint64
+	minWordLength      *uint64
+	maxWordLength      *uint64
+	stopWords          []string
+	analyzer           string
+	minimumShouldMatch string
+	boostTerms         *float32
+	include            *bool
+	boost              float32
+}
+
+// MoreLikeThis creates a new query of type "more_like_this", finding
+// documents similar to the items provided via Like in the provided fields. If
+// no fields are provided, ElasticSearch uses the index's default fields.
+func MoreLikeThis(fields ...string) *MoreLikeThisQuery {
+	return &MoreLikeThisQuery{
+		fields: fields,
+	}
+}
+
+// Like adds one or more items that returned documents should be similar to.
+// Like can be called multiple times, items will be appended to existing ones.
+func (q *MoreLikeThisQuery) Like(items ...LikeItem) *MoreLikeThisQuery {
+	q.like = append(q.like, items...)
+	return q
+}
+
+// Unlike adds one or more items whose terms should not be selected for the
+// query. Unlike can be called multiple times, items will be appended to
+// existing ones.
+func (q *MoreLikeThisQuery) Unlike(items ...LikeItem) *MoreLikeThisQuery {
+	q.unlike = append(q.unlike, items...)
+	return q
+}
+
+// MinTermFreq sets the minimum frequency below which terms of the input
+// documents are ignored.
+func (q *MoreLikeThisQuery) MinTermFreq(n uint64) *MoreLikeThisQuery {
+	q.minTermFreq = &n
+	return q
+}
+
+// MaxQueryTerms sets the maximum number of terms selected from the input
+// documents.
+func (q *MoreLikeThisQuery) MaxQueryTerms(n uint64) *MoreLikeThisQuery {
+	q.maxQueryTerms = &n
+	return q
+}
+
+// MinDocFreq sets the minimum number of documents a term must appear in to
+// be selected.
+func (q *MoreLikeThisQuery) MinDocFreq(n uint64) *MoreLikeThisQuery {
+	q.minDocFreq = &n
+	return q
+}
+
+// MaxDocFreq sets the maximum number of documents a term may appear in to be
+// selected.
+func (q *MoreLikeThisQuery) MaxDocFreq(n uint64) *MoreLikeThisQuery {
+	q.maxDocFreq = &n
+	return q
+}
+
+// MinWordLength sets the minimum length below which terms are ignored.
+func (q *MoreLikeThisQuery) MinWordLength(n uint64) *MoreLikeThisQuery {
+	q.minWordLength = &n
+	return q
+}
+
+// MaxWordLength sets the maximum length above which terms are ignored.
+func (q *MoreLikeThisQuery) MaxWordLength(n uint64) *MoreLikeThisQuery {
+	q.maxWordLength = &n
+	return q
+}
+
+// StopWords sets a list of words that are ignored.
+func (q *MoreLikeThisQuery) StopWords(words ...string) *MoreLikeThisQuery {
+	q.stopWords = append(q.stopWords, words...)
+	return q
+}
+
+// Analyzer sets the analyzer used to analyze free text items.
+func (q *MoreLikeThisQuery) Analyzer(a string) *MoreLikeThisQuery {
+	q.analyzer = a
+	return q
+}
+
+// MinimumShouldMatch sets the number or percentage of selected terms that
+// returned documents must match.
+func (q *MoreLikeThisQuery) MinimumShouldMatch(s string) *MoreLikeThisQuery {
+	q.minimumShouldMatch = s
+	return q
+}
+
+// BoostTerms sets the factor by which selected terms are boosted according
+// to their tf-idf score.
+func (q *MoreLikeThisQuery) BoostTerms(b float32) *MoreLikeThisQuery {
+	q.boostTerms = &b
+	return q
+}
+
+// Include sets whether the input documents should also be returned.
+func (q *MoreLikeThisQuery) Include(b bool) *MoreLikeThisQuery {
+	q.include = &b
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *MoreLikeThisQuery) Boost(b float32) *MoreLikeThisQuery {
+	q.boost = b
+	return q
+}
+
+// GetFields returns the names of the fields the query applies to.
+func (q *MoreLikeThisQuery) GetFields() []string {
+	return append([]string(nil), q.fields...)
+}
+
+// Clone returns a deep copy of the query.
+func (q *MoreLikeThisQuery) Clone() *MoreLikeThisQuery {
+	c := *q
+	c.fields = cloneStrings(q.fields)
+	c.like = cloneLikeItems(q.like)
+	c.unlike = cloneLikeItems(q.unlike)
+	c.stopWords = cloneStrings(q.stopWords)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *MoreLikeThisQuery) Map() map[string]interface{} {
+	params := make(map[string]interface{})
+	if len(q.fields) > 0 {
+		params["fields"] = q.fields
+	}
+	if len(q.like) > 0 {
+		params["like"] = likeItems(q.like)
+	}
+	if len(q.unlike) > 0 {
+		params["unlike"] = likeItems(q.unlike)
+	}
+	if q.minTermFreq != nil {
+		params["min_term_freq"] = *q.minTermFreq
+	}
+	if q.maxQueryTerms != nil {
+		params["max_query_terms"] = *q.maxQueryTerms
+	}
+	if q.minDocFreq != nil {
+		params["min_doc_freq"] = *q.minDocFreq
+	}
+	if q.maxDocFreq != nil {
+		params["max_doc_freq"] = *q.maxDocFreq
+	}
+	if q.minWordLength != nil {
+		params["min_word_length"] = *q.minWordLength
+	}
+	if q.maxWordLength != nil {
+		params["max_word_length"] = *q.maxWordLength
+	}
+	if len(q.stopWords) > 0 {
+		params["stop_words"] = q.stopWords
+	}
+	if q.analyzer != "" {
+		params["analyzer"] = q.analyzer
+	}
+	if q.minimumShouldMatch != "" {
+		params["minimum_should_match"] = q.minimumShouldMatch
+	}
+	if q.boostTerms != nil {
+		params["boost_terms"] = *q.boostTerms
+	}
+	if q.include != nil {
+		params["include"] = *q.include
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"more_like_this": params,
+	}
+}
+
+// Validate checks that the query has at least one like item, implementing the
+// Validator interface.
+func (q *MoreLikeThisQuery) Validate() error {
+	var v validation
+	v.check(len(q.like) > 0, "/more_like_this/like", "at least one item is required")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// LikeItem is the interface implemented by the items accepted by the "like"
+// and "unlike" parameters of a more_like_this query: free text created via
+// LikeText, and documents created via LikeDoc or LikeArtificialDoc.
+type LikeItem interface {
+	likeValue() interface{}
+}
+
+func likeItems(items []LikeItem) []interface{} {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = item.likeValue()
+	}
+	return values
+}
+
+type likeText string
+
+func (t likeText) likeValue() interface{} {
+	return string(t)
+}
+
+// LikeText creates a like item from free text.
+func LikeText(text string) LikeItem {
+	return likeText(text)
+}
+
+// LikeDocument is a like item referencing a document, either stored in an
+// index or provided artificially.
+type LikeDocument struct {
+	index   string
+	id      string
+	doc     interface{}
+	routing string
+}
+
+// LikeDoc creates a like item referencing the document with the provided ID
+// in the provided index.
+func LikeDoc(index, id string) *LikeDocument {
+	return &LikeDocument{
+		index: index,
+		id:    id,
+	}
+}
+
+// LikeArtificialDoc creates a like item from a document that is not stored
+// in the index. The document is analyzed using the mapping of the provided
+// index.
+func LikeArtificialDoc(index string, doc interface{}) *LikeDocument {
+	return &LikeDocument{
+		index: index,
+		doc:   doc,
+	}
+}
+
+// Routing sets the routing value used to fetch the referenced document.
+func (d *LikeDocument) Routing(r string) *LikeDocument {
+	d.routing = r
+	return d
+}
+
+// Clone returns a deep copy of the item.
+func (d *LikeDocument) Clone() *LikeDocument {
+	c := *d
+	return &c
+}
+
+func (d *LikeDocument) likeValue() interface{} {
+	m := make(map[string]interface{})
+	if d.index != "" {
+		m["_index"] = d.index
+	}
+	if d.id != "" {
+		m["_id"] = d.id
+	}
+	if d.doc != nil {
+		m["doc"] = d.doc
+	}
+	if d.routing != "" {
+		m["routing"] = d.routing
+	}
+	return m
+}
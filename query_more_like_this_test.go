@@ -0,0 +1,73 @@
+package esquery
+
+import "testing"
+
+func TestMoreLikeThis(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"more_like_this with free text",
+			MoreLikeThis("title", "description").
+				Like(LikeText("remote code execution")).
+				MinTermFreq(1).
+				MaxQueryTerms(12),
+			map[string]interface{}{
+				"more_like_this": map[string]interface{}{
+					"fields":          []string{"title", "description"},
+					"like":            []interface{}{"remote code execution"},
+					"min_term_freq":   1,
+					"max_query_terms": 12,
+				},
+			},
+		},
+		{
+			"more_like_this with documents and all params",
+			MoreLikeThis().
+				Like(
+					LikeDoc("findings", "1"),
+					LikeDoc("findings", "2").Routing("tenant-1"),
+					LikeArtificialDoc("findings", map[string]interface{}{
+						"title": "heap overflow",
+					}),
+				).
+				Unlike(LikeText("false positive"), LikeDoc("findings", "3")).
+				MinTermFreq(0).
+				MinDocFreq(2).
+				MaxDocFreq(1000).
+				MinWordLength(3).
+				MaxWordLength(20).
+				StopWords("the", "a").
+				Analyzer("english").
+				MinimumShouldMatch("30%").
+				BoostTerms(1.5).
+				Include(true).
+				Boost(2),
+			map[string]interface{}{
+				"more_like_this": map[string]interface{}{
+					"like": []interface{}{
+						map[string]interface{}{"_index": "findings", "_id": "1"},
+						map[string]interface{}{"_index": "findings", "_id": "2", "routing": "tenant-1"},
+						map[string]interface{}{
+							"_index": "findings",
+							"doc":    map[string]interface{}{"title": "heap overflow"},
+						},
+					},
+					"unlike": []interface{}{
+						"false positive",
+						map[string]interface{}{"_index": "findings", "_id": "3"},
+					},
+					"min_term_freq":        0,
+					"min_doc_freq":         2,
+					"max_doc_freq":         1000,
+					"min_word_length":      3,
+					"max_word_length":      20,
+					"stop_words":           []string{"the", "a"},
+					"analyzer":             "english",
+					"minimum_should_match": "30%",
+					"boost_terms":          1.5,
+					"include":              true,
+					"boost":                2,
+				},
+			},
+		},
+	})
+}
@@ -1,24 +1,41 @@
 package esquery
 
-import (
-	"github.com/fatih/structs"
-)
-
 type MultiMatchQuery struct {
 	params multiMatchParams
 }
 
+// GetFields returns the names of the fields the query applies to, without
+// their boosts.
+func (q *MultiMatchQuery) GetFields() []string {
+	return fieldNames(q.params.Fields)
+}
+
+// Clone returns a deep copy of the query.
+func (q *MultiMatchQuery) Clone() *MultiMatchQuery {
+	c := *q
+	c.params.Fields = cloneStrings(q.params.Fields)
+	return &c
+}
+
 // Map returns a map representation of the query; implementing the
 // Mappable interface.
 func (q *MultiMatchQuery) Map() map[string]interface{} {
 	return map[string]interface{}{
-		"multi_match": structs.Map(q.params),
+		"multi_match": q.params.toMap(),
 	}
 }
 
+// Validate checks that the query has a query value, implementing the
+// Validator interface.
+func (q *MultiMatchQuery) Validate() error {
+	var v validation
+	v.check(q.params.Qry != nil, "/multi_match/query", "is required")
+	return v.err()
+}
+
 type multiMatchParams struct {
 	Qry          interface{}    `structs:"query"`
-	Fields       []string       `structs:"fields"`
+	Fields       []string       `structs:"fields,omitempty"`
 	Type         MultiMatchType `structs:"type,string,omitempty"`
 	TieBrk       float32        `structs:"tie_breaker,omitempty"`
 	Boost        float32        `structs:"boost,omitempty"`
@@ -36,6 +53,63 @@ type multiMatchParams struct {
 	Slp          uint16         `structs:"slop,omitempty"`
 }
 
+// toMap returns a map representation of the parameters, as generated by the
+// structs package from their tags, without using reflection.
+func (params multiMatchParams) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"query": structValue(params.Qry),
+	}
+	if params.Fields != nil {
+		m["fields"] = params.Fields
+	}
+	if params.Type != 0 {
+		m["type"] = params.Type.String()
+	}
+	if params.TieBrk != 0 {
+		m["tie_breaker"] = params.TieBrk
+	}
+	if params.Boost != 0 {
+		m["boost"] = params.Boost
+	}
+	if params.Anl != "" {
+		m["analyzer"] = params.Anl
+	}
+	if params.AutoGenerate != nil {
+		m["auto_generate_synonyms_phrase_query"] = params.AutoGenerate
+	}
+	if params.Fuzz != "" {
+		m["fuzziness"] = params.Fuzz
+	}
+	if params.MaxExp != 0 {
+		m["max_expansions"] = params.MaxExp
+	}
+	if params.PrefLen != 0 {
+		m["prefix_length"] = params.PrefLen
+	}
+	if params.Trans != nil {
+		m["transpositions"] = params.Trans
+	}
+	if params.FuzzyRw != "" {
+		m["fuzzy_rewrite"] = params.FuzzyRw
+	}
+	if params.Lent != nil {
+		m["lenient"] = params.Lent
+	}
+	if params.Op != 0 {
+		m["operator"] = params.Op.String()
+	}
+	if params.MinMatch != "" {
+		m["minimum_should_match"] = params.MinMatch
+	}
+	if params.ZeroTerms != 0 {
+		m["zero_terms_query"] = params.ZeroTerms.String()
+	}
+	if params.Slp != 0 {
+		m["slop"] = params.Slp
+	}
+	return m
+}
+
 // MultiMatch creates a new query of type "multi_match"
 func MultiMatch(simpleQuery ...interface{}) *MultiMatchQuery {
 	return newMultiMatch(simpleQuery...)
@@ -68,12 +142,19 @@ func (q *MultiMatchQuery) Analyzer(a string) *MultiMatchQuery {
 	return q
 }
 
-// Fields sets the fields used in the query
+// Fields sets the fields used in the query. If no fields are set, the
+// "fields" parameter is omitted, and ElasticSearch searches the fields of the
+// index.query.default_field setting (it rejects a null value).
 func (q *MultiMatchQuery) Fields(a ...string) *MultiMatchQuery {
 	q.params.Fields = append(q.params.Fields, a...)
 	return q
 }
 
+// BoostedFields adds fields with their boosts to the fields used in the query.
+func (q *MultiMatchQuery) BoostedFields(fields ...BoostedField) *MultiMatchQuery {
+	return q.Fields(boostedFields(fields)...)
+}
+
 // AutoGenerateSynonymsPhraseQuery sets the "auto_generate_synonyms_phrase_query"
 // boolean.
 func (q *MultiMatchQuery) AutoGenerateSynonymsPhraseQuery(b bool) *MultiMatchQuery {
@@ -1,6 +1,7 @@
 package esquery
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -16,6 +17,28 @@ func TestMultiMatch(t *testing.T) {
 				},
 			},
 		},
+		{
+			"multi_match without fields",
+			MultiMatch("kimchy").Lenient(true),
+			map[string]interface{}{
+				"multi_match": map[string]interface{}{
+					"lenient": true,
+					"query":   "kimchy",
+				},
+			},
+		},
+		{
+			"multi_match with boosted fields",
+			MultiMatch("quick brown fox").
+				Fields("body").
+				BoostedFields(FieldBoost("title", 3), FieldBoost("summary", 1.5), FieldBoost("tags", 0)),
+			map[string]interface{}{
+				"multi_match": map[string]interface{}{
+					"fields": []string{"body", "title^3", "summary^1.5", "tags"},
+					"query":  "quick brown fox",
+				},
+			},
+		},
 		{
 			"multi_match all params",
 			MultiMatch("original").
@@ -60,3 +83,18 @@ func TestMultiMatch(t *testing.T) {
 		},
 	})
 }
+
+func TestMultiMatchGetFields(t *testing.T) {
+	q := MultiMatch("openssl").Fields("title^2", "description", "name.*^1.5")
+
+	fields := q.GetFields()
+	exp := []string{"title", "description", "name.*"}
+	if !reflect.DeepEqual(fields, exp) {
+		t.Errorf("expected %v, got %v", exp, fields)
+	}
+
+	fields[0] = "body"
+	if got := q.Map()["multi_match"].(map[string]interface{})["fields"].([]string)[0]; got != "title^2" {
+		t.Errorf("expected GetFields to return a copy, query fields changed to %q", got)
+	}
+}
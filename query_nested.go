@@ -0,0 +1,122 @@
+package esquery
+
+// NestedQuery represents a joining query of type "nested", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-nested-query.html
+type NestedQuery struct {
+	path           string
+	query          Mappable
+	scoreMode      NestedScoreMode
+	ignoreUnmapped *bool
+}
+
+// Nested creates a new query of type "nested", searching the nested objects
+// under the provided path with the provided query.
+func Nested(path string, query Mappable) *NestedQuery {
+	return &NestedQuery{
+		path:  path,
+		query: query,
+	}
+}
+
+// ScoreMode sets how the scores of matching child objects affect the score of
+// the root document.
+func (q *NestedQuery) ScoreMode(mode NestedScoreMode) *NestedQuery {
+	q.scoreMode = mode
+	return q
+}
+
+// IgnoreUnmapped sets whether to ignore an unmapped path rather than return
+// an error.
+func (q *NestedQuery) IgnoreUnmapped(b bool) *NestedQuery {
+	q.ignoreUnmapped = &b
+	return q
+}
+
+// GetPath returns the path of the nested field.
+func (q *NestedQuery) GetPath() string {
+	return q.path
+}
+
+// GetQuery returns the query run on the nested field's children.
+func (q *NestedQuery) GetQuery() Mappable {
+	return q.query
+}
+
+// Clone returns a deep copy of the query.
+func (q *NestedQuery) Clone() *NestedQuery {
+	c := *q
+	c.query = cloneMappable(q.query)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *NestedQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"path": q.path,
+	}
+	if q.query != nil {
+		params["query"] = q.query.Map()
+	}
+	if q.scoreMode != 0 {
+		params["score_mode"] = q.scoreMode.String()
+	}
+	if q.ignoreUnmapped != nil {
+		params["ignore_unmapped"] = *q.ignoreUnmapped
+	}
+
+	return map[string]interface{}{
+		"nested": params,
+	}
+}
+
+// Validate validates the query and its inner query, implementing the
+// Validator interface.
+func (q *NestedQuery) Validate() error {
+	var v validation
+	v.check(q.path != "", "/nested/path", "is required")
+	v.child("/nested/query", q.query)
+	return v.err()
+}
+
+// NestedScoreMode is an enumeration type representing supported values for a
+// nested query's "score_mode" parameter.
+type NestedScoreMode uint8
+
+const (
+	_ NestedScoreMode = iota
+
+	// NestedScoreAvg is the "avg" score mode
+	NestedScoreAvg
+
+	// NestedScoreMax is the "max" score mode
+	NestedScoreMax
+
+	// NestedScoreMin is the "min" score mode
+	NestedScoreMin
+
+	// NestedScoreNone is the "none" score mode
+	NestedScoreNone
+
+	// NestedScoreSum is the "sum" score mode
+	NestedScoreSum
+)
+
+// String returns a string representation of the score_mode parameter, as
+// known to ElasticSearch.
+func (a NestedScoreMode) String() string {
+	switch a {
+	case NestedScoreAvg:
+		return "avg"
+	case NestedScoreMax:
+		return "max"
+	case NestedScoreMin:
+		return "min"
+	case NestedScoreNone:
+		return "none"
+	case NestedScoreSum:
+		return "sum"
+	default:
+		return ""
+	}
+}
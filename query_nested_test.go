@@ -0,0 +1,44 @@
+package esquery
+
+import "testing"
+
+func TestNested(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"nested query with default options",
+			Nested("comments", Match("comments.author", "kimchy")),
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"path": "comments",
+					"query": map[string]interface{}{
+						"match": map[string]interface{}{
+							"comments.author": map[string]interface{}{
+								"query": "kimchy",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"nested query with all options",
+			Nested("comments", Range("comments.likes").Gte(10)).
+				ScoreMode(NestedScoreMax).
+				IgnoreUnmapped(true),
+			map[string]interface{}{
+				"nested": map[string]interface{}{
+					"path": "comments",
+					"query": map[string]interface{}{
+						"range": map[string]interface{}{
+							"comments.likes": map[string]interface{}{
+								"gte": 10,
+							},
+						},
+					},
+					"score_mode":      "max",
+					"ignore_unmapped": true,
+				},
+			},
+		},
+	})
+}
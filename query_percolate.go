@@ -0,0 +1,142 @@
+package esquery
+
+// PercolateQuery represents a query of type "percolate", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-percolate-query.html
+// It matches the queries stored in a percolator field that match the provided
+// documents.
+type PercolateQuery struct {
+	field      string
+	name       string
+	document   interface{}
+	documents  []interface{}
+	index      string
+	id         string
+	routing    string
+	preference string
+	version    *int64
+}
+
+// Percolate creates a new query of type "percolate", matching the queries
+// stored in the provided field of type "percolator".
+func Percolate(field string) *PercolateQuery {
+	return &PercolateQuery{
+		field: field,
+	}
+}
+
+// Name sets the name of the query, which is used as the suffix of the
+// "_percolator_document_slot" field of hits when a search request contains
+// multiple percolate queries.
+func (q *PercolateQuery) Name(name string) *PercolateQuery {
+	q.name = name
+	return q
+}
+
+// Document sets the document to percolate.
+func (q *PercolateQuery) Document(doc interface{}) *PercolateQuery {
+	q.document = doc
+	return q
+}
+
+// Documents adds one or more documents to percolate. Documents can be called
+// multiple times, documents will be appended to existing ones. The matching
+// documents of each hit are reported in its "_percolator_document_slot"
+// field, see DecodePercolateHits.
+func (q *PercolateQuery) Documents(docs ...interface{}) *PercolateQuery {
+	q.documents = append(q.documents, docs...)
+	return q
+}
+
+// IndexedDocument sets the index and ID of an already indexed document to
+// percolate, instead of providing the document itself.
+func (q *PercolateQuery) IndexedDocument(index, id string) *PercolateQuery {
+	q.index = index
+	q.id = id
+	return q
+}
+
+// Routing sets the routing value used to fetch the indexed document.
+func (q *PercolateQuery) Routing(r string) *PercolateQuery {
+	q.routing = r
+	return q
+}
+
+// Preference sets the preference used to fetch the indexed document.
+func (q *PercolateQuery) Preference(p string) *PercolateQuery {
+	q.preference = p
+	return q
+}
+
+// Version sets the expected version of the indexed document.
+func (q *PercolateQuery) Version(v int64) *PercolateQuery {
+	q.version = &v
+	return q
+}
+
+// GetField returns the name of the percolator field the query applies to.
+func (q *PercolateQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *PercolateQuery) Clone() *PercolateQuery {
+	c := *q
+	c.documents = cloneValues(q.documents)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *PercolateQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"field": q.field,
+	}
+	if q.name != "" {
+		params["name"] = q.name
+	}
+	if q.document != nil {
+		params["document"] = q.document
+	}
+	if len(q.documents) > 0 {
+		params["documents"] = q.documents
+	}
+	if q.index != "" {
+		params["index"] = q.index
+	}
+	if q.id != "" {
+		params["id"] = q.id
+	}
+	if q.routing != "" {
+		params["routing"] = q.routing
+	}
+	if q.preference != "" {
+		params["preference"] = q.preference
+	}
+	if q.version != nil {
+		params["version"] = *q.version
+	}
+
+	return map[string]interface{}{
+		"percolate": params,
+	}
+}
+
+// Validate checks that the query has a field name and exactly one source of
+// documents to percolate, implementing the Validator interface.
+func (q *PercolateQuery) Validate() error {
+	var v validation
+	v.field("/percolate/field", q.field)
+
+	var sources int
+	for _, set := range []bool{q.document != nil, len(q.documents) > 0, q.id != ""} {
+		if set {
+			sources++
+		}
+	}
+	v.check(
+		sources == 1,
+		"/percolate", "exactly one of document, documents or an indexed document is required",
+	)
+	v.check(q.id == "" || q.index != "", "/percolate/index", "is required for an indexed document")
+	return v.err()
+}
@@ -0,0 +1,59 @@
+package esquery
+
+import "testing"
+
+func TestPercolate(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"percolate a single document",
+			Percolate("query").Document(map[string]interface{}{
+				"message": "a new bonsai tree in the office",
+			}),
+			map[string]interface{}{
+				"percolate": map[string]interface{}{
+					"field": "query",
+					"document": map[string]interface{}{
+						"message": "a new bonsai tree in the office",
+					},
+				},
+			},
+		},
+		{
+			"percolate multiple named documents",
+			Percolate("query").
+				Name("incoming").
+				Documents(
+					map[string]interface{}{"message": "bonsai tree"},
+					map[string]interface{}{"message": "new tree"},
+				),
+			map[string]interface{}{
+				"percolate": map[string]interface{}{
+					"field": "query",
+					"name":  "incoming",
+					"documents": []interface{}{
+						map[string]interface{}{"message": "bonsai tree"},
+						map[string]interface{}{"message": "new tree"},
+					},
+				},
+			},
+		},
+		{
+			"percolate an indexed document",
+			Percolate("query").
+				IndexedDocument("findings", "2").
+				Routing("tenant-1").
+				Preference("_local").
+				Version(1),
+			map[string]interface{}{
+				"percolate": map[string]interface{}{
+					"field":      "query",
+					"index":      "findings",
+					"id":         "2",
+					"routing":    "tenant-1",
+					"preference": "_local",
+					"version":    1,
+				},
+			},
+		},
+	})
+}
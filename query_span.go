@@ -0,0 +1,600 @@
+package esquery
+
+// SpanQuery is the interface implemented by span queries, as described in
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/span-queries.html
+// Span queries can only be nested inside other span queries, which is
+// enforced at compile time by requiring SpanQuery values as their clauses.
+type SpanQuery interface {
+	Mappable
+	spanQuery()
+}
+
+// MultiTermQuery is the interface implemented by the term-level queries that
+// can be wrapped by a "span_multi" query: PrefixQuery, RegexpQuery (including
+// wildcard queries), FuzzyQuery and RangeQuery.
+type MultiTermQuery interface {
+	Mappable
+	multiTermQuery()
+}
+
+func (q *PrefixQuery) multiTermQuery() {}
+func (q *RegexpQuery) multiTermQuery() {}
+func (q *FuzzyQuery) multiTermQuery()  {}
+func (a *RangeQuery) multiTermQuery()  {}
+
+func spanClauses(clauses []SpanQuery) []map[string]interface{} {
+	maps := make([]map[string]interface{}, len(clauses))
+	for i, c := range clauses {
+		maps[i] = c.Map()
+	}
+	return maps
+}
+
+// spanClauses validates a list of span clauses, recording their errors under
+// the provided path followed by their index.
+func (v *validation) spanClauses(path string, clauses []SpanQuery) {
+	v.check(len(clauses) > 0, path, "at least one clause is required")
+	for i, c := range clauses {
+		v.child(path+jsonPath(i), c)
+	}
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanTermQuery represents a query of type "span_term", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-term-query.html
+type SpanTermQuery struct {
+	field string
+	value interface{}
+	boost float32
+}
+
+// SpanTerm creates a new query of type "span_term" on the provided field and
+// using the provided value.
+func SpanTerm(field string, value interface{}) *SpanTermQuery {
+	return &SpanTermQuery{
+		field: field,
+		value: value,
+	}
+}
+
+func (q *SpanTermQuery) spanQuery() {}
+
+// Boost sets the boost value of the query.
+func (q *SpanTermQuery) Boost(b float32) *SpanTermQuery {
+	q.boost = b
+	return q
+}
+
+// GetField returns the name of the field the query applies to.
+func (q *SpanTermQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanTermQuery) Clone() *SpanTermQuery {
+	c := *q
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanTermQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"value": q.value,
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"span_term": map[string]interface{}{
+			q.field: params,
+		},
+	}
+}
+
+// Validate checks that the query has a field name, implementing the Validator
+// interface.
+func (q *SpanTermQuery) Validate() error {
+	var v validation
+	v.field("/span_term", q.field)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanNearQuery represents a query of type "span_near", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-near-query.html
+type SpanNearQuery struct {
+	clauses []SpanQuery
+	slop    uint16
+	inOrder *bool
+}
+
+// SpanNear creates a new query of type "span_near" with the provided clauses.
+func SpanNear(clauses ...SpanQuery) *SpanNearQuery {
+	return &SpanNearQuery{
+		clauses: clauses,
+	}
+}
+
+func (q *SpanNearQuery) spanQuery() {}
+
+// Clauses adds one or more clauses to the query.
+func (q *SpanNearQuery) Clauses(clauses ...SpanQuery) *SpanNearQuery {
+	q.clauses = append(q.clauses, clauses...)
+	return q
+}
+
+// Slop sets the maximum number of intervening unmatched positions allowed.
+func (q *SpanNearQuery) Slop(n uint16) *SpanNearQuery {
+	q.slop = n
+	return q
+}
+
+// InOrder sets whether the clauses must match in the order they were
+// provided.
+func (q *SpanNearQuery) InOrder(b bool) *SpanNearQuery {
+	q.inOrder = &b
+	return q
+}
+
+// GetClauses returns the clauses of the query.
+func (q *SpanNearQuery) GetClauses() []SpanQuery {
+	return q.clauses
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanNearQuery) Clone() *SpanNearQuery {
+	c := *q
+	c.clauses = cloneSpanQueries(q.clauses)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanNearQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"clauses": spanClauses(q.clauses),
+	}
+	if q.slop > 0 {
+		params["slop"] = q.slop
+	}
+	if q.inOrder != nil {
+		params["in_order"] = *q.inOrder
+	}
+
+	return map[string]interface{}{
+		"span_near": params,
+	}
+}
+
+// Validate validates the query's clauses, implementing the Validator
+// interface.
+func (q *SpanNearQuery) Validate() error {
+	var v validation
+	v.spanClauses("/span_near/clauses", q.clauses)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanOrQuery represents a query of type "span_or", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-or-query.html
+type SpanOrQuery struct {
+	clauses []SpanQuery
+}
+
+// SpanOr creates a new query of type "span_or" with the provided clauses.
+func SpanOr(clauses ...SpanQuery) *SpanOrQuery {
+	return &SpanOrQuery{
+		clauses: clauses,
+	}
+}
+
+func (q *SpanOrQuery) spanQuery() {}
+
+// Clauses adds one or more clauses to the query.
+func (q *SpanOrQuery) Clauses(clauses ...SpanQuery) *SpanOrQuery {
+	q.clauses = append(q.clauses, clauses...)
+	return q
+}
+
+// GetClauses returns the clauses of the query.
+func (q *SpanOrQuery) GetClauses() []SpanQuery {
+	return q.clauses
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanOrQuery) Clone() *SpanOrQuery {
+	c := *q
+	c.clauses = cloneSpanQueries(q.clauses)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanOrQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"span_or": map[string]interface{}{
+			"clauses": spanClauses(q.clauses),
+		},
+	}
+}
+
+// Validate validates the query's clauses, implementing the Validator
+// interface.
+func (q *SpanOrQuery) Validate() error {
+	var v validation
+	v.spanClauses("/span_or/clauses", q.clauses)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanNotQuery represents a query of type "span_not", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-not-query.html
+type SpanNotQuery struct {
+	include SpanQuery
+	exclude SpanQuery
+	pre     *uint16
+	post    *uint16
+	dist    *uint16
+}
+
+// SpanNot creates a new query of type "span_not", matching spans of the
+// include query that do not overlap with spans of the exclude query.
+func SpanNot(include, exclude SpanQuery) *SpanNotQuery {
+	return &SpanNotQuery{
+		include: include,
+		exclude: exclude,
+	}
+}
+
+func (q *SpanNotQuery) spanQuery() {}
+
+// Pre sets the number of tokens before the include span that can't overlap
+// with the exclude span.
+func (q *SpanNotQuery) Pre(n uint16) *SpanNotQuery {
+	q.pre = &n
+	return q
+}
+
+// Post sets the number of tokens after the include span that can't overlap
+// with the exclude span.
+func (q *SpanNotQuery) Post(n uint16) *SpanNotQuery {
+	q.post = &n
+	return q
+}
+
+// Dist sets both the pre and post values at once.
+func (q *SpanNotQuery) Dist(n uint16) *SpanNotQuery {
+	q.dist = &n
+	return q
+}
+
+// GetInclude returns the query whose matches are kept.
+func (q *SpanNotQuery) GetInclude() SpanQuery {
+	return q.include
+}
+
+// GetExclude returns the query whose overlapping matches are removed.
+func (q *SpanNotQuery) GetExclude() SpanQuery {
+	return q.exclude
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanNotQuery) Clone() *SpanNotQuery {
+	c := *q
+	c.include = cloneSpanQuery(q.include)
+	c.exclude = cloneSpanQuery(q.exclude)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanNotQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"include": q.include.Map(),
+		"exclude": q.exclude.Map(),
+	}
+	if q.pre != nil {
+		params["pre"] = *q.pre
+	}
+	if q.post != nil {
+		params["post"] = *q.post
+	}
+	if q.dist != nil {
+		params["dist"] = *q.dist
+	}
+
+	return map[string]interface{}{
+		"span_not": params,
+	}
+}
+
+// Validate validates the query's include and exclude parts, implementing the
+// Validator interface.
+func (q *SpanNotQuery) Validate() error {
+	var v validation
+	v.child("/span_not/include", q.include)
+	v.child("/span_not/exclude", q.exclude)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanFirstQuery represents a query of type "span_first", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-first-query.html
+type SpanFirstQuery struct {
+	match SpanQuery
+	end   uint16
+}
+
+// SpanFirst creates a new query of type "span_first", matching spans of the
+// provided query that end no later than the provided position.
+func SpanFirst(match SpanQuery, end uint16) *SpanFirstQuery {
+	return &SpanFirstQuery{
+		match: match,
+		end:   end,
+	}
+}
+
+func (q *SpanFirstQuery) spanQuery() {}
+
+// GetMatch returns the wrapped span query.
+func (q *SpanFirstQuery) GetMatch() SpanQuery {
+	return q.match
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanFirstQuery) Clone() *SpanFirstQuery {
+	c := *q
+	c.match = cloneSpanQuery(q.match)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanFirstQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"span_first": map[string]interface{}{
+			"match": q.match.Map(),
+			"end":   q.end,
+		},
+	}
+}
+
+// Validate validates the query's inner query, implementing the Validator
+// interface.
+func (q *SpanFirstQuery) Validate() error {
+	var v validation
+	v.child("/span_first/match", q.match)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanContainingQuery represents a query of type "span_containing", as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-containing-query.html
+type SpanContainingQuery struct {
+	big    SpanQuery
+	little SpanQuery
+}
+
+// SpanContaining creates a new query of type "span_containing", matching spans
+// of the big query that contain a span of the little query.
+func SpanContaining(big, little SpanQuery) *SpanContainingQuery {
+	return &SpanContainingQuery{
+		big:    big,
+		little: little,
+	}
+}
+
+func (q *SpanContainingQuery) spanQuery() {}
+
+// GetBig returns the query whose matches are returned.
+func (q *SpanContainingQuery) GetBig() SpanQuery {
+	return q.big
+}
+
+// GetLittle returns the query that must be contained.
+func (q *SpanContainingQuery) GetLittle() SpanQuery {
+	return q.little
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanContainingQuery) Clone() *SpanContainingQuery {
+	c := *q
+	c.big = cloneSpanQuery(q.big)
+	c.little = cloneSpanQuery(q.little)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanContainingQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"span_containing": map[string]interface{}{
+			"big":    q.big.Map(),
+			"little": q.little.Map(),
+		},
+	}
+}
+
+// Validate validates the query's big and little parts, implementing the
+// Validator interface.
+func (q *SpanContainingQuery) Validate() error {
+	var v validation
+	v.child("/span_containing/big", q.big)
+	v.child("/span_containing/little", q.little)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanWithinQuery represents a query of type "span_within", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-within-query.html
+type SpanWithinQuery struct {
+	big    SpanQuery
+	little SpanQuery
+}
+
+// SpanWithin creates a new query of type "span_within", matching spans of the
+// little query that are enclosed in a span of the big query.
+func SpanWithin(big, little SpanQuery) *SpanWithinQuery {
+	return &SpanWithinQuery{
+		big:    big,
+		little: little,
+	}
+}
+
+func (q *SpanWithinQuery) spanQuery() {}
+
+// GetBig returns the query that must contain the matches.
+func (q *SpanWithinQuery) GetBig() SpanQuery {
+	return q.big
+}
+
+// GetLittle returns the query whose matches are returned.
+func (q *SpanWithinQuery) GetLittle() SpanQuery {
+	return q.little
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanWithinQuery) Clone() *SpanWithinQuery {
+	c := *q
+	c.big = cloneSpanQuery(q.big)
+	c.little = cloneSpanQuery(q.little)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanWithinQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"span_within": map[string]interface{}{
+			"big":    q.big.Map(),
+			"little": q.little.Map(),
+		},
+	}
+}
+
+// Validate validates the query's big and little parts, implementing the
+// Validator interface.
+func (q *SpanWithinQuery) Validate() error {
+	var v validation
+	v.child("/span_within/big", q.big)
+	v.child("/span_within/little", q.little)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SpanMultiTermQuery represents a query of type "span_multi", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-multi-term-query.html
+type SpanMultiTermQuery struct {
+	match MultiTermQuery
+}
+
+// SpanMultiTerm creates a new query of type "span_multi", wrapping the
+// provided term-level query so it can be used as a span query.
+func SpanMultiTerm(match MultiTermQuery) *SpanMultiTermQuery {
+	return &SpanMultiTermQuery{
+		match: match,
+	}
+}
+
+func (q *SpanMultiTermQuery) spanQuery() {}
+
+// GetMatch returns the wrapped multi-term query.
+func (q *SpanMultiTermQuery) GetMatch() MultiTermQuery {
+	return q.match
+}
+
+// Clone returns a deep copy of the query.
+func (q *SpanMultiTermQuery) Clone() *SpanMultiTermQuery {
+	c := *q
+	if q.match != nil {
+		c.match = cloneMappable(q.match).(MultiTermQuery)
+	}
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *SpanMultiTermQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"span_multi": map[string]interface{}{
+			"match": q.match.Map(),
+		},
+	}
+}
+
+// Validate validates the wrapped query, implementing the Validator interface.
+func (q *SpanMultiTermQuery) Validate() error {
+	var v validation
+	v.child("/span_multi/match", q.match)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// FieldMaskingSpanQuery represents a query of type "field_masking_span", as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-span-field-masking-query.html
+type FieldMaskingSpanQuery struct {
+	query SpanQuery
+	field string
+}
+
+// FieldMaskingSpan creates a new query of type "field_masking_span", allowing
+// the provided span query to be combined with span queries on other fields by
+// pretending it runs on the provided field.
+func FieldMaskingSpan(query SpanQuery, field string) *FieldMaskingSpanQuery {
+	return &FieldMaskingSpanQuery{
+		query: query,
+		field: field,
+	}
+}
+
+func (q *FieldMaskingSpanQuery) spanQuery() {}
+
+// GetQuery returns the wrapped span query.
+func (q *FieldMaskingSpanQuery) GetQuery() SpanQuery {
+	return q.query
+}
+
+// GetField returns the name of the field the query is masked as.
+func (q *FieldMaskingSpanQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *FieldMaskingSpanQuery) Clone() *FieldMaskingSpanQuery {
+	c := *q
+	c.query = cloneSpanQuery(q.query)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *FieldMaskingSpanQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"field_masking_span": map[string]interface{}{
+			"query": q.query.Map(),
+			"field": q.field,
+		},
+	}
+}
+
+// Validate validates the wrapped query and checks that a field name is
+// provided, implementing the Validator interface.
+func (q *FieldMaskingSpanQuery) Validate() error {
+	var v validation
+	v.child("/field_masking_span/query", q.query)
+	v.field("/field_masking_span/field", q.field)
+	return v.err()
+}
@@ -0,0 +1,164 @@
+package esquery
+
+import "testing"
+
+func TestSpan(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"span_term",
+			SpanTerm("user", "kimchy").Boost(2),
+			map[string]interface{}{
+				"span_term": map[string]interface{}{
+					"user": map[string]interface{}{
+						"value": "kimchy",
+						"boost": 2,
+					},
+				},
+			},
+		},
+		{
+			"span_near",
+			SpanNear(SpanTerm("field", "value1"), SpanTerm("field", "value2")).
+				Clauses(SpanTerm("field", "value3")).
+				Slop(12).
+				InOrder(false),
+			map[string]interface{}{
+				"span_near": map[string]interface{}{
+					"clauses": []map[string]interface{}{
+						{"span_term": map[string]interface{}{"field": map[string]interface{}{"value": "value1"}}},
+						{"span_term": map[string]interface{}{"field": map[string]interface{}{"value": "value2"}}},
+						{"span_term": map[string]interface{}{"field": map[string]interface{}{"value": "value3"}}},
+					},
+					"slop":     12,
+					"in_order": false,
+				},
+			},
+		},
+		{
+			"span_or",
+			SpanOr(SpanTerm("field", "value1"), SpanTerm("field", "value2")),
+			map[string]interface{}{
+				"span_or": map[string]interface{}{
+					"clauses": []map[string]interface{}{
+						{"span_term": map[string]interface{}{"field": map[string]interface{}{"value": "value1"}}},
+						{"span_term": map[string]interface{}{"field": map[string]interface{}{"value": "value2"}}},
+					},
+				},
+			},
+		},
+		{
+			"span_not",
+			SpanNot(
+				SpanTerm("field1", "hoya"),
+				SpanNear(SpanTerm("field1", "la"), SpanTerm("field1", "hoya")).Slop(0).InOrder(true),
+			).Pre(1).Post(2).Dist(3),
+			map[string]interface{}{
+				"span_not": map[string]interface{}{
+					"include": map[string]interface{}{
+						"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "hoya"}},
+					},
+					"exclude": map[string]interface{}{
+						"span_near": map[string]interface{}{
+							"clauses": []map[string]interface{}{
+								{"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "la"}}},
+								{"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "hoya"}}},
+							},
+							"in_order": true,
+						},
+					},
+					"pre":  1,
+					"post": 2,
+					"dist": 3,
+				},
+			},
+		},
+		{
+			"span_first",
+			SpanFirst(SpanTerm("user", "kimchy"), 3),
+			map[string]interface{}{
+				"span_first": map[string]interface{}{
+					"match": map[string]interface{}{
+						"span_term": map[string]interface{}{"user": map[string]interface{}{"value": "kimchy"}},
+					},
+					"end": 3,
+				},
+			},
+		},
+		{
+			"span_containing and span_within",
+			SpanContaining(
+				SpanNear(SpanTerm("field1", "bar"), SpanTerm("field1", "baz")).Slop(5).InOrder(true),
+				SpanWithin(SpanTerm("field1", "foo"), SpanTerm("field1", "qux")),
+			),
+			map[string]interface{}{
+				"span_containing": map[string]interface{}{
+					"big": map[string]interface{}{
+						"span_near": map[string]interface{}{
+							"clauses": []map[string]interface{}{
+								{"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "bar"}}},
+								{"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "baz"}}},
+							},
+							"slop":     5,
+							"in_order": true,
+						},
+					},
+					"little": map[string]interface{}{
+						"span_within": map[string]interface{}{
+							"big": map[string]interface{}{
+								"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "foo"}},
+							},
+							"little": map[string]interface{}{
+								"span_term": map[string]interface{}{"field1": map[string]interface{}{"value": "qux"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"span_multi",
+			SpanNear(
+				SpanMultiTerm(Prefix("user", "ki")),
+				SpanMultiTerm(Wildcard("user", "*chy")),
+				SpanMultiTerm(Fuzzy("user", "kimchi").Fuzziness("1")),
+			),
+			map[string]interface{}{
+				"span_near": map[string]interface{}{
+					"clauses": []map[string]interface{}{
+						{"span_multi": map[string]interface{}{"match": map[string]interface{}{
+							"prefix": map[string]interface{}{"user": map[string]interface{}{"value": "ki"}},
+						}}},
+						{"span_multi": map[string]interface{}{"match": map[string]interface{}{
+							"wildcard": map[string]interface{}{"user": map[string]interface{}{"value": "*chy"}},
+						}}},
+						{"span_multi": map[string]interface{}{"match": map[string]interface{}{
+							"fuzzy": map[string]interface{}{"user": map[string]interface{}{"value": "kimchi", "fuzziness": "1"}},
+						}}},
+					},
+				},
+			},
+		},
+		{
+			"field_masking_span",
+			SpanNear(
+				SpanTerm("text", "quick brown"),
+				FieldMaskingSpan(SpanTerm("text.stems", "fox"), "text"),
+			).Slop(5).InOrder(false),
+			map[string]interface{}{
+				"span_near": map[string]interface{}{
+					"clauses": []map[string]interface{}{
+						{"span_term": map[string]interface{}{"text": map[string]interface{}{"value": "quick brown"}}},
+						{"field_masking_span": map[string]interface{}{
+							"query": map[string]interface{}{
+								"span_term": map[string]interface{}{"text.stems": map[string]interface{}{"value": "fox"}},
+							},
+							"field": "text",
+						}},
+					},
+					"slop":     5,
+					"in_order": false,
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,338 @@
+package esquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// ScriptQuery represents a query of type "script", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-script-query.html
+// It is typically used in a filter context.
+type ScriptQuery struct {
+	script *Script
+	boost  float32
+}
+
+// ScriptFilter creates a new query of type "script", matching documents for
+// which the provided script returns true. The method name differs from the
+// query type to prevent conflict with the Script type.
+func ScriptFilter(script *Script) *ScriptQuery {
+	return &ScriptQuery{
+		script: script,
+	}
+}
+
+// Boost sets the boost value of the query.
+func (q *ScriptQuery) Boost(b float32) *ScriptQuery {
+	q.boost = b
+	return q
+}
+
+// Clone returns a deep copy of the query.
+func (q *ScriptQuery) Clone() *ScriptQuery {
+	c := *q
+	c.script = cloneScript(q.script)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *ScriptQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"script": q.script.Map(),
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"script": params,
+	}
+}
+
+// Validate validates the query's script, implementing the Validator interface.
+func (q *ScriptQuery) Validate() error {
+	var v validation
+	v.child("/script/script", q.script)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// WrapperQuery represents a query of type "wrapper", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-wrapper-query.html
+type WrapperQuery struct {
+	query string
+}
+
+// Wrapper creates a new query of type "wrapper" with the provided query, in
+// JSON format. The query is base64-encoded when the wrapper is mapped.
+func Wrapper(query string) *WrapperQuery {
+	return &WrapperQuery{
+		query: query,
+	}
+}
+
+// Clone returns a deep copy of the query.
+func (q *WrapperQuery) Clone() *WrapperQuery {
+	c := *q
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *WrapperQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"wrapper": map[string]interface{}{
+			"query": base64.StdEncoding.EncodeToString([]byte(q.query)),
+		},
+	}
+}
+
+// Validate checks that the wrapped query is valid JSON, implementing the
+// Validator interface.
+func (q *WrapperQuery) Validate() error {
+	var v validation
+	v.check(json.Valid([]byte(q.query)), "/wrapper/query", "is not valid JSON")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// PinnedQuery represents a query of type "pinned", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-pinned-query.html
+type PinnedQuery struct {
+	ids     []string
+	organic Mappable
+}
+
+// Pinned creates a new query of type "pinned", promoting the documents with
+// the provided IDs (in order) above the documents matching the provided
+// organic query.
+func Pinned(organic Mappable, ids ...string) *PinnedQuery {
+	return &PinnedQuery{
+		ids:     ids,
+		organic: organic,
+	}
+}
+
+// IDs adds one or more IDs of documents to promote. IDs can be called
+// multiple times, IDs will be appended to existing ones.
+func (q *PinnedQuery) IDs(ids ...string) *PinnedQuery {
+	q.ids = append(q.ids, ids...)
+	return q
+}
+
+// GetOrganic returns the query ranking the documents after the pinned ones.
+func (q *PinnedQuery) GetOrganic() Mappable {
+	return q.organic
+}
+
+// Clone returns a deep copy of the query.
+func (q *PinnedQuery) Clone() *PinnedQuery {
+	c := *q
+	c.ids = cloneStrings(q.ids)
+	c.organic = cloneMappable(q.organic)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *PinnedQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"ids": q.ids,
+	}
+	if q.organic != nil {
+		params["organic"] = q.organic.Map()
+	}
+
+	return map[string]interface{}{
+		"pinned": params,
+	}
+}
+
+// Validate validates the organic query and checks that at least one ID is
+// provided, implementing the Validator interface.
+func (q *PinnedQuery) Validate() error {
+	var v validation
+	v.check(len(q.ids) > 0, "/pinned/ids", "at least one ID is required")
+	v.child("/pinned/organic", q.organic)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// DistanceFeatureQuery represents a query of type "distance_feature", as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-distance-feature-query.html
+type DistanceFeatureQuery struct {
+	field  string
+	origin interface{}
+	pivot  string
+	boost  float32
+}
+
+// DistanceFeature creates a new query of type "distance_feature", boosting
+// documents whose date or geo_point field is closer to the provided origin.
+// For date fields, the origin is a date (e.g. "now") and the pivot a time
+// unit (e.g. "7d"); for geo_point fields, the origin is a point (e.g. a
+// GeoPoint) and the pivot a distance (e.g. "1km").
+func DistanceFeature(field string, origin interface{}, pivot string) *DistanceFeatureQuery {
+	return &DistanceFeatureQuery{
+		field:  field,
+		origin: origin,
+		pivot:  pivot,
+	}
+}
+
+// Boost sets the boost value of the query.
+func (q *DistanceFeatureQuery) Boost(b float32) *DistanceFeatureQuery {
+	q.boost = b
+	return q
+}
+
+// GetField returns the name of the field the query applies to.
+func (q *DistanceFeatureQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *DistanceFeatureQuery) Clone() *DistanceFeatureQuery {
+	c := *q
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *DistanceFeatureQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"field":  q.field,
+		"origin": q.origin,
+		"pivot":  q.pivot,
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"distance_feature": params,
+	}
+}
+
+// Validate checks that the query has a field name, an origin and a pivot,
+// implementing the Validator interface.
+func (q *DistanceFeatureQuery) Validate() error {
+	var v validation
+	v.field("/distance_feature/field", q.field)
+	v.check(q.origin != nil, "/distance_feature/origin", "is required")
+	v.check(q.pivot != "", "/distance_feature/pivot", "is required")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// RankFeatureQuery represents a query of type "rank_feature", as described
+// in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-rank-feature-query.html
+type RankFeatureQuery struct {
+	field    string
+	function string
+	params   map[string]interface{}
+	boost    float32
+}
+
+// RankFeature creates a new query of type "rank_feature", boosting documents
+// based on the value of the provided field of type "rank_feature". Features
+// of a field of type "rank_features" are referenced as "field.feature". If
+// no function is set, ElasticSearch uses the saturation function.
+func RankFeature(field string) *RankFeatureQuery {
+	return &RankFeatureQuery{
+		field: field,
+	}
+}
+
+// Saturation sets the function of the query to the saturation function, with
+// a pivot computed by ElasticSearch.
+func (q *RankFeatureQuery) Saturation() *RankFeatureQuery {
+	return q.setFunction("saturation", map[string]interface{}{})
+}
+
+// SaturationPivot sets the function of the query to the saturation function,
+// with the provided pivot.
+func (q *RankFeatureQuery) SaturationPivot(pivot float32) *RankFeatureQuery {
+	return q.setFunction("saturation", map[string]interface{}{
+		"pivot": pivot,
+	})
+}
+
+// Log sets the function of the query to the logarithmic function, with the
+// provided scaling factor.
+func (q *RankFeatureQuery) Log(scalingFactor float32) *RankFeatureQuery {
+	return q.setFunction("log", map[string]interface{}{
+		"scaling_factor": scalingFactor,
+	})
+}
+
+// Sigmoid sets the function of the query to the sigmoid function, with the
+// provided pivot and exponent.
+func (q *RankFeatureQuery) Sigmoid(pivot, exponent float32) *RankFeatureQuery {
+	return q.setFunction("sigmoid", map[string]interface{}{
+		"pivot":    pivot,
+		"exponent": exponent,
+	})
+}
+
+// Linear sets the function of the query to the linear function.
+func (q *RankFeatureQuery) Linear() *RankFeatureQuery {
+	return q.setFunction("linear", map[string]interface{}{})
+}
+
+func (q *RankFeatureQuery) setFunction(name string, params map[string]interface{}) *RankFeatureQuery {
+	q.function = name
+	q.params = params
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *RankFeatureQuery) Boost(b float32) *RankFeatureQuery {
+	q.boost = b
+	return q
+}
+
+// GetField returns the name of the field the query applies to.
+func (q *RankFeatureQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *RankFeatureQuery) Clone() *RankFeatureQuery {
+	c := *q
+	c.params = cloneMap(q.params)
+	return &c
+}
+
+// Map returns a map representation of the query, thus implementing the
+// Mappable interface.
+func (q *RankFeatureQuery) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"field": q.field,
+	}
+	if q.function != "" {
+		params[q.function] = q.params
+	}
+	if q.boost != 0 {
+		params["boost"] = q.boost
+	}
+
+	return map[string]interface{}{
+		"rank_feature": params,
+	}
+}
+
+// Validate checks that the query has a field name, implementing the Validator
+// interface.
+func (q *RankFeatureQuery) Validate() error {
+	var v validation
+	v.field("/rank_feature/field", q.field)
+	return v.err()
+}
@@ -0,0 +1,141 @@
+package esquery
+
+import "testing"
+
+func TestSpecializedQueries(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"script query in a filter context",
+			Bool().Filter(
+				ScriptFilter(
+					InlineScript("doc['likes'].value > params.min").Param("min", 10),
+				).Boost(1.5),
+			),
+			map[string]interface{}{
+				"bool": map[string]interface{}{
+					"filter": []map[string]interface{}{
+						{
+							"script": map[string]interface{}{
+								"script": map[string]interface{}{
+									"source": "doc['likes'].value > params.min",
+									"params": map[string]interface{}{
+										"min": 10,
+									},
+								},
+								"boost": 1.5,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"wrapper",
+			Wrapper(`{"term":{"user":"kimchy"}}`),
+			map[string]interface{}{
+				"wrapper": map[string]interface{}{
+					"query": "eyJ0ZXJtIjp7InVzZXIiOiJraW1jaHkifX0=",
+				},
+			},
+		},
+		{
+			"pinned",
+			Pinned(Match("description", "iphone"), "1", "4").IDs("100"),
+			map[string]interface{}{
+				"pinned": map[string]interface{}{
+					"ids": []string{"1", "4", "100"},
+					"organic": map[string]interface{}{
+						"match": map[string]interface{}{
+							"description": map[string]interface{}{
+								"query": "iphone",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"distance_feature on a date",
+			DistanceFeature("production_date", "now", "7d").Boost(2),
+			map[string]interface{}{
+				"distance_feature": map[string]interface{}{
+					"field":  "production_date",
+					"origin": "now",
+					"pivot":  "7d",
+					"boost":  2,
+				},
+			},
+		},
+		{
+			"distance_feature on a geo_point",
+			DistanceFeature("location", GeoPoint{Lat: 40, Lon: -70}, "1km"),
+			map[string]interface{}{
+				"distance_feature": map[string]interface{}{
+					"field":  "location",
+					"origin": map[string]interface{}{"lat": 40, "lon": -70},
+					"pivot":  "1km",
+				},
+			},
+		},
+		{
+			"rank_feature without a function",
+			RankFeature("pagerank"),
+			map[string]interface{}{
+				"rank_feature": map[string]interface{}{
+					"field": "pagerank",
+				},
+			},
+		},
+		{
+			"rank_feature with saturation",
+			RankFeature("pagerank").Saturation(),
+			map[string]interface{}{
+				"rank_feature": map[string]interface{}{
+					"field":      "pagerank",
+					"saturation": map[string]interface{}{},
+				},
+			},
+		},
+		{
+			"rank_feature with saturation pivot",
+			RankFeature("pagerank").SaturationPivot(8).Boost(0.5),
+			map[string]interface{}{
+				"rank_feature": map[string]interface{}{
+					"field":      "pagerank",
+					"saturation": map[string]interface{}{"pivot": 8},
+					"boost":      0.5,
+				},
+			},
+		},
+		{
+			"rank_feature with log on a rank_features field",
+			RankFeature("topics.sports").Log(4),
+			map[string]interface{}{
+				"rank_feature": map[string]interface{}{
+					"field": "topics.sports",
+					"log":   map[string]interface{}{"scaling_factor": 4},
+				},
+			},
+		},
+		{
+			"rank_feature with sigmoid",
+			RankFeature("pagerank").Sigmoid(7, 0.6),
+			map[string]interface{}{
+				"rank_feature": map[string]interface{}{
+					"field":   "pagerank",
+					"sigmoid": map[string]interface{}{"pivot": 7, "exponent": 0.6},
+				},
+			},
+		},
+		{
+			"rank_feature with linear",
+			RankFeature("pagerank").Linear(),
+			map[string]interface{}{
+				"rank_feature": map[string]interface{}{
+					"field":  "pagerank",
+					"linear": map[string]interface{}{},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,486 @@
+package esquery
+
+import (
+	"strings"
+
+	"github.com/fatih/structs"
+)
+
+// QueryStringQuery represents a query of type "query_string", as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-query-string-query.html
+type QueryStringQuery struct {
+	params queryStringParams
+}
+
+// GetQuery returns the query string.
+func (q *QueryStringQuery) GetQuery() string {
+	return q.params.Qry
+}
+
+// GetFields returns the names of the fields the query applies to, without
+// their boosts.
+func (q *QueryStringQuery) GetFields() []string {
+	return fieldNames(q.params.Fields)
+}
+
+// Clone returns a deep copy of the query.
+func (q *QueryStringQuery) Clone() *QueryStringQuery {
+	c := *q
+	c.params.Fields = cloneStrings(q.params.Fields)
+	return &c
+}
+
+// Map returns a map representation of the query; implementing the
+// Mappable interface.
+func (q *QueryStringQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"query_string": structs.Map(q.params),
+	}
+}
+
+// Validate checks that the query has a query string, implementing the
+// Validator interface.
+func (q *QueryStringQuery) Validate() error {
+	var v validation
+	v.check(q.params.Qry != "", "/query_string/query", "is required")
+	return v.err()
+}
+
+type queryStringParams struct {
+	Qry              string         `structs:"query"`
+	DefaultField     string         `structs:"default_field,omitempty"`
+	Fields           []string       `structs:"fields,omitempty"`
+	Type             MultiMatchType `structs:"type,string,omitempty"`
+	AllowLeading     *bool          `structs:"allow_leading_wildcard,omitempty"`
+	AnalyzeWildcard  *bool          `structs:"analyze_wildcard,omitempty"`
+	Anl              string         `structs:"analyzer,omitempty"`
+	AutoGenerate     *bool          `structs:"auto_generate_synonyms_phrase_query,omitempty"`
+	Boost            float32        `structs:"boost,omitempty"`
+	Op               MatchOperator  `structs:"default_operator,string,omitempty"`
+	EnablePosInc     *bool          `structs:"enable_position_increments,omitempty"`
+	Fuzz             string         `structs:"fuzziness,omitempty"`
+	FuzzyMaxExp      uint16         `structs:"fuzzy_max_expansions,omitempty"`
+	FuzzyPrefLen     uint16         `structs:"fuzzy_prefix_length,omitempty"`
+	FuzzyTrans       *bool          `structs:"fuzzy_transpositions,omitempty"`
+	Lent             *bool          `structs:"lenient,omitempty"`
+	MaxDetStates     uint16         `structs:"max_determinized_states,omitempty"`
+	MinMatch         string         `structs:"minimum_should_match,omitempty"`
+	QuoteAnl         string         `structs:"quote_analyzer,omitempty"`
+	PhraseSlp        uint16         `structs:"phrase_slop,omitempty"`
+	QuoteFieldSuffix string         `structs:"quote_field_suffix,omitempty"`
+	Rewrite          string         `structs:"rewrite,omitempty"`
+	TieBrk           float32        `structs:"tie_breaker,omitempty"`
+	TimeZone         string         `structs:"time_zone,omitempty"`
+}
+
+// QueryString creates a new query of type "query_string" with the provided
+// query text, written in the Lucene query syntax.
+func QueryString(query string) *QueryStringQuery {
+	return &QueryStringQuery{
+		params: queryStringParams{
+			Qry: query,
+		},
+	}
+}
+
+// Query sets the query text.
+func (q *QueryStringQuery) Query(query string) *QueryStringQuery {
+	q.params.Qry = query
+	return q
+}
+
+// DefaultField sets the field to search when no field is provided in the
+// query text.
+func (q *QueryStringQuery) DefaultField(field string) *QueryStringQuery {
+	q.params.DefaultField = field
+	return q
+}
+
+// Fields sets the fields to search. As with MultiMatchQuery, fields can be
+// boosted with the "^" notation (e.g. "title^2").
+func (q *QueryStringQuery) Fields(a ...string) *QueryStringQuery {
+	q.params.Fields = append(q.params.Fields, a...)
+	return q
+}
+
+// BoostedFields adds fields with their boosts to the fields used in the query.
+func (q *QueryStringQuery) BoostedFields(fields ...BoostedField) *QueryStringQuery {
+	return q.Fields(boostedFields(fields)...)
+}
+
+// Type sets how the query is executed when searching multiple fields.
+func (q *QueryStringQuery) Type(t MultiMatchType) *QueryStringQuery {
+	q.params.Type = t
+	return q
+}
+
+// AllowLeadingWildcard sets whether "*" and "?" are allowed as the first
+// character of a term.
+func (q *QueryStringQuery) AllowLeadingWildcard(b bool) *QueryStringQuery {
+	q.params.AllowLeading = &b
+	return q
+}
+
+// AnalyzeWildcard sets whether to analyze wildcard terms.
+func (q *QueryStringQuery) AnalyzeWildcard(b bool) *QueryStringQuery {
+	q.params.AnalyzeWildcard = &b
+	return q
+}
+
+// Analyzer sets the analyzer used to convert the query text into tokens.
+func (q *QueryStringQuery) Analyzer(a string) *QueryStringQuery {
+	q.params.Anl = a
+	return q
+}
+
+// AutoGenerateSynonymsPhraseQuery sets the "auto_generate_synonyms_phrase_query"
+// boolean.
+func (q *QueryStringQuery) AutoGenerateSynonymsPhraseQuery(b bool) *QueryStringQuery {
+	q.params.AutoGenerate = &b
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *QueryStringQuery) Boost(b float32) *QueryStringQuery {
+	q.params.Boost = b
+	return q
+}
+
+// DefaultOperator sets the boolean logic used to interpret the query text when
+// no operator is specified.
+func (q *QueryStringQuery) DefaultOperator(op MatchOperator) *QueryStringQuery {
+	q.params.Op = op
+	return q
+}
+
+// EnablePositionIncrements sets whether position increments are enabled in
+// constructed queries.
+func (q *QueryStringQuery) EnablePositionIncrements(b bool) *QueryStringQuery {
+	q.params.EnablePosInc = &b
+	return q
+}
+
+// Fuzziness sets the maximum edit distance allowed for fuzzy matching.
+func (q *QueryStringQuery) Fuzziness(f string) *QueryStringQuery {
+	q.params.Fuzz = f
+	return q
+}
+
+// FuzzyMaxExpansions sets the maximum number of terms to which the query
+// expands for fuzzy matching.
+func (q *QueryStringQuery) FuzzyMaxExpansions(e uint16) *QueryStringQuery {
+	q.params.FuzzyMaxExp = e
+	return q
+}
+
+// FuzzyPrefixLength sets the number of beginning characters left unchanged for
+// fuzzy matching.
+func (q *QueryStringQuery) FuzzyPrefixLength(l uint16) *QueryStringQuery {
+	q.params.FuzzyPrefLen = l
+	return q
+}
+
+// FuzzyTranspositions sets whether edits for fuzzy matching include
+// transpositions of two adjacent characters.
+func (q *QueryStringQuery) FuzzyTranspositions(b bool) *QueryStringQuery {
+	q.params.FuzzyTrans = &b
+	return q
+}
+
+// Lenient sets whether format-based errors should be ignored.
+func (q *QueryStringQuery) Lenient(b bool) *QueryStringQuery {
+	q.params.Lent = &b
+	return q
+}
+
+// MaxDeterminizedStates sets the maximum number of automaton states required
+// for the query.
+func (q *QueryStringQuery) MaxDeterminizedStates(m uint16) *QueryStringQuery {
+	q.params.MaxDetStates = m
+	return q
+}
+
+// MinimumShouldMatch sets the minimum number of clauses that must match for a
+// document to be returned.
+func (q *QueryStringQuery) MinimumShouldMatch(s string) *QueryStringQuery {
+	q.params.MinMatch = s
+	return q
+}
+
+// QuoteAnalyzer sets the analyzer used to convert quoted text into tokens.
+func (q *QueryStringQuery) QuoteAnalyzer(a string) *QueryStringQuery {
+	q.params.QuoteAnl = a
+	return q
+}
+
+// PhraseSlop sets the maximum number of positions allowed between matching
+// tokens for phrases.
+func (q *QueryStringQuery) PhraseSlop(n uint16) *QueryStringQuery {
+	q.params.PhraseSlp = n
+	return q
+}
+
+// QuoteFieldSuffix sets a suffix appended to quoted text in the query text.
+func (q *QueryStringQuery) QuoteFieldSuffix(s string) *QueryStringQuery {
+	q.params.QuoteFieldSuffix = s
+	return q
+}
+
+// Rewrite sets the method used to rewrite the query.
+func (q *QueryStringQuery) Rewrite(s string) *QueryStringQuery {
+	q.params.Rewrite = s
+	return q
+}
+
+// TieBreaker sets the tie breaker used when searching multiple fields.
+func (q *QueryStringQuery) TieBreaker(t float32) *QueryStringQuery {
+	q.params.TieBrk = t
+	return q
+}
+
+// TimeZone sets the time zone used to convert date values in the query text to
+// UTC.
+func (q *QueryStringQuery) TimeZone(zone string) *QueryStringQuery {
+	q.params.TimeZone = zone
+	return q
+}
+
+//----------------------------------------------------------------------------//
+
+// SimpleQueryStringQuery represents a query of type "simple_query_string", as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-simple-query-string-query.html
+type SimpleQueryStringQuery struct {
+	params simpleQueryStringParams
+}
+
+// GetQuery returns the query string.
+func (q *SimpleQueryStringQuery) GetQuery() string {
+	return q.params.Qry
+}
+
+// GetFields returns the names of the fields the query applies to, without
+// their boosts.
+func (q *SimpleQueryStringQuery) GetFields() []string {
+	return fieldNames(q.params.Fields)
+}
+
+// Clone returns a deep copy of the query.
+func (q *SimpleQueryStringQuery) Clone() *SimpleQueryStringQuery {
+	c := *q
+	c.params.Fields = cloneStrings(q.params.Fields)
+	return &c
+}
+
+// Map returns a map representation of the query; implementing the
+// Mappable interface.
+func (q *SimpleQueryStringQuery) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"simple_query_string": structs.Map(q.params),
+	}
+}
+
+// Validate checks that the query has a query string, implementing the
+// Validator interface.
+func (q *SimpleQueryStringQuery) Validate() error {
+	var v validation
+	v.check(q.params.Qry != "", "/simple_query_string/query", "is required")
+	return v.err()
+}
+
+type simpleQueryStringParams struct {
+	Qry              string                `structs:"query"`
+	Fields           []string              `structs:"fields,omitempty"`
+	Op               MatchOperator         `structs:"default_operator,string,omitempty"`
+	AnalyzeWildcard  *bool                 `structs:"analyze_wildcard,omitempty"`
+	Anl              string                `structs:"analyzer,omitempty"`
+	AutoGenerate     *bool                 `structs:"auto_generate_synonyms_phrase_query,omitempty"`
+	Boost            float32               `structs:"boost,omitempty"`
+	Flags            SimpleQueryStringFlag `structs:"flags,string,omitempty"`
+	FuzzyMaxExp      uint16                `structs:"fuzzy_max_expansions,omitempty"`
+	FuzzyPrefLen     uint16                `structs:"fuzzy_prefix_length,omitempty"`
+	FuzzyTrans       *bool                 `structs:"fuzzy_transpositions,omitempty"`
+	Lent             *bool                 `structs:"lenient,omitempty"`
+	MinMatch         string                `structs:"minimum_should_match,omitempty"`
+	QuoteFieldSuffix string                `structs:"quote_field_suffix,omitempty"`
+}
+
+// SimpleQueryString creates a new query of type "simple_query_string" with the
+// provided query text.
+func SimpleQueryString(query string) *SimpleQueryStringQuery {
+	return &SimpleQueryStringQuery{
+		params: simpleQueryStringParams{
+			Qry: query,
+		},
+	}
+}
+
+// Query sets the query text.
+func (q *SimpleQueryStringQuery) Query(query string) *SimpleQueryStringQuery {
+	q.params.Qry = query
+	return q
+}
+
+// Fields sets the fields to search. As with MultiMatchQuery, fields can be
+// boosted with the "^" notation (e.g. "title^2").
+func (q *SimpleQueryStringQuery) Fields(a ...string) *SimpleQueryStringQuery {
+	q.params.Fields = append(q.params.Fields, a...)
+	return q
+}
+
+// BoostedFields adds fields with their boosts to the fields used in the query.
+func (q *SimpleQueryStringQuery) BoostedFields(fields ...BoostedField) *SimpleQueryStringQuery {
+	return q.Fields(boostedFields(fields)...)
+}
+
+// DefaultOperator sets the boolean logic used to interpret the query text when
+// no operator is specified.
+func (q *SimpleQueryStringQuery) DefaultOperator(op MatchOperator) *SimpleQueryStringQuery {
+	q.params.Op = op
+	return q
+}
+
+// AnalyzeWildcard sets whether to analyze wildcard terms.
+func (q *SimpleQueryStringQuery) AnalyzeWildcard(b bool) *SimpleQueryStringQuery {
+	q.params.AnalyzeWildcard = &b
+	return q
+}
+
+// Analyzer sets the analyzer used to convert the query text into tokens.
+func (q *SimpleQueryStringQuery) Analyzer(a string) *SimpleQueryStringQuery {
+	q.params.Anl = a
+	return q
+}
+
+// AutoGenerateSynonymsPhraseQuery sets the "auto_generate_synonyms_phrase_query"
+// boolean.
+func (q *SimpleQueryStringQuery) AutoGenerateSynonymsPhraseQuery(b bool) *SimpleQueryStringQuery {
+	q.params.AutoGenerate = &b
+	return q
+}
+
+// Boost sets the boost value of the query.
+func (q *SimpleQueryStringQuery) Boost(b float32) *SimpleQueryStringQuery {
+	q.params.Boost = b
+	return q
+}
+
+// Flags sets the operators enabled in the query text, e.g.
+// SimpleFlagAnd|SimpleFlagOr|SimpleFlagPrefix.
+func (q *SimpleQueryStringQuery) Flags(f SimpleQueryStringFlag) *SimpleQueryStringQuery {
+	q.params.Flags = f
+	return q
+}
+
+// FuzzyMaxExpansions sets the maximum number of terms to which the query
+// expands for fuzzy matching.
+func (q *SimpleQueryStringQuery) FuzzyMaxExpansions(e uint16) *SimpleQueryStringQuery {
+	q.params.FuzzyMaxExp = e
+	return q
+}
+
+// FuzzyPrefixLength sets the number of beginning characters left unchanged for
+// fuzzy matching.
+func (q *SimpleQueryStringQuery) FuzzyPrefixLength(l uint16) *SimpleQueryStringQuery {
+	q.params.FuzzyPrefLen = l
+	return q
+}
+
+// FuzzyTranspositions sets whether edits for fuzzy matching include
+// transpositions of two adjacent characters.
+func (q *SimpleQueryStringQuery) FuzzyTranspositions(b bool) *SimpleQueryStringQuery {
+	q.params.FuzzyTrans = &b
+	return q
+}
+
+// Lenient sets whether format-based errors should be ignored.
+func (q *SimpleQueryStringQuery) Lenient(b bool) *SimpleQueryStringQuery {
+	q.params.Lent = &b
+	return q
+}
+
+// MinimumShouldMatch sets the minimum number of clauses that must match for a
+// document to be returned.
+func (q *SimpleQueryStringQuery) MinimumShouldMatch(s string) *SimpleQueryStringQuery {
+	q.params.MinMatch = s
+	return q
+}
+
+// QuoteFieldSuffix sets a suffix appended to quoted text in the query text.
+func (q *SimpleQueryStringQuery) QuoteFieldSuffix(s string) *SimpleQueryStringQuery {
+	q.params.QuoteFieldSuffix = s
+	return q
+}
+
+// SimpleQueryStringFlag is a set of flags representing the operators enabled
+// in a simple_query_string query. Flags can be combined with the "|" operator.
+type SimpleQueryStringFlag uint16
+
+const (
+	// SimpleFlagAnd enables the "+" AND operator
+	SimpleFlagAnd SimpleQueryStringFlag = 1 << iota
+
+	// SimpleFlagOr enables the "|" OR operator
+	SimpleFlagOr
+
+	// SimpleFlagNot enables the "-" NOT operator
+	SimpleFlagNot
+
+	// SimpleFlagPhrase enables the '"' operator for phrases
+	SimpleFlagPhrase
+
+	// SimpleFlagPrefix enables the "*" prefix operator
+	SimpleFlagPrefix
+
+	// SimpleFlagPrecedence enables parentheses for precedence
+	SimpleFlagPrecedence
+
+	// SimpleFlagEscape enables "\" as an escape character
+	SimpleFlagEscape
+
+	// SimpleFlagWhitespace enables whitespace as a split character
+	SimpleFlagWhitespace
+
+	// SimpleFlagFuzzy enables the "~N" operator after a word
+	SimpleFlagFuzzy
+
+	// SimpleFlagNear enables the "~N" operator after a phrase
+	SimpleFlagNear
+
+	// SimpleFlagSlop is a synonym of SimpleFlagNear
+	SimpleFlagSlop
+
+	// SimpleFlagNone disables all operators
+	SimpleFlagNone
+
+	// SimpleFlagAll enables all operators
+	SimpleFlagAll
+)
+
+var simpleQueryStringFlagNames = []string{
+	"AND",
+	"OR",
+	"NOT",
+	"PHRASE",
+	"PREFIX",
+	"PRECEDENCE",
+	"ESCAPE",
+	"WHITESPACE",
+	"FUZZY",
+	"NEAR",
+	"SLOP",
+	"NONE",
+	"ALL",
+}
+
+// String returns a string representation of the flags, as known to
+// ElasticSearch (e.g. "AND|OR|PREFIX").
+func (a SimpleQueryStringFlag) String() string {
+	var names []string
+	for i, name := range simpleQueryStringFlagNames {
+		if a&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
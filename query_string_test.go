@@ -0,0 +1,116 @@
+package esquery
+
+import "testing"
+
+func TestQueryString(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"query_string: simple",
+			QueryString("(new york city) OR (big apple)").DefaultField("content"),
+			map[string]interface{}{
+				"query_string": map[string]interface{}{
+					"query":         "(new york city) OR (big apple)",
+					"default_field": "content",
+				},
+			},
+		},
+		{
+			"query_string: all params",
+			QueryString("status:open AND title:rce*").
+				BoostedFields(FieldBoost("title", 3)).
+				Fields("body").
+				Type(MatchTypeCrossFields).
+				AllowLeadingWildcard(false).
+				AnalyzeWildcard(true).
+				Analyzer("standard").
+				AutoGenerateSynonymsPhraseQuery(false).
+				Boost(1.5).
+				DefaultOperator(OperatorAnd).
+				EnablePositionIncrements(true).
+				Fuzziness("AUTO").
+				FuzzyMaxExpansions(10).
+				FuzzyPrefixLength(1).
+				FuzzyTranspositions(true).
+				Lenient(true).
+				MaxDeterminizedStates(5000).
+				MinimumShouldMatch("2").
+				QuoteAnalyzer("whitespace").
+				PhraseSlop(3).
+				QuoteFieldSuffix(".exact").
+				Rewrite("constant_score").
+				TieBreaker(0.3).
+				TimeZone("Asia/Jerusalem"),
+			map[string]interface{}{
+				"query_string": map[string]interface{}{
+					"query":                               "status:open AND title:rce*",
+					"fields":                              []string{"title^3", "body"},
+					"type":                                "cross_fields",
+					"allow_leading_wildcard":              false,
+					"analyze_wildcard":                    true,
+					"analyzer":                            "standard",
+					"auto_generate_synonyms_phrase_query": false,
+					"boost":                               1.5,
+					"default_operator":                    "AND",
+					"enable_position_increments":          true,
+					"fuzziness":                           "AUTO",
+					"fuzzy_max_expansions":                10,
+					"fuzzy_prefix_length":                 1,
+					"fuzzy_transpositions":                true,
+					"lenient":                             true,
+					"max_determinized_states":             5000,
+					"minimum_should_match":                "2",
+					"quote_analyzer":                      "whitespace",
+					"phrase_slop":                         3,
+					"quote_field_suffix":                  ".exact",
+					"rewrite":                             "constant_score",
+					"tie_breaker":                         0.3,
+					"time_zone":                           "Asia/Jerusalem",
+				},
+			},
+		},
+		{
+			"simple_query_string: simple",
+			SimpleQueryString(`"fried eggs" +(eggplant | potato) -frittata`),
+			map[string]interface{}{
+				"simple_query_string": map[string]interface{}{
+					"query": `"fried eggs" +(eggplant | potato) -frittata`,
+				},
+			},
+		},
+		{
+			"simple_query_string: all params",
+			SimpleQueryString("foo | bar + baz*").
+				BoostedFields(FieldBoost("title", 5), FieldBoost("body", 0)).
+				DefaultOperator(OperatorAnd).
+				AnalyzeWildcard(true).
+				Analyzer("snowball").
+				AutoGenerateSynonymsPhraseQuery(true).
+				Boost(2).
+				Flags(SimpleFlagOr | SimpleFlagAnd | SimpleFlagPrefix).
+				FuzzyMaxExpansions(20).
+				FuzzyPrefixLength(2).
+				FuzzyTranspositions(false).
+				Lenient(true).
+				MinimumShouldMatch("75%").
+				QuoteFieldSuffix(".exact"),
+			map[string]interface{}{
+				"simple_query_string": map[string]interface{}{
+					"query":                               "foo | bar + baz*",
+					"fields":                              []string{"title^5", "body"},
+					"default_operator":                    "AND",
+					"analyze_wildcard":                    true,
+					"analyzer":                            "snowball",
+					"auto_generate_synonyms_phrase_query": true,
+					"boost":                               2,
+					"flags":                               "AND|OR|PREFIX",
+					"fuzzy_max_expansions":                20,
+					"fuzzy_prefix_length":                 2,
+					"fuzzy_transpositions":                false,
+					"lenient":                             true,
+					"minimum_should_match":                "75%",
+					"quote_field_suffix":                  ".exact",
+				},
+			},
+		},
+	})
+}
@@ -16,6 +16,12 @@ func Exists(field string) *ExistsQuery {
 	return &ExistsQuery{field}
 }
 
+// Clone returns a deep copy of the query.
+func (q *ExistsQuery) Clone() *ExistsQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *ExistsQuery) Map() map[string]interface{} {
@@ -24,6 +30,25 @@ func (q *ExistsQuery) Map() map[string]interface{} {
 	}
 }
 
+// writeJSON writes the query in the same way as Map, see EncodeJSON.
+func (q *ExistsQuery) writeJSON(e *encoder) {
+	e.openObject()
+	e.key("exists")
+	e.openObject()
+	e.key("field")
+	e.string(q.Field)
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks that the query has a field name, implementing the Validator
+// interface.
+func (q *ExistsQuery) Validate() error {
+	var v validation
+	v.field("/exists/field", q.Field)
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // IDsQuery represents a query of type "ids", as described in:
@@ -43,12 +68,27 @@ func IDs(vals ...string) *IDsQuery {
 	return q
 }
 
+// Clone returns a deep copy of the query.
+func (q *IDsQuery) Clone() *IDsQuery {
+	c := *q
+	c.IDs.Values = cloneStrings(q.IDs.Values)
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *IDsQuery) Map() map[string]interface{} {
 	return structs.Map(q)
 }
 
+// Validate checks that the query has at least one ID, implementing the
+// Validator interface.
+func (q *IDsQuery) Validate() error {
+	var v validation
+	v.check(len(q.IDs.Values) > 0, "/ids/values", "at least one ID is required")
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // PrefixQuery represents query of type "prefix", as described in:
@@ -81,6 +121,22 @@ func (q *PrefixQuery) Rewrite(s string) *PrefixQuery {
 	return q
 }
 
+// GetField returns the name of the field the query applies to.
+func (q *PrefixQuery) GetField() string {
+	return q.field
+}
+
+// GetValue returns the prefix the query looks for.
+func (q *PrefixQuery) GetValue() string {
+	return q.params.Value
+}
+
+// Clone returns a deep copy of the query.
+func (q *PrefixQuery) Clone() *PrefixQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *PrefixQuery) Map() map[string]interface{} {
@@ -91,6 +147,14 @@ func (q *PrefixQuery) Map() map[string]interface{} {
 	}
 }
 
+// Validate checks that the query has a field name, implementing the Validator
+// interface.
+func (q *PrefixQuery) Validate() error {
+	var v validation
+	v.field("/prefix", q.field)
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // RangeQuery represents a query of type "range", as described in:
@@ -111,6 +175,37 @@ type rangeQueryParams struct {
 	Boost    float32       `structs:"boost,omitempty"`
 }
 
+// toMap returns a map representation of the parameters, as generated by the
+// structs package from their tags, without using reflection.
+func (params rangeQueryParams) toMap() map[string]interface{} {
+	m := make(map[string]interface{}, 2)
+	if params.Gt != nil {
+		m["gt"] = structValue(params.Gt)
+	}
+	if params.Gte != nil {
+		m["gte"] = structValue(params.Gte)
+	}
+	if params.Lt != nil {
+		m["lt"] = structValue(params.Lt)
+	}
+	if params.Lte != nil {
+		m["lte"] = structValue(params.Lte)
+	}
+	if params.Format != "" {
+		m["format"] = params.Format
+	}
+	if params.Relation != 0 {
+		m["relation"] = params.Relation.String()
+	}
+	if params.TimeZone != "" {
+		m["time_zone"] = params.TimeZone
+	}
+	if params.Boost != 0 {
+		m["boost"] = params.Boost
+	}
+	return m
+}
+
 // Range creates a new query of type "range" on the provided field
 func Range(field string) *RangeQuery {
 	return &RangeQuery{field: field}
@@ -166,16 +261,39 @@ func (a *RangeQuery) Boost(b float32) *RangeQuery {
 	return a
 }
 
+// GetField returns the name of the field the query applies to.
+func (a *RangeQuery) GetField() string {
+	return a.field
+}
+
+// Clone returns a deep copy of the query.
+func (a *RangeQuery) Clone() *RangeQuery {
+	c := *a
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (a *RangeQuery) Map() map[string]interface{} {
 	return map[string]interface{}{
 		"range": map[string]interface{}{
-			a.field: structs.Map(a.params),
+			a.field: a.params.toMap(),
 		},
 	}
 }
 
+// Validate checks that the query has a field name and at least one bound,
+// implementing the Validator interface.
+func (a *RangeQuery) Validate() error {
+	var v validation
+	v.field("/range", a.field)
+	v.check(
+		a.params.Gt != nil || a.params.Gte != nil || a.params.Lt != nil || a.params.Lte != nil,
+		jsonPath("range", a.field), "at least one bound is required",
+	)
+	return v.err()
+}
+
 // RangeRelation is an enumeration type for a range query's "relation" field
 type RangeRelation uint8
 
@@ -264,22 +382,54 @@ func (q *RegexpQuery) Rewrite(r string) *RegexpQuery {
 	return q
 }
 
+// GetField returns the name of the field the query applies to.
+func (q *RegexpQuery) GetField() string {
+	return q.field
+}
+
+// GetValue returns the regular expression or wildcard pattern of the query.
+func (q *RegexpQuery) GetValue() string {
+	return q.params.Value
+}
+
+// IsWildcard returns whether the query is of type "wildcard" rather than
+// "regexp".
+func (q *RegexpQuery) IsWildcard() bool {
+	return q.wildcard
+}
+
+// Clone returns a deep copy of the query.
+func (q *RegexpQuery) Clone() *RegexpQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *RegexpQuery) Map() map[string]interface{} {
-	var qType string
-	if q.wildcard {
-		qType = "wildcard"
-	} else {
-		qType = "regexp"
-	}
 	return map[string]interface{}{
-		qType: map[string]interface{}{
+		q.typeName(): map[string]interface{}{
 			q.field: structs.Map(q.params),
 		},
 	}
 }
 
+// typeName returns the name of the query's type, as known to ElasticSearch.
+func (q *RegexpQuery) typeName() string {
+	if q.wildcard {
+		return "wildcard"
+	}
+	return "regexp"
+}
+
+// Validate checks that the query has a field name, implementing the Validator
+// interface.
+func (q *RegexpQuery) Validate() error {
+	var v validation
+	v.field(jsonPath(q.typeName()), q.field)
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // Wildcard creates a new query of type "wildcard" on the provided field and
@@ -315,6 +465,30 @@ type fuzzyQueryParams struct {
 	Rewrite        string `structs:"rewrite,omitempty"`
 }
 
+// toMap returns a map representation of the parameters, as generated by the
+// structs package from their tags, without using reflection.
+func (params fuzzyQueryParams) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"value": params.Value,
+	}
+	if params.Fuzziness != "" {
+		m["fuzziness"] = params.Fuzziness
+	}
+	if params.MaxExpansions != 0 {
+		m["max_expansions"] = params.MaxExpansions
+	}
+	if params.PrefixLength != 0 {
+		m["prefix_length"] = params.PrefixLength
+	}
+	if params.Transpositions != nil {
+		m["transpositions"] = params.Transpositions
+	}
+	if params.Rewrite != "" {
+		m["rewrite"] = params.Rewrite
+	}
+	return m
+}
+
 // Fuzzy creates a new query of type "fuzzy" on the provided field and using
 // the provided value
 func Fuzzy(field, value string) *FuzzyQuery {
@@ -364,16 +538,35 @@ func (q *FuzzyQuery) Rewrite(s string) *FuzzyQuery {
 	return q
 }
 
+// GetField returns the name of the field the query applies to.
+func (q *FuzzyQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q *FuzzyQuery) Clone() *FuzzyQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *FuzzyQuery) Map() map[string]interface{} {
 	return map[string]interface{}{
 		"fuzzy": map[string]interface{}{
-			q.field: structs.Map(q.params),
+			q.field: q.params.toMap(),
 		},
 	}
 }
 
+// Validate checks that the query has a field name, implementing the Validator
+// interface.
+func (q *FuzzyQuery) Validate() error {
+	var v validation
+	v.field("/fuzzy", q.field)
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // TermQuery represents a query of type "term", as described in:
@@ -411,6 +604,22 @@ func (q *TermQuery) Boost(b float32) *TermQuery {
 	return q
 }
 
+// GetField returns the name of the field the query applies to.
+func (q *TermQuery) GetField() string {
+	return q.field
+}
+
+// GetValue returns the value the query looks for.
+func (q *TermQuery) GetValue() interface{} {
+	return q.params.Value
+}
+
+// Clone returns a deep copy of the query.
+func (q *TermQuery) Clone() *TermQuery {
+	c := *q
+	return &c
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q *TermQuery) Map() map[string]interface{} {
@@ -421,6 +630,38 @@ func (q *TermQuery) Map() map[string]interface{} {
 	}
 }
 
+// writeJSON writes the query in the same way as Map, see EncodeJSON.
+func (q *TermQuery) writeJSON(e *encoder) {
+	if !isScalar(q.params.Value) {
+		e.mapValue(q.Map())
+		return
+	}
+
+	e.openObject()
+	e.key("term")
+	e.openObject()
+	e.stringKey(q.field)
+	e.openObject()
+	if q.params.Boost != 0 {
+		e.key("boost")
+		e.float(float64(q.params.Boost), 32)
+	}
+	e.key("value")
+	e.value(q.params.Value)
+	e.closeObject()
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks that the query has a field name and a value, implementing
+// the Validator interface.
+func (q *TermQuery) Validate() error {
+	var v validation
+	v.field("/term", q.field)
+	v.check(q.params.Value != nil, jsonPath("term", q.field, "value"), "is required")
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // TermsQuery represents a query of type "terms", as described in:
@@ -452,6 +693,22 @@ func (q *TermsQuery) Boost(b float32) *TermsQuery {
 	return q
 }
 
+// GetField returns the name of the field the query applies to.
+func (q TermsQuery) GetField() string {
+	return q.field
+}
+
+// GetValues returns the values the query looks for.
+func (q TermsQuery) GetValues() []interface{} {
+	return q.values
+}
+
+// Clone returns a deep copy of the query.
+func (q TermsQuery) Clone() *TermsQuery {
+	q.values = cloneValues(q.values)
+	return &q
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q TermsQuery) Map() map[string]interface{} {
@@ -463,6 +720,40 @@ func (q TermsQuery) Map() map[string]interface{} {
 	return map[string]interface{}{"terms": innerMap}
 }
 
+// writeJSON writes the query in the same way as Map, see EncodeJSON.
+func (q TermsQuery) writeJSON(e *encoder) {
+	boost := q.boost > 0
+	if boost && q.field == "boost" {
+		e.mapValue(q.Map())
+		return
+	}
+
+	e.openObject()
+	e.key("terms")
+	e.openObject()
+	if boost && "boost" < q.field {
+		e.key("boost")
+		e.float(float64(q.boost), 32)
+	}
+	e.stringKey(q.field)
+	e.value(q.values)
+	if boost && q.field < "boost" {
+		e.key("boost")
+		e.float(float64(q.boost), 32)
+	}
+	e.closeObject()
+	e.closeObject()
+}
+
+// Validate checks that the query has a field name and at least one value,
+// implementing the Validator interface.
+func (q TermsQuery) Validate() error {
+	var v validation
+	v.field("/terms", q.field)
+	v.check(len(q.values) > 0, jsonPath("terms", q.field), "at least one value is required")
+	return v.err()
+}
+
 //----------------------------------------------------------------------------//
 
 // TermsSetQuery represents a query of type "terms_set", as described in:
@@ -475,7 +766,7 @@ type TermsSetQuery struct {
 type termsSetQueryParams struct {
 	Terms                    []string `structs:"terms"`
 	MinimumShouldMatchField  string   `structs:"minimum_should_match_field,omitempty"`
-	MinimumShouldMatchScript string   `structs:"minimum_should_match_script,omitempty"`
+	MinimumShouldMatchScript *Script  `structs:"-"`
 }
 
 // TermsSet creates a new query of type "terms_set" on the provided field and
@@ -502,19 +793,45 @@ func (q *TermsSetQuery) MinimumShouldMatchField(field string) *TermsSetQuery {
 	return q
 }
 
-// MinimumShouldMatchScript sets the custom script containing the number of
+// MinimumShouldMatchScript sets the custom script computing the number of
 // matching terms required to return a document.
-func (q *TermsSetQuery) MinimumShouldMatchScript(script string) *TermsSetQuery {
+func (q *TermsSetQuery) MinimumShouldMatchScript(script *Script) *TermsSetQuery {
 	q.params.MinimumShouldMatchScript = script
 	return q
 }
 
+// GetField returns the name of the field the query applies to.
+func (q TermsSetQuery) GetField() string {
+	return q.field
+}
+
+// Clone returns a deep copy of the query.
+func (q TermsSetQuery) Clone() *TermsSetQuery {
+	q.params.Terms = cloneStrings(q.params.Terms)
+	q.params.MinimumShouldMatchScript = cloneScript(q.params.MinimumShouldMatchScript)
+	return &q
+}
+
 // Map returns a map representation of the query, thus implementing the
 // Mappable interface.
 func (q TermsSetQuery) Map() map[string]interface{} {
+	params := structs.Map(q.params)
+	if q.params.MinimumShouldMatchScript != nil {
+		params["minimum_should_match_script"] = q.params.MinimumShouldMatchScript.Map()
+	}
 	return map[string]interface{}{
 		"terms_set": map[string]interface{}{
-			q.field: structs.Map(q.params),
+			q.field: params,
 		},
 	}
 }
+
+// Validate checks that the query has a field name, at least one term and a
+// valid script if any, implementing the Validator interface.
+func (q TermsSetQuery) Validate() error {
+	var v validation
+	v.field("/terms_set", q.field)
+	v.check(len(q.params.Terms) > 0, jsonPath("terms_set", q.field, "terms"), "at least one term is required")
+	v.optional(jsonPath("terms_set", q.field, "minimum_should_match_script"), q.params.MinimumShouldMatchScript)
+	return v.err()
+}
@@ -147,5 +147,20 @@ func TestTermLevel(t *testing.T) {
 				},
 			},
 		},
+		{
+			"terms_set with script",
+			TermsSet("programming_languages", "go", "rust").
+				MinimumShouldMatchScript(InlineScript("Math.min(params.num_terms, doc['required_matches'].value)")),
+			map[string]interface{}{
+				"terms_set": map[string]interface{}{
+					"programming_languages": map[string]interface{}{
+						"terms": []string{"go", "rust"},
+						"minimum_should_match_script": map[string]interface{}{
+							"source": "Math.min(params.num_terms, doc['required_matches'].value)",
+						},
+					},
+				},
+			},
+		},
 	})
 }
@@ -0,0 +1,565 @@
+package esquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Schema represents the fields of one or more indices, as defined by their
+// mappings. It is used to check queries and aggregations against the fields
+// they reference, without access to an ElasticSearch cluster (e.g. in unit
+// tests), see Check.
+type Schema struct {
+	fields map[string]*FieldMapping
+}
+
+// FieldMapping describes a single mapped field.
+type FieldMapping struct {
+	// Name is the full path of the field, e.g. "user.name" or
+	// "title.keyword" for a multi-field.
+	Name string
+
+	// Type is the mapping type of the field, e.g. "keyword", "text" or
+	// "nested". Objects without an explicit type have type "object". Aliases
+	// have the type of the field they point to.
+	Type string
+
+	// NestedPath is the path of the closest enclosing field of type "nested",
+	// or an empty string if the field is not inside a nested field.
+	NestedPath string
+
+	// Aggregatable is true if the field can be used in aggregations (and for
+	// sorting), i.e. if it has doc values or, for text fields, fielddata.
+	Aggregatable bool
+}
+
+// LoadSchema reads index mappings in JSON format from the provided reader, see
+// ParseSchema.
+func LoadSchema(r io.Reader) (*Schema, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(data)
+}
+
+// ParseSchema creates a schema from index mappings in JSON format. It accepts
+// the response of the get mapping API (GET <index>/_mapping), in which case
+// the mappings of all returned indices are merged, the body of a create index
+// request ({"mappings": {...}}), or a bare mapping ({"properties": {...}}).
+// Runtime fields are supported as well.
+func ParseSchema(mapping []byte) (*Schema, error) {
+	var root map[string]json.RawMessage
+	err := json.Unmarshal(mapping, &root)
+	if err != nil {
+		return nil, fmt.Errorf("esquery: invalid mapping: %w", err)
+	}
+
+	s := &Schema{fields: make(map[string]*FieldMapping)}
+
+	switch {
+	case root["properties"] != nil || root["runtime"] != nil:
+		err = s.addMapping(mapping)
+	case root["mappings"] != nil:
+		err = s.addMapping(root["mappings"])
+	default:
+		indices := make([]string, 0, len(root))
+		for index := range root {
+			indices = append(indices, index)
+		}
+		sort.Strings(indices)
+
+		for _, index := range indices {
+			var body struct {
+				Mappings json.RawMessage `json:"mappings"`
+			}
+			err = json.Unmarshal(root[index], &body)
+			if err != nil || body.Mappings == nil {
+				return nil, fmt.Errorf("esquery: invalid mapping: no mappings found for %q", index)
+			}
+			err = s.addMapping(body.Mappings)
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// fieldDef is the definition of a field in a mapping.
+type fieldDef struct {
+	Type       string              `json:"type"`
+	Path       string              `json:"path"`
+	DocValues  *bool               `json:"doc_values"`
+	Fielddata  bool                `json:"fielddata"`
+	Properties map[string]fieldDef `json:"properties"`
+	Fields     map[string]fieldDef `json:"fields"`
+}
+
+func (s *Schema) addMapping(data []byte) error {
+	var mapping struct {
+		Properties map[string]fieldDef `json:"properties"`
+		Runtime    map[string]fieldDef `json:"runtime"`
+	}
+	err := json.Unmarshal(data, &mapping)
+	if err != nil {
+		return fmt.Errorf("esquery: invalid mapping: %w", err)
+	}
+
+	aliases := make(map[string]string)
+	err = s.addProperties("", "", mapping.Properties, aliases)
+	if err != nil {
+		return err
+	}
+	for name, def := range mapping.Runtime {
+		err = s.add(&FieldMapping{Name: name, Type: def.Type, Aggregatable: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, target := range aliases {
+		f, ok := s.fields[target]
+		if !ok {
+			return fmt.Errorf("esquery: invalid mapping: alias %q points to unknown field %q", name, target)
+		}
+		err = s.add(&FieldMapping{
+			Name:         name,
+			Type:         f.Type,
+			NestedPath:   f.NestedPath,
+			Aggregatable: f.Aggregatable,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) addProperties(
+	prefix, nestedPath string,
+	props map[string]fieldDef,
+	aliases map[string]string,
+) error {
+	for name, def := range props {
+		name = prefix + name
+		typ := def.Type
+		if typ == "" {
+			typ = "object"
+		}
+		if typ == "alias" {
+			aliases[name] = def.Path
+			continue
+		}
+
+		err := s.add(&FieldMapping{
+			Name:         name,
+			Type:         typ,
+			NestedPath:   nestedPath,
+			Aggregatable: isAggregatable(typ, def),
+		})
+		if err != nil {
+			return err
+		}
+
+		for sub, subDef := range def.Fields {
+			subType := subDef.Type
+			err = s.add(&FieldMapping{
+				Name:         name + "." + sub,
+				Type:         subType,
+				NestedPath:   nestedPath,
+				Aggregatable: isAggregatable(subType, subDef),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		childNestedPath := nestedPath
+		if typ == "nested" {
+			childNestedPath = name
+		}
+		err = s.addProperties(name+".", childNestedPath, def.Properties, aliases)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) add(f *FieldMapping) error {
+	if existing, ok := s.fields[f.Name]; ok && existing.Type != f.Type {
+		return fmt.Errorf(
+			"esquery: invalid mapping: field %q has conflicting types %q and %q",
+			f.Name, existing.Type, f.Type,
+		)
+	}
+	s.fields[f.Name] = f
+	return nil
+}
+
+func isAggregatable(typ string, def fieldDef) bool {
+	switch typ {
+	case "text", "match_only_text", "annotated_text":
+		return def.Fielddata
+	case "object", "nested", "search_as_you_type", "completion", "percolator":
+		return false
+	case "binary":
+		return def.DocValues != nil && *def.DocValues
+	default:
+		return def.DocValues == nil || *def.DocValues
+	}
+}
+
+// Field returns the mapping of the field with the provided full path, or nil
+// if the field is not mapped.
+func (s *Schema) Field(name string) *FieldMapping {
+	return s.fields[name]
+}
+
+// Fields returns the mappings of all fields in the schema, sorted by name.
+// Parents (objects, nested fields and fields with multi-fields) are thus
+// always listed before their children.
+func (s *Schema) Fields() []*FieldMapping {
+	fields := make([]*FieldMapping, 0, len(s.fields))
+	for _, f := range s.fields {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+// Check checks the provided query, aggregation or request against the schema.
+// It reports fields that are not mapped, queries that do not suit the type of
+// their field (e.g. a term query on a text field, or a range query on a
+// keyword field), nested fields used outside of a nested query or
+// aggregation on their path (and vice versa), nested queries and
+// aggregations whose path is not of type "nested", and aggregations on
+// fields that are not aggregatable.
+//
+// The value is validated first, see Validate. Problems are returned as
+// ValidationErrors, with paths relative to the map generated by the value.
+// Fields whose names start with an underscore (metadata fields) or contain
+// wildcards are not checked.
+func (s *Schema) Check(m Mappable) error {
+	err := Validate(m)
+	if err != nil {
+		return err
+	}
+
+	// round-trip through JSON so that the generated map only contains
+	// generic maps and slices, regardless of how it was built
+	data, err := json.Marshal(m.Map())
+	if err != nil {
+		return err
+	}
+	var generic map[string]interface{}
+	err = json.Unmarshal(data, &generic)
+	if err != nil {
+		return err
+	}
+
+	c := schemaCheck{schema: s}
+	switch m.(type) {
+	case Aggregation:
+		c.agg("", generic, "")
+	case *SearchRequest, *CountRequest, *UpdateRequest:
+		c.query("/query", asObject(generic["query"]), "")
+		c.query("/post_filter", asObject(generic["post_filter"]), "")
+		c.aggs("/aggs", asObject(generic["aggs"]), "")
+	default:
+		c.query("", generic, "")
+	}
+	return c.v.err()
+}
+
+// schemaCheck walks generic query and aggregation maps, recording problems
+// found against a schema.
+type schemaCheck struct {
+	schema *Schema
+	v      validation
+}
+
+// field checks that the provided field is mapped and can be used in the
+// provided nested scope, returning its mapping or nil.
+func (c *schemaCheck) field(path, name, scope, kind string) *FieldMapping {
+	if name == "" || strings.HasPrefix(name, "_") || strings.ContainsAny(name, "*?") {
+		return nil
+	}
+
+	f := c.schema.Field(name)
+	if f == nil {
+		c.v.errorf(path, "field %q is not mapped", name)
+		return nil
+	}
+
+	switch {
+	case f.NestedPath == scope:
+	case f.NestedPath == "":
+		c.v.errorf(path, "field %q is not inside nested path %q", name, scope)
+	case scope == "":
+		c.v.errorf(path, "field %q is inside nested path %q and requires a nested %s", name, f.NestedPath, kind)
+	default:
+		c.v.errorf(path, "field %q is inside nested path %q, not %q", name, f.NestedPath, scope)
+	}
+
+	return f
+}
+
+// nestedPath checks that the provided path is mapped as a nested field. It
+// returns the scope to check the nested clauses in: the path if it is valid,
+// or the current scope otherwise, so that the error is not repeated for every
+// nested clause.
+func (c *schemaCheck) nestedPath(path, name, scope string) string {
+	f := c.schema.Field(name)
+	switch {
+	case f == nil:
+		c.v.errorf(path, "field %q is not mapped", name)
+	case f.Type != "nested":
+		c.v.errorf(path, "field %q is of type %q, not nested", name, f.Type)
+	default:
+		return name
+	}
+	return scope
+}
+
+func (c *schemaCheck) query(path string, q map[string]interface{}, scope string) {
+	for _, typ := range sortedKeys(q) {
+		params := asObject(q[typ])
+		if params == nil {
+			continue
+		}
+		base := path + jsonPath(typ)
+
+		switch typ {
+		case "bool":
+			for _, clause := range []string{"must", "filter", "should", "must_not"} {
+				c.queries(base+jsonPath(clause), params[clause], scope)
+			}
+		case "boosting":
+			c.query(base+"/positive", asObject(params["positive"]), scope)
+			c.query(base+"/negative", asObject(params["negative"]), scope)
+		case "constant_score":
+			c.query(base+"/filter", asObject(params["filter"]), scope)
+		case "dis_max":
+			c.queries(base+"/queries", params["queries"], scope)
+		case "function_score":
+			c.query(base+"/query", asObject(params["query"]), scope)
+			for i, f := range asList(params["functions"]) {
+				c.query(base+jsonPath("functions", i, "filter"), asObject(asObject(f)["filter"]), scope)
+			}
+		case "script_score":
+			c.query(base+"/query", asObject(params["query"]), scope)
+		case "pinned":
+			c.query(base+"/organic", asObject(params["organic"]), scope)
+		case "nested":
+			nested, _ := params["path"].(string)
+			nested = c.nestedPath(base+"/path", nested, scope)
+			c.query(base+"/query", asObject(params["query"]), nested)
+		case "span_near", "span_or":
+			c.queries(base+"/clauses", params["clauses"], scope)
+		case "span_not":
+			c.query(base+"/include", asObject(params["include"]), scope)
+			c.query(base+"/exclude", asObject(params["exclude"]), scope)
+		case "span_first", "span_multi":
+			c.query(base+"/match", asObject(params["match"]), scope)
+		case "span_containing", "span_within":
+			c.query(base+"/big", asObject(params["big"]), scope)
+			c.query(base+"/little", asObject(params["little"]), scope)
+		case "field_masking_span":
+			c.query(base+"/query", asObject(params["query"]), scope)
+		case "term", "terms", "terms_set":
+			for _, name := range fieldKeys(params) {
+				f := c.field(base+jsonPath(name), name, scope, "query")
+				if f != nil && isTextType(f.Type) {
+					c.v.errorf(
+						base+jsonPath(name),
+						"%s query on field %q of type %q, use a match query or a keyword field",
+						typ, name, f.Type,
+					)
+				}
+			}
+		case "range":
+			for _, name := range fieldKeys(params) {
+				f := c.field(base+jsonPath(name), name, scope, "query")
+				if f != nil && !isRangeType(f.Type) {
+					c.v.errorf(base+jsonPath(name), "range query on field %q of type %q", name, f.Type)
+				}
+			}
+		case "match", "match_phrase", "match_phrase_prefix", "match_bool_prefix",
+			"prefix", "wildcard", "regexp", "fuzzy", "span_term", "intervals":
+			for _, name := range fieldKeys(params) {
+				c.field(base+jsonPath(name), name, scope, "query")
+			}
+		case "exists":
+			name, _ := params["field"].(string)
+			c.field(base+"/field", name, scope, "query")
+		case "multi_match", "query_string", "simple_query_string", "combined_fields", "more_like_this":
+			for i, name := range asList(params["fields"]) {
+				name, _ := name.(string)
+				if j := strings.IndexByte(name, '^'); j >= 0 {
+					name = name[:j]
+				}
+				c.field(base+jsonPath("fields", i), name, scope, "query")
+			}
+			if name, ok := params["default_field"].(string); ok {
+				c.field(base+"/default_field", name, scope, "query")
+			}
+		case "distance_feature":
+			c.fieldOfType(base+"/field", params, scope, "date", "date_nanos", "geo_point")
+		case "rank_feature":
+			name, _ := params["field"].(string)
+			if i := strings.LastIndexByte(name, '.'); i >= 0 && c.schema.Field(name) == nil {
+				// features of a rank_features field are not mapped
+				// individually
+				if parent := c.schema.Field(name[:i]); parent != nil && parent.Type == "rank_features" {
+					c.field(base+"/field", name[:i], scope, "query")
+					continue
+				}
+			}
+			c.fieldOfType(base+"/field", params, scope, "rank_feature", "rank_features")
+		case "percolate":
+			c.fieldOfType(base+"/field", params, scope, "percolator")
+		}
+	}
+}
+
+// fieldOfType checks the field referenced by the "field" parameter of a query,
+// which must be of one of the provided types.
+func (c *schemaCheck) fieldOfType(path string, params map[string]interface{}, scope string, types ...string) {
+	name, _ := params["field"].(string)
+	f := c.field(path, name, scope, "query")
+	if f == nil {
+		return
+	}
+	for _, typ := range types {
+		if f.Type == typ {
+			return
+		}
+	}
+	c.v.errorf(path, "field %q is of type %q, expected %s", name, f.Type, strings.Join(types, " or "))
+}
+
+// queries checks a list of queries, or a single query.
+func (c *schemaCheck) queries(path string, val interface{}, scope string) {
+	if q := asObject(val); q != nil {
+		c.query(path, q, scope)
+		return
+	}
+	for i, q := range asList(val) {
+		c.query(path+jsonPath(i), asObject(q), scope)
+	}
+}
+
+func (c *schemaCheck) aggs(path string, aggs map[string]interface{}, scope string) {
+	for _, name := range sortedKeys(aggs) {
+		c.agg(path+jsonPath(name), asObject(aggs[name]), scope)
+	}
+}
+
+func (c *schemaCheck) agg(path string, agg map[string]interface{}, scope string) {
+	subScope := scope
+	for _, typ := range sortedKeys(agg) {
+		params := asObject(agg[typ])
+		base := path + jsonPath(typ)
+
+		switch typ {
+		case "aggs", "aggregations", "meta":
+			continue
+		case "nested":
+			nested, _ := params["path"].(string)
+			subScope = c.nestedPath(base+"/path", nested, scope)
+		case "reverse_nested":
+			subScope, _ = params["path"].(string)
+			if subScope != "" {
+				subScope = c.nestedPath(base+"/path", subScope, "")
+			}
+		case "filter":
+			c.query(base, params, scope)
+		case "weighted_avg":
+			for _, part := range []string{"value", "weight"} {
+				name, _ := asObject(params[part])["field"].(string)
+				c.aggField(base+jsonPath(part, "field"), name, scope)
+			}
+		default:
+			if name, ok := params["field"].(string); ok {
+				c.aggField(base+"/field", name, scope)
+			}
+		}
+	}
+
+	for _, key := range []string{"aggs", "aggregations"} {
+		c.aggs(path+jsonPath(key), asObject(agg[key]), subScope)
+	}
+}
+
+// aggField checks that the field of an aggregation is mapped and aggregatable.
+func (c *schemaCheck) aggField(path, name, scope string) {
+	f := c.field(path, name, scope, "aggregation")
+	if f != nil && !f.Aggregatable {
+		c.v.errorf(path, "field %q of type %q is not aggregatable", name, f.Type)
+	}
+}
+
+func isTextType(typ string) bool {
+	switch typ {
+	case "text", "match_only_text", "annotated_text", "search_as_you_type":
+		return true
+	}
+	return false
+}
+
+func isRangeType(typ string) bool {
+	switch typ {
+	case "long", "integer", "short", "byte", "double", "float", "half_float",
+		"scaled_float", "unsigned_long", "date", "date_nanos", "ip", "version",
+		"integer_range", "float_range", "long_range", "double_range",
+		"date_range", "ip_range":
+		return true
+	}
+	return false
+}
+
+// fieldKeys returns the keys of a field-keyed query's parameters (e.g. the
+// parameters of a term query), excluding shared options such as "boost".
+func fieldKeys(params map[string]interface{}) []string {
+	keys := sortedKeys(params)
+	names := keys[:0]
+	for _, key := range keys {
+		if key != "boost" && key != "_name" {
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func asObject(val interface{}) map[string]interface{} {
+	m, _ := val.(map[string]interface{})
+	return m
+}
+
+func asList(val interface{}) []interface{} {
+	l, _ := val.([]interface{})
+	return l
+}
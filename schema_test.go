@@ -0,0 +1,211 @@
+package esquery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMapping = `{
+	"findings": {
+		"mappings": {
+			"properties": {
+				"title": {
+					"type": "text",
+					"fields": {
+						"keyword": {"type": "keyword"}
+					}
+				},
+				"status": {"type": "keyword"},
+				"score": {"type": "float"},
+				"created": {"type": "date"},
+				"description": {"type": "text"},
+				"raw": {"type": "keyword", "doc_values": false},
+				"severity": {"type": "alias", "path": "score"},
+				"asset": {
+					"properties": {
+						"name": {"type": "keyword"}
+					}
+				},
+				"packages": {
+					"type": "nested",
+					"properties": {
+						"name": {"type": "keyword"},
+						"version": {"type": "version"}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(testMapping))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected *FieldMapping
+	}{
+		{"title", &FieldMapping{"title", "text", "", false}},
+		{"title.keyword", &FieldMapping{"title.keyword", "keyword", "", true}},
+		{"raw", &FieldMapping{"raw", "keyword", "", false}},
+		{"severity", &FieldMapping{"severity", "float", "", true}},
+		{"asset", &FieldMapping{"asset", "object", "", false}},
+		{"asset.name", &FieldMapping{"asset.name", "keyword", "", true}},
+		{"packages", &FieldMapping{"packages", "nested", "", false}},
+		{"packages.name", &FieldMapping{"packages.name", "keyword", "packages", true}},
+		{"missing", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := s.Field(test.name)
+			if !reflect.DeepEqual(f, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, f)
+			}
+		})
+	}
+}
+
+func TestParseSchemaFormats(t *testing.T) {
+	for _, mapping := range []string{
+		`{"properties": {"status": {"type": "keyword"}}}`,
+		`{"mappings": {"properties": {"status": {"type": "keyword"}}}}`,
+		`{"a": {"mappings": {"properties": {"status": {"type": "keyword"}}}}}`,
+		`{"runtime": {"status": {"type": "keyword"}}}`,
+	} {
+		s, err := ParseSchema([]byte(mapping))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", mapping, err)
+		} else if s.Field("status") == nil {
+			t.Errorf("%s: field not found", mapping)
+		}
+	}
+
+	for _, mapping := range []string{
+		`not json`,
+		`{"a": {"settings": {}}}`,
+		`{"properties": {"a": {"type": "alias", "path": "b"}}}`,
+		`{
+			"a": {"mappings": {"properties": {"status": {"type": "keyword"}}}},
+			"b": {"mappings": {"properties": {"status": {"type": "text"}}}}
+		}`,
+	} {
+		if _, err := ParseSchema([]byte(mapping)); err == nil {
+			t.Errorf("%s: expected an error, got nil", mapping)
+		}
+	}
+}
+
+func TestSchemaCheck(t *testing.T) {
+	s, err := LoadSchema(strings.NewReader(testMapping))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name  string
+		value Mappable
+		paths []string
+	}{
+		{
+			"valid search request",
+			Search().
+				Query(Bool().
+					Must(
+						Match("title", "openssl"),
+						Term("status", "open"),
+						Range("severity").Gte(7),
+						Nested("packages", Term("packages.name", "openssl")),
+					).
+					Filter(Exists("asset.name"), IDs("a", "b"))).
+				Aggs(
+					TermsAgg("by_status", "status").Aggs(Avg("avg_score", "score")),
+					NestedAgg("packages", "packages").Aggs(TermsAgg("by_name", "packages.name")),
+				),
+			nil,
+		},
+		{
+			"unmapped field",
+			Match("titel", "openssl"),
+			[]string{"/match/titel"},
+		},
+		{
+			"term query on text field",
+			Bool().Filter(Term("title", "openssl"), Terms("title.keyword", "a")),
+			[]string{"/bool/filter/0/term/title"},
+		},
+		{
+			"range query on keyword field",
+			Range("status").Gte("a"),
+			[]string{"/range/status"},
+		},
+		{
+			"nested field outside nested query",
+			MultiMatch("openssl").Fields("title^2", "packages.name"),
+			[]string{"/multi_match/fields/1"},
+		},
+		{
+			"field outside nested path",
+			Nested("packages", Term("status", "open")),
+			[]string{"/nested/query/term/status"},
+		},
+		{
+			"nested query on object path",
+			Nested("asset", Term("asset.name", "server")),
+			[]string{"/nested/path"},
+		},
+		{
+			"non-aggregatable fields",
+			Search().Aggs(
+				TermsAgg("by_title", "title"),
+				Cardinality("raw_count", "raw"),
+			),
+			[]string{"/aggs/by_title/terms/field", "/aggs/raw_count/cardinality/field"},
+		},
+		{
+			"nested aggregation mismatch",
+			TermsAgg("by_name", "packages.name").Aggs(
+				NestedAgg("assets", "asset"),
+			),
+			[]string{"/terms/field", "/aggs/assets/nested/path"},
+		},
+		{
+			"filter aggregation",
+			FilterAgg("open", Term("title", "open")),
+			[]string{"/filter/term/title"},
+		},
+		{
+			"invalid query",
+			Range("score"),
+			[]string{"/range/score"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := s.Check(test.value)
+			if test.paths == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %#v", err)
+			}
+			paths := make([]string, len(errs))
+			for i, e := range errs {
+				paths[i] = e.Path
+			}
+			if !reflect.DeepEqual(paths, test.paths) {
+				t.Errorf("expected errors at %q, got %q (%s)", test.paths, paths, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// Script represents a script that can be provided to the various features of
+// ElasticSearch that support scripting, as described in
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/modules-scripting-using.html
+//
+// User input should always be passed to scripts as parameters rather than
+// concatenated into their source, both to prevent script injection and to
+// allow ElasticSearch to cache the compiled script. InlineScript only accepts
+// constant sources for this reason, see also Validate.
+type Script struct {
+	source  string
+	id      string
+	lang    string
+	params  map[string]interface{}
+	options map[string]string
+}
+
+// scriptSource is the type of the source code of inline scripts. As it is
+// unexported, only constant strings (and concatenations of constants) can be
+// passed to InlineScript, so that values known at runtime can only reach the
+// script through its parameters.
+type scriptSource string
+
+// InlineScript creates a new script with the provided source code, which must
+// be a constant. Values known at runtime, such as user input, must be passed
+// with Param and referenced as "params.<name>" in the source.
+func InlineScript(source scriptSource) *Script {
+	return &Script{
+		source: string(source),
+	}
+}
+
+// StoredScript creates a new script referencing the stored script with the
+// provided ID, see PutScript.
+func StoredScript(id string) *Script {
+	return &Script{
+		id: id,
+	}
+}
+
+// Lang sets the language the script is written in. ElasticSearch defaults to
+// "painless".
+func (s *Script) Lang(lang string) *Script {
+	s.lang = lang
+	return s
+}
+
+// Params sets the parameters passed to the script, replacing any parameters
+// set before.
+func (s *Script) Params(params map[string]interface{}) *Script {
+	s.params = params
+	return s
+}
+
+// Param sets a single parameter passed to the script.
+func (s *Script) Param(name string, value interface{}) *Script {
+	if s.params == nil {
+		s.params = make(map[string]interface{})
+	}
+	s.params[name] = value
+	return s
+}
+
+// Option sets a single option of the script's compiler, e.g.
+// "content_type" for mustache scripts.
+func (s *Script) Option(name, value string) *Script {
+	if s.options == nil {
+		s.options = make(map[string]string)
+	}
+	s.options[name] = value
+	return s
+}
+
+// Validate checks that the script is either inline or stored, and that none
+// of its string parameters were also concatenated into its source as a
+// quoted literal, which usually means that user input made its way into the
+// source instead of being referenced as "params.<name>".
+func (s *Script) Validate() error {
+	if (s.source == "") == (s.id == "") {
+		return fmt.Errorf("esquery: script must have either a source or an id")
+	}
+
+	names := make([]string, 0, len(s.params))
+	for name := range s.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := s.params[name].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if strings.Contains(s.source, "'"+value+"'") || strings.Contains(s.source, `"`+value+`"`) {
+			return fmt.Errorf(
+				"esquery: script source contains the value of parameter %q, reference it as params.%s instead",
+				name, name,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the script.
+func (s *Script) Clone() *Script {
+	c := *s
+	c.params = cloneMap(s.params)
+	if s.options != nil {
+		c.options = make(map[string]string, len(s.options))
+		for k, v := range s.options {
+			c.options[k] = v
+		}
+	}
+	return &c
+}
+
+// Map returns a map representation of the script, thus implementing the
+// Mappable interface.
+func (s *Script) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	if s.id != "" {
+		m["id"] = s.id
+	} else {
+		m["source"] = s.source
+	}
+	if s.lang != "" {
+		m["lang"] = s.lang
+	}
+	if len(s.params) > 0 {
+		m["params"] = s.params
+	}
+	if len(s.options) > 0 {
+		m["options"] = s.options
+	}
+	return m
+}
+
+//----------------------------------------------------------------------------//
+
+// PutScriptRequest represents a request to store a script, as described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/create-stored-script-api.html
+type PutScriptRequest struct {
+	id     string
+	script *Script
+}
+
+// PutScript creates a new request storing the provided inline script under
+// the provided ID. Stored scripts must specify their language, see Validate.
+func PutScript(id string, script *Script) *PutScriptRequest {
+	return &PutScriptRequest{
+		id:     id,
+		script: script,
+	}
+}
+
+// Clone returns a deep copy of the request.
+func (req *PutScriptRequest) Clone() *PutScriptRequest {
+	c := *req
+	c.script = cloneScript(req.script)
+	return &c
+}
+
+// Map returns a map representation of the request, thus implementing the
+// Mappable interface.
+func (req *PutScriptRequest) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"script": req.script.Map(),
+	}
+}
+
+// Validate checks that the request has an ID and that its script is a valid
+// inline script specifying its language, implementing the Validator
+// interface.
+func (req *PutScriptRequest) Validate() error {
+	var v validation
+	v.check(req.id != "", "", "stored script must have an id")
+	v.child("/script", req.script)
+	if req.script != nil {
+		v.check(req.script.id == "", "/script/id", "cannot store a script referencing a stored script")
+		v.check(req.script.lang != "", "/script/lang", "stored script must specify its language")
+	}
+	return v.err()
+}
+
+// Run executes the request using the provided ElasticSearch client. The
+// script is validated first, see Validate.
+func (req *PutScriptRequest) Run(
+	api *elasticsearch.Client,
+	o ...func(*esapi.PutScriptRequest),
+) (res *esapi.Response, err error) {
+	return req.RunPutScript(api.PutScript, o...)
+}
+
+// RunPutScript is the same as the Run method, except that it accepts a value
+// of type esapi.PutScript (usually this is the PutScript field of an
+// elasticsearch.Client object), allowing implementation of mock clients.
+func (req *PutScriptRequest) RunPutScript(
+	put esapi.PutScript,
+	o ...func(*esapi.PutScriptRequest),
+) (res *esapi.Response, err error) {
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = json.NewEncoder(&b).Encode(req.Map())
+	if err != nil {
+		return nil, err
+	}
+
+	return put(req.id, &b, o...)
+}
+
+// GetScriptRequest represents a request to retrieve a stored script, as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/get-stored-script-api.html
+type GetScriptRequest struct {
+	id string
+}
+
+// GetScript creates a new request retrieving the stored script with the
+// provided ID.
+func GetScript(id string) *GetScriptRequest {
+	return &GetScriptRequest{
+		id: id,
+	}
+}
+
+// Clone returns a deep copy of the request.
+func (req *GetScriptRequest) Clone() *GetScriptRequest {
+	c := *req
+	return &c
+}
+
+// Run executes the request using the provided ElasticSearch client.
+func (req *GetScriptRequest) Run(
+	api *elasticsearch.Client,
+	o ...func(*esapi.GetScriptRequest),
+) (res *esapi.Response, err error) {
+	return req.RunGetScript(api.GetScript, o...)
+}
+
+// RunGetScript is the same as the Run method, except that it accepts a value
+// of type esapi.GetScript (usually this is the GetScript field of an
+// elasticsearch.Client object), allowing implementation of mock clients.
+func (req *GetScriptRequest) RunGetScript(
+	get esapi.GetScript,
+	o ...func(*esapi.GetScriptRequest),
+) (res *esapi.Response, err error) {
+	return get(req.id, o...)
+}
+
+// DeleteScriptRequest represents a request to delete a stored script, as
+// described in:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/delete-stored-script-api.html
+type DeleteScriptRequest struct {
+	id string
+}
+
+// DeleteScript creates a new request deleting the stored script with the
+// provided ID.
+func DeleteScript(id string) *DeleteScriptRequest {
+	return &DeleteScriptRequest{
+		id: id,
+	}
+}
+
+// Clone returns a deep copy of the request.
+func (req *DeleteScriptRequest) Clone() *DeleteScriptRequest {
+	c := *req
+	return &c
+}
+
+// Run executes the request using the provided ElasticSearch client.
+func (req *DeleteScriptRequest) Run(
+	api *elasticsearch.Client,
+	o ...func(*esapi.DeleteScriptRequest),
+) (res *esapi.Response, err error) {
+	return req.RunDeleteScript(api.DeleteScript, o...)
+}
+
+// RunDeleteScript is the same as the Run method, except that it accepts a
+// value of type esapi.DeleteScript (usually this is the DeleteScript field of
+// an elasticsearch.Client object), allowing implementation of mock clients.
+func (req *DeleteScriptRequest) RunDeleteScript(
+	del esapi.DeleteScript,
+	o ...func(*esapi.DeleteScriptRequest),
+) (res *esapi.Response, err error) {
+	return del(req.id, o...)
+}
@@ -0,0 +1,174 @@
+package esquery
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+func TestScripts(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"inline script with all options",
+			InlineScript("{{#toJson}}terms{{/toJson}}").
+				Lang("mustache").
+				Param("terms", []string{"a", "b"}).
+				Option("content_type", "application/json"),
+			map[string]interface{}{
+				"source": "{{#toJson}}terms{{/toJson}}",
+				"lang":   "mustache",
+				"params": map[string]interface{}{
+					"terms": []string{"a", "b"},
+				},
+				"options": map[string]interface{}{
+					"content_type": "application/json",
+				},
+			},
+		},
+		{
+			"stored script",
+			StoredScript("calculate-score").Param("my_modifier", 2),
+			map[string]interface{}{
+				"id": "calculate-score",
+				"params": map[string]interface{}{
+					"my_modifier": 2,
+				},
+			},
+		},
+		{
+			"put script request",
+			PutScript("calculate-score", InlineScript("Math.log(_score * 2)").Lang("painless")),
+			map[string]interface{}{
+				"script": map[string]interface{}{
+					"source": "Math.log(_score * 2)",
+					"lang":   "painless",
+				},
+			},
+		},
+	})
+}
+
+func TestScriptValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		script *Script
+		valid  bool
+	}{
+		{
+			"parameterized script",
+			InlineScript("doc['user'].value == params.user").Param("user", "kimchy"),
+			true,
+		},
+		{
+			"stored script",
+			StoredScript("calculate-score").Param("user", "kimchy"),
+			true,
+		},
+		{
+			"non-string parameter",
+			InlineScript("doc['likes'].value > 10").Param("min", 10),
+			true,
+		},
+		{
+			"concatenated parameter",
+			InlineScript("doc['user'].value == 'kimchy'").Param("user", "kimchy"),
+			false,
+		},
+		{
+			"concatenated parameter in double quotes",
+			InlineScript(`doc['user'].value == "kimchy"`).Param("user", "kimchy"),
+			false,
+		},
+		{
+			"empty script",
+			InlineScript(""),
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.script.Validate()
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestPutScriptValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   *PutScriptRequest
+		paths []string
+	}{
+		{
+			"valid request",
+			PutScript("calculate-score", InlineScript("Math.log(_score * 2)").Lang("painless")),
+			nil,
+		},
+		{
+			"missing id and language",
+			PutScript("", InlineScript("Math.log(_score * 2)")),
+			[]string{"", "/script/lang"},
+		},
+		{
+			"stored script",
+			PutScript("calculate-score", StoredScript("other").Lang("painless")),
+			[]string{"/script/id"},
+		},
+		{
+			"missing script",
+			PutScript("calculate-score", nil),
+			[]string{"/script"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.req.Validate()
+			if test.paths == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+			var paths []string
+			for _, e := range errs {
+				paths = append(paths, e.Path)
+			}
+			if !reflect.DeepEqual(paths, test.paths) {
+				t.Errorf("expected errors at %q, got %s", test.paths, err)
+			}
+		})
+	}
+}
+
+func TestPutScriptRefusesUnsafeScripts(t *testing.T) {
+	called := false
+	put := esapi.PutScript(func(id string, body io.Reader, o ...func(*esapi.PutScriptRequest)) (*esapi.Response, error) {
+		called = true
+		_, _ = ioutil.ReadAll(body)
+		return &esapi.Response{StatusCode: 200}, nil
+	})
+
+	unsafe := InlineScript("doc['user'].value == 'kimchy'").Lang("painless").Param("user", "kimchy")
+	if _, err := PutScript("find-user", unsafe).RunPutScript(put); err == nil || called {
+		t.Errorf("expected unsafe script to be refused, got err=%v, called=%v", err, called)
+	}
+
+	safe := InlineScript("doc['user'].value == params.user").Lang("painless")
+	if _, err := PutScript("find-user", safe).RunPutScript(put); err != nil || !called {
+		t.Errorf("expected safe script to be stored, got err=%v, called=%v", err, called)
+	}
+}
@@ -2,8 +2,8 @@ package esquery
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
@@ -15,18 +15,13 @@ import (
 // Not all features of the search API are currently supported, but a request can
 // currently include a query, aggregations, and more.
 type SearchRequest struct {
+	hitOptions
 	aggs        []Aggregation
-	explain     *bool
-	from        *uint64
-	highlight   Mappable
 	searchAfter []interface{}
 	postFilter  Mappable
 	query       Mappable
-	size        *uint64
-	sort        Sort
-	source      Source
 	timeout     *time.Duration
-
+	frozen      bool
 }
 
 // Search creates a new SearchRequest object, to be filled via method chaining.
@@ -36,24 +31,28 @@ func Search() *SearchRequest {
 
 // Query sets a query for the request.
 func (req *SearchRequest) Query(q Mappable) *SearchRequest {
+	req = req.mutable()
 	req.query = q
 	return req
 }
 
 // Aggs sets one or more aggregations for the request.
 func (req *SearchRequest) Aggs(aggs ...Aggregation) *SearchRequest {
+	req = req.mutable()
 	req.aggs = append(req.aggs, aggs...)
 	return req
 }
 
 // PostFilter sets a post_filter for the request.
 func (req *SearchRequest) PostFilter(filter Mappable) *SearchRequest {
+	req = req.mutable()
 	req.postFilter = filter
 	return req
 }
 
 // From sets a document offset to start from.
 func (req *SearchRequest) From(offset uint64) *SearchRequest {
+	req = req.mutable()
 	req.from = &offset
 	return req
 }
@@ -61,23 +60,21 @@ func (req *SearchRequest) From(offset uint64) *SearchRequest {
 // Size sets the number of hits to return. The default - according to the ES
 // documentation - is 10.
 func (req *SearchRequest) Size(size uint64) *SearchRequest {
+	req = req.mutable()
 	req.size = &size
 	return req
 }
 
 // Sort sets how the results should be sorted.
 func (req *SearchRequest) Sort(name string, order Order) *SearchRequest {
-	req.sort = append(req.sort, map[string]interface{}{
-		name: map[string]interface{}{
-			"order": order,
-		},
-	})
-
+	req = req.mutable()
+	req.addSort(name, order)
 	return req
 }
 
 // SearchAfter retrieve the sorted result
 func (req *SearchRequest) SearchAfter(s ...interface{}) *SearchRequest {
+	req = req.mutable()
 	req.searchAfter = append(req.searchAfter, s...)
 	return req
 }
@@ -85,35 +82,147 @@ func (req *SearchRequest) SearchAfter(s ...interface{}) *SearchRequest {
 // Explain sets whether the ElasticSearch API should return an explanation for
 // how each hit's score was calculated.
 func (req *SearchRequest) Explain(b bool) *SearchRequest {
+	req = req.mutable()
 	req.explain = &b
 	return req
 }
 
 // Timeout sets a timeout for the request.
 func (req *SearchRequest) Timeout(dur time.Duration) *SearchRequest {
+	req = req.mutable()
 	req.timeout = &dur
 	return req
 }
 
 // SourceIncludes sets the keys to return from the matching documents.
 func (req *SearchRequest) SourceIncludes(keys ...string) *SearchRequest {
+	req = req.mutable()
 	req.source.includes = keys
 	return req
 }
 
 // SourceExcludes sets the keys to not return from the matching documents.
 func (req *SearchRequest) SourceExcludes(keys ...string) *SearchRequest {
+	req = req.mutable()
 	req.source.excludes = keys
 	return req
 }
 
 // Highlight sets a highlight for the request.
 func (req *SearchRequest) Highlight(highlight Mappable) *SearchRequest {
+	req = req.mutable()
 	req.highlight = highlight
 	return req
 }
 
+// Version sets whether to return the version of each hit.
+func (req *SearchRequest) Version(b bool) *SearchRequest {
+	req = req.mutable()
+	req.version = &b
+	return req
+}
+
+// SeqNoPrimaryTerm sets whether to return the sequence number and primary
+// term of the last modification of each hit.
+func (req *SearchRequest) SeqNoPrimaryTerm(b bool) *SearchRequest {
+	req = req.mutable()
+	req.seqNoPrimaryTerm = &b
+	return req
+}
 
+// DocvalueFields adds fields whose doc values should be returned for each
+// hit.
+func (req *SearchRequest) DocvalueFields(fields ...string) *SearchRequest {
+	req = req.mutable()
+	for _, field := range fields {
+		req.docvalueFields = append(req.docvalueFields, docvalueField{field: field})
+	}
+	return req
+}
+
+// DocvalueFieldFormat adds a field whose doc values should be returned for
+// each hit, using the provided format (e.g. "epoch_millis" for dates).
+func (req *SearchRequest) DocvalueFieldFormat(field, format string) *SearchRequest {
+	req = req.mutable()
+	req.docvalueFields = append(req.docvalueFields, docvalueField{field, format})
+	return req
+}
+
+// StoredFields sets the stored fields to return for each hit.
+func (req *SearchRequest) StoredFields(fields ...string) *SearchRequest {
+	req = req.mutable()
+	req.storedFields = append(req.storedFields, fields...)
+	return req
+}
+
+// ScriptField adds a field that is computed by the provided script for each
+// hit.
+func (req *SearchRequest) ScriptField(name string, script *Script) *SearchRequest {
+	req = req.mutable()
+	req.addScriptField(name, script)
+	return req
+}
+
+// GetQuery returns the query of the request.
+func (req *SearchRequest) GetQuery() Mappable {
+	if req.frozen {
+		return cloneMappable(req.query)
+	}
+	return req.query
+}
+
+// GetPostFilter returns the post filter of the request.
+func (req *SearchRequest) GetPostFilter() Mappable {
+	if req.frozen {
+		return cloneMappable(req.postFilter)
+	}
+	return req.postFilter
+}
+
+// GetAggs returns the aggregations of the request.
+func (req *SearchRequest) GetAggs() []Aggregation {
+	if req.frozen {
+		return cloneAggs(req.aggs)
+	}
+	return req.aggs
+}
+
+// Clone returns a deep copy of the request, which is mutable even if the
+// request is frozen.
+func (req *SearchRequest) Clone() *SearchRequest {
+	c := *req
+	c.frozen = false
+	c.hitOptions = req.hitOptions.clone()
+	c.aggs = cloneAggs(req.aggs)
+	c.searchAfter = cloneValues(req.searchAfter)
+	c.postFilter = cloneMappable(req.postFilter)
+	c.query = cloneMappable(req.query)
+	return &c
+}
+
+// Freeze makes the request immutable, along with the bool queries and bucket
+// aggregations it contains: their setters then return a modified copy, leaving
+// them unchanged, and their accessors (GetQuery, GetMust, GetAggs, etc.)
+// return deep copies. The content of the request is replaced with a deep copy
+// first, so that the values previously provided to its setters no longer
+// affect it. A frozen request can be shared between goroutines and used as a
+// template to derive other requests from. Freeze returns the request itself.
+//
+// Other types have no frozen mode: the nodes of a frozen request visited by
+// Walk or Inspect must not be modified.
+func (req *SearchRequest) Freeze() *SearchRequest {
+	*req = *req.Clone()
+	freeze(req)
+	return req
+}
+
+// mutable returns the request if it is not frozen, or a mutable copy otherwise.
+func (req *SearchRequest) mutable() *SearchRequest {
+	if req.frozen {
+		return req.Clone()
+	}
+	return req
+}
 
 // Map implements the Mappable interface. It converts the request to into a
 // nested map[string]interface{}, as expected by the go-elasticsearch library.
@@ -133,46 +242,104 @@ func (req *SearchRequest) Map() map[string]interface{} {
 	if req.postFilter != nil {
 		m["post_filter"] = req.postFilter.Map()
 	}
-	if req.size != nil {
-		m["size"] = *req.size
+	if req.timeout != nil {
+		m["timeout"] = fmt.Sprintf("%.0fs", req.timeout.Seconds())
 	}
-	if len(req.sort) > 0 {
-		m["sort"] = req.sort
+	if req.searchAfter != nil {
+		m["search_after"] = req.searchAfter
 	}
-	if req.from != nil {
-		m["from"] = *req.from
+
+	req.mapInto(m)
+
+	return m
+}
+
+// writeJSON writes the request in the same way as Map, see EncodeJSON.
+func (req *SearchRequest) writeJSON(e *encoder) {
+	e.openObject()
+	req.writeSource(e)
+	if len(req.aggs) > 0 {
+		e.key("aggs")
+		e.aggs(req.aggs)
 	}
+	req.writeDocvalueFields(e)
 	if req.explain != nil {
-		m["explain"] = *req.explain
+		e.key("explain")
+		e.bool(*req.explain)
 	}
-	if req.timeout != nil {
-		m["timeout"] = fmt.Sprintf("%.0fs", req.timeout.Seconds())
+	if req.from != nil {
+		e.key("from")
+		e.uint(*req.from)
 	}
 	if req.highlight != nil {
-		m["highlight"] = req.highlight.Map()
+		e.key("highlight")
+		e.mappable(req.highlight)
+	}
+	if req.postFilter != nil {
+		e.key("post_filter")
+		e.mappable(req.postFilter)
+	}
+	if req.query != nil {
+		e.key("query")
+		e.mappable(req.query)
 	}
+	req.writeScriptFields(e)
 	if req.searchAfter != nil {
-		m["search_after"] = req.searchAfter
+		e.key("search_after")
+		e.value(req.searchAfter)
 	}
-
-
-	source := req.source.Map()
-	if len(source) > 0 {
-		m["_source"] = source
+	if req.seqNoPrimaryTerm != nil {
+		e.key("seq_no_primary_term")
+		e.bool(*req.seqNoPrimaryTerm)
+	}
+	if req.size != nil {
+		e.key("size")
+		e.uint(*req.size)
+	}
+	req.writeSort(e)
+	if len(req.storedFields) > 0 {
+		e.key("stored_fields")
+		e.strings(req.storedFields)
+	}
+	if req.timeout != nil {
+		e.key("timeout")
+		e.buf = append(e.buf, '"')
+		e.buf = strconv.AppendFloat(e.buf, req.timeout.Seconds(), 'f', 0, 64)
+		e.buf = append(e.buf, 's', '"')
+	}
+	if req.version != nil {
+		e.key("version")
+		e.bool(*req.version)
 	}
+	e.closeObject()
+}
 
-	return m
+// Validate validates the request's query, post filter, aggregations and
+// highlight, implementing the Validator interface.
+func (req *SearchRequest) Validate() error {
+	var v validation
+	v.optional("/query", req.query)
+	v.optional("/post_filter", req.postFilter)
+	v.aggs("/aggs", req.aggs)
+	req.validateInto(&v, "")
+	return v.err()
 }
 
 // MarshalJSON implements the json.Marshaler interface. It returns a JSON
-// representation of the map generated by the SearchRequest's Map method.
+// representation of the map generated by the SearchRequest's Map method, or
+// the error returned by its Validate method.
 func (req *SearchRequest) MarshalJSON() ([]byte, error) {
-	return json.Marshal(req.Map())
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return AppendJSON(nil, req)
 }
 
 // Run executes the request using the provided ElasticSearch client. Zero or
 // more search options can be provided as well. It returns the standard Response
-// type of the official Go client.
+// type of the official Go client. The request is validated first, see
+// Validate.
 func (req *SearchRequest) Run(
 	api *elasticsearch.Client,
 	o ...func(*esapi.SearchRequest),
@@ -190,13 +357,17 @@ func (req *SearchRequest) RunSearch(
 	search esapi.Search,
 	o ...func(*esapi.SearchRequest),
 ) (res *esapi.Response, err error) {
-	var b bytes.Buffer
-	err = json.NewEncoder(&b).Encode(req.Map())
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := AppendJSON(nil, req)
 	if err != nil {
 		return nil, err
 	}
 
-	opts := append([]func(*esapi.SearchRequest){search.WithBody(&b)}, o...)
+	opts := append([]func(*esapi.SearchRequest){search.WithBody(bytes.NewReader(body))}, o...)
 
 	return search(opts...)
 }
@@ -120,5 +120,32 @@ func TestSearchMaps(t *testing.T) {
 				},
 			},
 		},
+		{
+			"a query with hit options",
+			Search().
+				Query(MatchAll()).
+				Version(true).
+				SeqNoPrimaryTerm(true).
+				DocvalueFields("tag").
+				StoredFields("title", "body").
+				ScriptField("price_with_tax", InlineScript("doc['price'].value * 1.17").Lang("painless")),
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"match_all": map[string]interface{}{},
+				},
+				"version":             true,
+				"seq_no_primary_term": true,
+				"docvalue_fields":     []interface{}{"tag"},
+				"stored_fields":       []string{"title", "body"},
+				"script_fields": map[string]interface{}{
+					"price_with_tax": map[string]interface{}{
+						"script": map[string]interface{}{
+							"source": "doc['price'].value * 1.17",
+							"lang":   "painless",
+						},
+					},
+				},
+			},
+		},
 	})
 }
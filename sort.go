@@ -0,0 +1,554 @@
+package esquery
+
+// Sorter is the interface implemented by the typed sort builders (FieldSort,
+// GeoDistanceSort and ScriptSort), which can be provided to the SortBy method
+// of SearchRequest and TopHitsAgg. Sort options are described in
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/sort-search-results.html
+type Sorter interface {
+	Mappable
+	sorter()
+}
+
+// SortBy adds one or more typed sort keys to the request. It can be combined
+// with the Sort method, keys are used in the order they were added.
+func (req *SearchRequest) SortBy(sorts ...Sorter) *SearchRequest {
+	req.addSorters(sorts)
+	return req
+}
+
+// SortBy adds one or more typed sort keys for the top matching hits. It can be
+// combined with the Sort method, keys are used in the order they were added.
+func (agg *TopHitsAgg) SortBy(sorts ...Sorter) *TopHitsAgg {
+	agg.addSorters(sorts)
+	return agg
+}
+
+// Special values for the "missing" option of a sort key.
+const (
+	// SortMissingFirst sorts documents missing the field first
+	SortMissingFirst = "_first"
+
+	// SortMissingLast sorts documents missing the field last
+	SortMissingLast = "_last"
+)
+
+//----------------------------------------------------------------------------//
+
+// FieldSort represents a sort key on a document field, or on one of the
+// special "_score", "_doc" and "_shard_doc" keys.
+type FieldSort struct {
+	name         string
+	order        Order
+	mode         SortMode
+	missing      interface{}
+	unmappedType string
+	numericType  SortNumericType
+	format       string
+	nested       *NestedSort
+}
+
+// SortField creates a new sort key on the provided field.
+func SortField(name string) *FieldSort {
+	return &FieldSort{name: name}
+}
+
+// SortScore creates a new sort key on the score of the hits.
+func SortScore() *FieldSort {
+	return SortField("_score")
+}
+
+// SortDoc creates a new sort key on the index order of the hits.
+func SortDoc() *FieldSort {
+	return SortField("_doc")
+}
+
+// SortShardDoc creates a new sort key on the shard and index order of the
+// hits, which is a tiebreaker suitable for point-in-time searches.
+func SortShardDoc() *FieldSort {
+	return SortField("_shard_doc")
+}
+
+func (s *FieldSort) sorter() {}
+
+// Order sets the sort order.
+func (s *FieldSort) Order(order Order) *FieldSort {
+	s.order = order
+	return s
+}
+
+// Mode sets how to pick the sort value of array or multi-valued fields.
+func (s *FieldSort) Mode(mode SortMode) *FieldSort {
+	s.mode = mode
+	return s
+}
+
+// Missing sets how to sort documents missing the field. The value can be
+// SortMissingFirst, SortMissingLast or a custom value to use for them.
+func (s *FieldSort) Missing(val interface{}) *FieldSort {
+	s.missing = val
+	return s
+}
+
+// UnmappedType sets the type to assume for the field in indices where it is
+// not mapped.
+func (s *FieldSort) UnmappedType(t string) *FieldSort {
+	s.unmappedType = t
+	return s
+}
+
+// NumericType sets the numeric type to cast values to, allowing to sort
+// across indices where the field is mapped with different numeric types.
+func (s *FieldSort) NumericType(t SortNumericType) *FieldSort {
+	s.numericType = t
+	return s
+}
+
+// Format sets the date format of the sort values of date fields.
+func (s *FieldSort) Format(f string) *FieldSort {
+	s.format = f
+	return s
+}
+
+// Nested sets the options for sorting on a field inside nested objects.
+func (s *FieldSort) Nested(nested *NestedSort) *FieldSort {
+	s.nested = nested
+	return s
+}
+
+// Clone returns a deep copy of the sort.
+func (s *FieldSort) Clone() *FieldSort {
+	c := *s
+	if s.nested != nil {
+		c.nested = s.nested.Clone()
+	}
+	return &c
+}
+
+// Map returns a map representation of the sort key, thus implementing the
+// Mappable interface.
+func (s *FieldSort) Map() map[string]interface{} {
+	params := make(map[string]interface{})
+	if s.order != "" {
+		params["order"] = s.order
+	}
+	if s.mode != 0 {
+		params["mode"] = s.mode.String()
+	}
+	if s.missing != nil {
+		params["missing"] = s.missing
+	}
+	if s.unmappedType != "" {
+		params["unmapped_type"] = s.unmappedType
+	}
+	if s.numericType != 0 {
+		params["numeric_type"] = s.numericType.String()
+	}
+	if s.format != "" {
+		params["format"] = s.format
+	}
+	if s.nested != nil {
+		params["nested"] = s.nested.Map()
+	}
+
+	return map[string]interface{}{
+		s.name: params,
+	}
+}
+
+// Validate checks that the sort key has a field name and validates its
+// nested options, implementing the Validator interface.
+func (s *FieldSort) Validate() error {
+	var v validation
+	v.field("", s.name)
+	v.optional(jsonPath(s.name, "nested"), s.nested)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// NestedSort represents the options for sorting on fields inside nested
+// objects.
+type NestedSort struct {
+	path        string
+	filter      Mappable
+	maxChildren *uint64
+	nested      *NestedSort
+}
+
+// SortNested creates new nested sort options for the provided nested path.
+func SortNested(path string) *NestedSort {
+	return &NestedSort{path: path}
+}
+
+// Filter sets a filter that inner objects must match in order for their
+// field values to be taken into account when sorting.
+func (n *NestedSort) Filter(filter Mappable) *NestedSort {
+	n.filter = filter
+	return n
+}
+
+// MaxChildren sets the maximum number of children to consider per root
+// document when picking the sort value.
+func (n *NestedSort) MaxChildren(max uint64) *NestedSort {
+	n.maxChildren = &max
+	return n
+}
+
+// Nested sets options for a nested path inside this nested path.
+func (n *NestedSort) Nested(nested *NestedSort) *NestedSort {
+	n.nested = nested
+	return n
+}
+
+// Clone returns a deep copy of the sort.
+func (n *NestedSort) Clone() *NestedSort {
+	c := *n
+	c.filter = cloneMappable(n.filter)
+	if n.nested != nil {
+		c.nested = n.nested.Clone()
+	}
+	return &c
+}
+
+// Map returns a map representation of the nested sort options, thus
+// implementing the Mappable interface.
+func (n *NestedSort) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"path": n.path,
+	}
+	if n.filter != nil {
+		m["filter"] = n.filter.Map()
+	}
+	if n.maxChildren != nil {
+		m["max_children"] = *n.maxChildren
+	}
+	if n.nested != nil {
+		m["nested"] = n.nested.Map()
+	}
+	return m
+}
+
+// Validate checks that the options have a path and validates their filter
+// and nested options, implementing the Validator interface.
+func (n *NestedSort) Validate() error {
+	var v validation
+	v.check(n.path != "", "/path", "is required")
+	v.optional("/filter", n.filter)
+	v.optional("/nested", n.nested)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// GeoDistanceSort represents a sort key of type "_geo_distance", which sorts
+// hits by their distance from one or more geo points.
+type GeoDistanceSort struct {
+	field          string
+	points         []GeoPoint
+	order          Order
+	unit           string
+	mode           SortMode
+	distanceType   DistanceType
+	ignoreUnmapped *bool
+}
+
+// SortGeoDistance creates a new sort key of type "_geo_distance" on the
+// provided geo_point field, measuring the distance from the provided points.
+func SortGeoDistance(field string, points ...GeoPoint) *GeoDistanceSort {
+	return &GeoDistanceSort{
+		field:  field,
+		points: points,
+	}
+}
+
+func (s *GeoDistanceSort) sorter() {}
+
+// Order sets the sort order.
+func (s *GeoDistanceSort) Order(order Order) *GeoDistanceSort {
+	s.order = order
+	return s
+}
+
+// Unit sets the unit used when computing sort values (e.g. "km").
+func (s *GeoDistanceSort) Unit(unit string) *GeoDistanceSort {
+	s.unit = unit
+	return s
+}
+
+// Mode sets how to pick the sort value of fields with multiple geo points.
+func (s *GeoDistanceSort) Mode(mode SortMode) *GeoDistanceSort {
+	s.mode = mode
+	return s
+}
+
+// DistanceType sets how to compute the distance.
+func (s *GeoDistanceSort) DistanceType(t DistanceType) *GeoDistanceSort {
+	s.distanceType = t
+	return s
+}
+
+// IgnoreUnmapped sets whether indices where the field is not mapped should be
+// ignored rather than failing the search.
+func (s *GeoDistanceSort) IgnoreUnmapped(b bool) *GeoDistanceSort {
+	s.ignoreUnmapped = &b
+	return s
+}
+
+// Clone returns a deep copy of the sort.
+func (s *GeoDistanceSort) Clone() *GeoDistanceSort {
+	c := *s
+	if s.points != nil {
+		c.points = append([]GeoPoint(nil), s.points...)
+	}
+	return &c
+}
+
+// Map returns a map representation of the sort key, thus implementing the
+// Mappable interface.
+func (s *GeoDistanceSort) Map() map[string]interface{} {
+	points := make([]map[string]interface{}, len(s.points))
+	for i, p := range s.points {
+		points[i] = p.Map()
+	}
+
+	params := map[string]interface{}{
+		s.field: points,
+	}
+	if s.order != "" {
+		params["order"] = s.order
+	}
+	if s.unit != "" {
+		params["unit"] = s.unit
+	}
+	if s.mode != 0 {
+		params["mode"] = s.mode.String()
+	}
+	if s.distanceType != 0 {
+		params["distance_type"] = s.distanceType.String()
+	}
+	if s.ignoreUnmapped != nil {
+		params["ignore_unmapped"] = *s.ignoreUnmapped
+	}
+
+	return map[string]interface{}{
+		"_geo_distance": params,
+	}
+}
+
+// Validate checks that the sort key has a field name and at least one point,
+// implementing the Validator interface.
+func (s *GeoDistanceSort) Validate() error {
+	var v validation
+	v.field("/_geo_distance", s.field)
+	v.check(len(s.points) > 0, jsonPath("_geo_distance", s.field), "at least one point is required")
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// ScriptSort represents a sort key of type "_script", which sorts hits by the
+// value computed by a script.
+type ScriptSort struct {
+	script *Script
+	typ    ScriptSortType
+	order  Order
+	mode   SortMode
+}
+
+// SortScript creates a new sort key of type "_script", sorting by the values
+// of the provided type returned by the script.
+func SortScript(script *Script, typ ScriptSortType) *ScriptSort {
+	return &ScriptSort{
+		script: script,
+		typ:    typ,
+	}
+}
+
+func (s *ScriptSort) sorter() {}
+
+// Order sets the sort order.
+func (s *ScriptSort) Order(order Order) *ScriptSort {
+	s.order = order
+	return s
+}
+
+// Mode sets how to pick the sort value when the script returns multiple
+// values.
+func (s *ScriptSort) Mode(mode SortMode) *ScriptSort {
+	s.mode = mode
+	return s
+}
+
+// Clone returns a deep copy of the sort.
+func (s *ScriptSort) Clone() *ScriptSort {
+	c := *s
+	c.script = cloneScript(s.script)
+	return &c
+}
+
+// Map returns a map representation of the sort key, thus implementing the
+// Mappable interface.
+func (s *ScriptSort) Map() map[string]interface{} {
+	params := map[string]interface{}{
+		"type": s.typ.String(),
+	}
+	if s.script != nil {
+		params["script"] = s.script.Map()
+	}
+	if s.order != "" {
+		params["order"] = s.order
+	}
+	if s.mode != 0 {
+		params["mode"] = s.mode.String()
+	}
+
+	return map[string]interface{}{
+		"_script": params,
+	}
+}
+
+// Validate validates the sort key's script, implementing the Validator
+// interface.
+func (s *ScriptSort) Validate() error {
+	var v validation
+	v.child("/_script/script", s.script)
+	return v.err()
+}
+
+//----------------------------------------------------------------------------//
+
+// SortMode is an enumeration type representing supported values for a sort
+// key's "mode" parameter.
+type SortMode uint8
+
+const (
+	_ SortMode = iota
+
+	// SortModeMin is the "min" mode
+	SortModeMin
+
+	// SortModeMax is the "max" mode
+	SortModeMax
+
+	// SortModeAvg is the "avg" mode
+	SortModeAvg
+
+	// SortModeSum is the "sum" mode
+	SortModeSum
+
+	// SortModeMedian is the "median" mode
+	SortModeMedian
+)
+
+// String returns a string representation of the mode parameter, as known to
+// ElasticSearch.
+func (a SortMode) String() string {
+	switch a {
+	case SortModeMin:
+		return "min"
+	case SortModeMax:
+		return "max"
+	case SortModeAvg:
+		return "avg"
+	case SortModeSum:
+		return "sum"
+	case SortModeMedian:
+		return "median"
+	default:
+		return ""
+	}
+}
+
+// SortNumericType is an enumeration type representing supported values for a
+// sort key's "numeric_type" parameter.
+type SortNumericType uint8
+
+const (
+	_ SortNumericType = iota
+
+	// NumericTypeDouble is the "double" numeric type
+	NumericTypeDouble
+
+	// NumericTypeLong is the "long" numeric type
+	NumericTypeLong
+
+	// NumericTypeDate is the "date" numeric type
+	NumericTypeDate
+
+	// NumericTypeDateNanos is the "date_nanos" numeric type
+	NumericTypeDateNanos
+)
+
+// String returns a string representation of the numeric_type parameter, as
+// known to ElasticSearch.
+func (a SortNumericType) String() string {
+	switch a {
+	case NumericTypeDouble:
+		return "double"
+	case NumericTypeLong:
+		return "long"
+	case NumericTypeDate:
+		return "date"
+	case NumericTypeDateNanos:
+		return "date_nanos"
+	default:
+		return ""
+	}
+}
+
+// DistanceType is an enumeration type representing supported values for the
+// "distance_type" parameter of geo distance computations.
+type DistanceType uint8
+
+const (
+	_ DistanceType = iota
+
+	// DistanceArc is the "arc" distance type
+	DistanceArc
+
+	// DistancePlane is the "plane" distance type
+	DistancePlane
+)
+
+// String returns a string representation of the distance_type parameter, as
+// known to ElasticSearch.
+func (a DistanceType) String() string {
+	switch a {
+	case DistanceArc:
+		return "arc"
+	case DistancePlane:
+		return "plane"
+	default:
+		return ""
+	}
+}
+
+// ScriptSortType is an enumeration type representing supported values for a
+// script sort's "type" parameter.
+type ScriptSortType uint8
+
+const (
+	// ScriptSortNumber sorts by numeric script values
+	ScriptSortNumber ScriptSortType = iota
+
+	// ScriptSortString sorts by string script values
+	ScriptSortString
+
+	// ScriptSortVersion sorts by version script values
+	ScriptSortVersion
+)
+
+// String returns a string representation of the script sort type, as known to
+// ElasticSearch.
+func (a ScriptSortType) String() string {
+	switch a {
+	case ScriptSortNumber:
+		return "number"
+	case ScriptSortString:
+		return "string"
+	case ScriptSortVersion:
+		return "version"
+	default:
+		return ""
+	}
+}
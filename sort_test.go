@@ -0,0 +1,126 @@
+package esquery
+
+import "testing"
+
+func TestSort(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"field sort: all options",
+			SortField("price").
+				Order(OrderAsc).
+				Mode(SortModeAvg).
+				Missing(SortMissingLast).
+				UnmappedType("long").
+				NumericType(NumericTypeDouble).
+				Nested(
+					SortNested("offers").
+						Filter(Term("offers.color", "blue")).
+						MaxChildren(10).
+						Nested(SortNested("offers.variants")),
+				),
+			map[string]interface{}{
+				"price": map[string]interface{}{
+					"order":         "asc",
+					"mode":          "avg",
+					"missing":       "_last",
+					"unmapped_type": "long",
+					"numeric_type":  "double",
+					"nested": map[string]interface{}{
+						"path": "offers",
+						"filter": map[string]interface{}{
+							"term": map[string]interface{}{
+								"offers.color": map[string]interface{}{
+									"value": "blue",
+								},
+							},
+						},
+						"max_children": 10,
+						"nested": map[string]interface{}{
+							"path": "offers.variants",
+						},
+					},
+				},
+			},
+		},
+		{
+			"field sort: date format",
+			SortField("date").Order(OrderDesc).Format("strict_date_optional_time_nanos"),
+			map[string]interface{}{
+				"date": map[string]interface{}{
+					"order":  "desc",
+					"format": "strict_date_optional_time_nanos",
+				},
+			},
+		},
+		{
+			"field sort: score",
+			SortScore(),
+			map[string]interface{}{
+				"_score": map[string]interface{}{},
+			},
+		},
+		{
+			"geo distance sort",
+			SortGeoDistance("pin.location", GeoPoint{Lat: 40, Lon: -70}, GeoPoint{Lat: 41, Lon: -71}).
+				Order(OrderAsc).
+				Unit("km").
+				Mode(SortModeMin).
+				DistanceType(DistanceArc).
+				IgnoreUnmapped(true),
+			map[string]interface{}{
+				"_geo_distance": map[string]interface{}{
+					"pin.location": []map[string]interface{}{
+						{"lat": 40, "lon": -70},
+						{"lat": 41, "lon": -71},
+					},
+					"order":           "asc",
+					"unit":            "km",
+					"mode":            "min",
+					"distance_type":   "arc",
+					"ignore_unmapped": true,
+				},
+			},
+		},
+		{
+			"script sort",
+			SortScript(
+				InlineScript("doc['field_name'].value * params.factor").Param("factor", 1.1),
+				ScriptSortNumber,
+			).Order(OrderAsc),
+			map[string]interface{}{
+				"_script": map[string]interface{}{
+					"type": "number",
+					"script": map[string]interface{}{
+						"source": "doc['field_name'].value * params.factor",
+						"params": map[string]interface{}{"factor": 1.1},
+					},
+					"order": "asc",
+				},
+			},
+		},
+		{
+			"search request with mixed sorts",
+			Search().
+				Sort("title", OrderAsc).
+				SortBy(SortField("date").Order(OrderDesc), SortShardDoc()),
+			map[string]interface{}{
+				"sort": []map[string]interface{}{
+					{"title": map[string]interface{}{"order": "asc"}},
+					{"date": map[string]interface{}{"order": "desc"}},
+					{"_shard_doc": map[string]interface{}{}},
+				},
+			},
+		},
+		{
+			"top hits with typed sort",
+			TopHits("top").SortBy(SortDoc().Order(OrderAsc)),
+			map[string]interface{}{
+				"top_hits": map[string]interface{}{
+					"sort": []map[string]interface{}{
+						{"_doc": map[string]interface{}{"order": "asc"}},
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,229 @@
+package sqlwhere
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokLParen
+	tokRParen
+	tokComma
+	tokStar
+	tokOperator
+)
+
+// String returns a human readable description of the token kind, used in
+// error messages.
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of statement"
+	case tokIdent:
+		return "identifier"
+	case tokString:
+		return "string"
+	case tokNumber:
+		return "number"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokComma:
+		return `","`
+	case tokStar:
+		return `"*"`
+	case tokOperator:
+		return "comparison operator"
+	default:
+		return "token"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	pos  int
+
+	// text is the unquoted text of identifiers and strings, the text of
+	// numbers and the operator of comparisons.
+	text string
+
+	// quoted denotes whether an identifier was quoted, in which case it is
+	// never a keyword.
+	quoted bool
+}
+
+// is returns whether the token is the provided keyword. Keywords are case
+// insensitive.
+func (t token) is(keyword string) bool {
+	return t.kind == tokIdent && !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+// describe returns a description of the token for error messages.
+func (t token) describe() string {
+	if t.kind == tokIdent && !t.quoted && isReserved(t.text) {
+		return strings.ToUpper(t.text)
+	}
+	return t.kind.String()
+}
+
+var reserved = []string{
+	"AND", "AS", "BETWEEN", "BY", "FALSE", "FROM", "GROUP", "IN", "IS", "LIKE",
+	"NOT", "NULL", "OR", "SELECT", "TRUE", "WHERE",
+}
+
+func isReserved(word string) bool {
+	for _, r := range reserved {
+		if strings.EqualFold(word, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '@' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || r == '.' || unicode.IsDigit(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+// lex splits the statement into tokens.
+func lex(input string) ([]token, error) {
+	l := &lexer{input: input}
+
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	single := func(kind tokenKind) (token, error) {
+		l.pos++
+		return token{kind: kind, pos: start, text: l.input[start:l.pos]}, nil
+	}
+
+	switch c := l.input[l.pos]; {
+	case c == '(':
+		return single(tokLParen)
+	case c == ')':
+		return single(tokRParen)
+	case c == ',':
+		return single(tokComma)
+	case c == '*':
+		return single(tokStar)
+	case c == '=':
+		return single(tokOperator)
+	case c == '!' || c == '<' || c == '>':
+		l.pos++
+		if l.pos < len(l.input) && (l.input[l.pos] == '=' || (c == '<' && l.input[l.pos] == '>')) {
+			l.pos++
+		}
+		op := l.input[start:l.pos]
+		if op == "!" {
+			return token{}, errorf(start, `unexpected "!"`)
+		}
+		return token{kind: tokOperator, pos: start, text: op}, nil
+	case c == '\'':
+		return l.quoted(tokString, '\'')
+	case c == '"' || c == '`':
+		return l.quoted(tokIdent, c)
+	case isDigit(c) || ((c == '-' || c == '.') && l.pos+1 < len(l.input) && isDigit(l.input[l.pos+1])):
+		return l.number()
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+	if !isIdentStart(r) {
+		return token{}, errorf(start, "unexpected character %q", r)
+	}
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !isIdentPart(r) {
+			break
+		}
+		l.pos += size
+	}
+	return token{kind: tokIdent, pos: start, text: l.input[start:l.pos]}, nil
+}
+
+// quoted lexes a string literal or a quoted identifier. The quote character
+// is escaped by doubling it.
+func (l *lexer) quoted(kind tokenKind, quote byte) (token, error) {
+	start := l.pos
+	l.pos++
+
+	var b strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		l.pos++
+		if c != quote {
+			b.WriteByte(c)
+			continue
+		}
+		if l.pos < len(l.input) && l.input[l.pos] == quote {
+			b.WriteByte(quote)
+			l.pos++
+			continue
+		}
+		return token{kind: kind, pos: start, text: b.String(), quoted: true}, nil
+	}
+
+	if kind == tokString {
+		return token{}, errorf(start, "unterminated string")
+	}
+	return token{}, errorf(start, "unterminated identifier")
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if !isDigit(c) && c != '.' && c != 'e' && c != 'E' &&
+			!((c == '-' || c == '+') && (l.input[l.pos-1] == 'e' || l.input[l.pos-1] == 'E')) {
+			break
+		}
+		l.pos++
+	}
+	return token{kind: tokNumber, pos: start, text: l.input[start:l.pos]}, nil
+}
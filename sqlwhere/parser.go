@@ -0,0 +1,364 @@
+// Package sqlwhere compiles a subset of SQL into esquery trees: predicates
+// of WHERE clauses are compiled into queries, and SELECT statements with
+// aggregate functions and a GROUP BY clause are compiled into aggregations.
+//
+// The following predicates are supported:
+//
+//	status = 'open'                 term query
+//	status != 'closed'              term query in a must_not clause ("<>" too)
+//	severity >= 7                   range query ("<", "<=", ">", ">=")
+//	severity BETWEEN 7 AND 10       range query, bounds included
+//	status IN ('open', 'pending')   terms query
+//	host LIKE 'web_%'               wildcard query ("%" and "_" wildcards)
+//	owner IS NULL                   exists query in a must_not clause
+//	owner IS NOT NULL               exists query
+//	a AND b, a OR b, NOT a          boolean operators
+//	(a OR b) AND c                  grouping
+//
+// IN, BETWEEN and LIKE can be negated with NOT (e.g. "status NOT IN (...)").
+// Values are single-quoted strings, numbers, TRUE and FALSE. Field names can
+// be quoted with double quotes or backticks, e.g. when they are keywords.
+// As predicates do not affect scoring, AND produces the filter clauses of a
+// bool query.
+package sqlwhere
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aquasecurity/esquery"
+)
+
+// Error is the error type returned when a statement cannot be compiled. Its
+// position points at the offending token.
+type Error struct {
+	// Pos is the byte offset in the statement at which the error was
+	// detected.
+	Pos int
+
+	// Msg describes the error.
+	Msg string
+}
+
+// Error returns a string representation of the error, implementing the error
+// interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("sqlwhere: %s at position %d", e.Msg, e.Pos)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Where compiles the predicate of a WHERE clause into a query. The predicate
+// may optionally be preceded by the WHERE keyword. Errors are always of type
+// *Error.
+func Where(expr string) (esquery.Mappable, error) {
+	st, err := newParseState(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.peek().is("WHERE") {
+		st.next()
+	}
+
+	q, err := st.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := st.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "expected AND, OR or end of statement, found %s", t.describe())
+	}
+	return q, nil
+}
+
+type parseState struct {
+	tokens []token
+	pos    int
+}
+
+func newParseState(input string) (*parseState, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	return &parseState{tokens: tokens}, nil
+}
+
+func (st *parseState) peek() token {
+	return st.tokens[st.pos]
+}
+
+func (st *parseState) next() token {
+	t := st.tokens[st.pos]
+	if t.kind != tokEOF {
+		st.pos++
+	}
+	return t
+}
+
+// keyword consumes the provided keyword, or returns an error if the next
+// token is something else.
+func (st *parseState) keyword(keyword string) error {
+	if t := st.next(); !t.is(keyword) {
+		return errorf(t.pos, "expected %s, found %s", keyword, t.describe())
+	}
+	return nil
+}
+
+// expect consumes a token of the provided kind, or returns an error if the
+// next token is something else.
+func (st *parseState) expect(kind tokenKind) (token, error) {
+	t := st.next()
+	if t.kind != kind {
+		return t, errorf(t.pos, "expected %s, found %s", kind, t.describe())
+	}
+	return t, nil
+}
+
+// field consumes a field name.
+func (st *parseState) field() (token, error) {
+	t := st.next()
+	if t.kind != tokIdent || (!t.quoted && isReserved(t.text)) {
+		return t, errorf(t.pos, "expected a field name, found %s", t.describe())
+	}
+	return t, nil
+}
+
+func (st *parseState) or() (esquery.Mappable, error) {
+	var queries []esquery.Mappable
+	for {
+		q, err := st.and()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+		if !st.peek().is("OR") {
+			break
+		}
+		st.next()
+	}
+
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+	return esquery.Bool().Should(queries...).MinimumShouldMatch(1), nil
+}
+
+func (st *parseState) and() (esquery.Mappable, error) {
+	var queries []esquery.Mappable
+	for {
+		q, err := st.not()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+		if !st.peek().is("AND") {
+			break
+		}
+		st.next()
+	}
+
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+	return esquery.Bool().Filter(queries...), nil
+}
+
+func (st *parseState) not() (esquery.Mappable, error) {
+	if !st.peek().is("NOT") {
+		return st.primary()
+	}
+	st.next()
+	q, err := st.not()
+	if err != nil {
+		return nil, err
+	}
+	return esquery.Bool().MustNot(q), nil
+}
+
+func (st *parseState) primary() (esquery.Mappable, error) {
+	if st.peek().kind == tokLParen {
+		st.next()
+		q, err := st.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := st.next(); t.kind != tokRParen {
+			return nil, errorf(t.pos, `expected AND, OR or ")", found %s`, t.describe())
+		}
+		return q, nil
+	}
+	return st.predicate()
+}
+
+func (st *parseState) predicate() (esquery.Mappable, error) {
+	f, err := st.field()
+	if err != nil {
+		return nil, err
+	}
+	field := f.text
+
+	t := st.next()
+	if t.kind == tokOperator {
+		v, err := st.value()
+		if err != nil {
+			return nil, err
+		}
+		switch t.text {
+		case "=":
+			return esquery.Term(field, v), nil
+		case "!=", "<>":
+			return esquery.Bool().MustNot(esquery.Term(field, v)), nil
+		case "<":
+			return esquery.Range(field).Lt(v), nil
+		case "<=":
+			return esquery.Range(field).Lte(v), nil
+		case ">":
+			return esquery.Range(field).Gt(v), nil
+		default:
+			return esquery.Range(field).Gte(v), nil
+		}
+	}
+
+	if t.is("IS") {
+		negate := st.peek().is("NOT")
+		if negate {
+			st.next()
+		}
+		if err := st.keyword("NULL"); err != nil {
+			return nil, err
+		}
+		if negate {
+			return esquery.Exists(field), nil
+		}
+		return esquery.Bool().MustNot(esquery.Exists(field)), nil
+	}
+
+	negate := t.is("NOT")
+	if negate {
+		t = st.next()
+	}
+
+	var q esquery.Mappable
+	switch {
+	case t.is("IN"):
+		q, err = st.in(field)
+	case t.is("BETWEEN"):
+		q, err = st.between(field)
+	case t.is("LIKE"):
+		q, err = st.like(field)
+	case negate:
+		return nil, errorf(t.pos, "expected IN, BETWEEN or LIKE, found %s", t.describe())
+	default:
+		return nil, errorf(t.pos, "expected an operator, found %s", t.describe())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if negate {
+		return esquery.Bool().MustNot(q), nil
+	}
+	return q, nil
+}
+
+// value consumes a literal value.
+func (st *parseState) value() (interface{}, error) {
+	t := st.next()
+	switch {
+	case t.kind == tokString:
+		return t.text, nil
+	case t.kind == tokNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errorf(t.pos, "invalid number %q", t.text)
+		}
+		return f, nil
+	case t.is("TRUE"):
+		return true, nil
+	case t.is("FALSE"):
+		return false, nil
+	case t.is("NULL"):
+		return nil, errorf(t.pos, "NULL cannot be compared, use IS NULL or IS NOT NULL")
+	}
+	return nil, errorf(t.pos, "expected a value, found %s", t.describe())
+}
+
+func (st *parseState) in(field string) (esquery.Mappable, error) {
+	if _, err := st.expect(tokLParen); err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	for {
+		v, err := st.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		t := st.next()
+		if t.kind == tokRParen {
+			break
+		}
+		if t.kind != tokComma {
+			return nil, errorf(t.pos, `expected "," or ")", found %s`, t.describe())
+		}
+	}
+
+	return esquery.Terms(field, values...), nil
+}
+
+func (st *parseState) between(field string) (esquery.Mappable, error) {
+	from, err := st.value()
+	if err != nil {
+		return nil, err
+	}
+	if err := st.keyword("AND"); err != nil {
+		return nil, err
+	}
+	to, err := st.value()
+	if err != nil {
+		return nil, err
+	}
+	return esquery.Range(field).Gte(from).Lte(to), nil
+}
+
+func (st *parseState) like(field string) (esquery.Mappable, error) {
+	t, err := st.expect(tokString)
+	if err != nil {
+		return nil, err
+	}
+	return esquery.Wildcard(field, likePattern(t.text)), nil
+}
+
+// likePattern converts a LIKE pattern into the syntax of ElasticSearch's
+// wildcard query. A backslash escapes the following character.
+func likePattern(like string) string {
+	var b strings.Builder
+	for i := 0; i < len(like); i++ {
+		c := like[i]
+		if c == '\\' && i+1 < len(like) {
+			i++
+			c = like[i]
+		} else if c == '%' {
+			b.WriteByte('*')
+			continue
+		} else if c == '_' {
+			b.WriteByte('?')
+			continue
+		}
+
+		if c == '*' || c == '?' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
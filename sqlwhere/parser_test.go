@@ -0,0 +1,111 @@
+package sqlwhere
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aquasecurity/esquery"
+)
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		exp  esquery.Mappable
+	}{
+		{
+			"comparisons",
+			`status = 'open' AND severity >= 7 AND score < 0.5 AND owner != 'root'`,
+			esquery.Bool().Filter(
+				esquery.Term("status", "open"),
+				esquery.Range("severity").Gte(7),
+				esquery.Range("score").Lt(0.5),
+				esquery.Bool().MustNot(esquery.Term("owner", "root")),
+			),
+		},
+		{
+			"in, between and like",
+			`WHERE status IN ('open', 'pending') OR severity BETWEEN 7 AND 10 OR host LIKE 'web\_%'`,
+			esquery.Bool().Should(
+				esquery.Terms("status", "open", "pending"),
+				esquery.Range("severity").Gte(7).Lte(10),
+				esquery.Wildcard("host", "web_*"),
+			).MinimumShouldMatch(1),
+		},
+		{
+			"negations and null checks",
+			`NOT (a = 1 OR b = TRUE) and c not in (1, 2) and d is null and e IS NOT NULL`,
+			esquery.Bool().Filter(
+				esquery.Bool().MustNot(
+					esquery.Bool().Should(
+						esquery.Term("a", 1),
+						esquery.Term("b", true),
+					).MinimumShouldMatch(1),
+				),
+				esquery.Bool().MustNot(esquery.Terms("c", 1, 2)),
+				esquery.Bool().MustNot(esquery.Exists("d")),
+				esquery.Exists("e"),
+			),
+		},
+		{
+			"quoted identifiers and escaped strings",
+			"\"group\" = 'it''s' AND `user.name` LIKE '%?_*'",
+			esquery.Bool().Filter(
+				esquery.Term("group", "it's"),
+				esquery.Wildcard("user.name", `*\??\*`),
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := Where(test.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			exp, _ := json.Marshal(test.exp.Map())
+			got, _ := json.Marshal(q.Map())
+			if string(exp) != string(got) {
+				t.Errorf("expected %s, got %s", exp, got)
+			}
+		})
+	}
+}
+
+func TestWhereErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"unterminated string", `a = 'open`, 4, "unterminated string"},
+		{"missing parenthesis", `(a = 1 OR b = 2`, 15, `expected AND, OR or ")", found end of statement`},
+		{"missing value", `a = AND b = 1`, 4, "expected a value, found AND"},
+		{"null comparison", `a = NULL`, 4, "NULL cannot be compared, use IS NULL or IS NOT NULL"},
+		{"missing operator", `a 1`, 2, "expected an operator, found number"},
+		{"bad negation", `a NOT = 1`, 6, "expected IN, BETWEEN or LIKE, found comparison operator"},
+		{"keyword as field", `AND = 1`, 0, "expected a field name, found AND"},
+		{"unclosed list", `a IN (1, 2`, 10, `expected "," or ")", found end of statement`},
+		{"missing between bound", `a BETWEEN 1 OR 2`, 12, "expected AND, found OR"},
+		{"trailing tokens", `a = 1 b = 2`, 6, "expected AND, OR or end of statement, found identifier"},
+		{"invalid character", `a = #1`, 4, `unexpected character '#'`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Where(test.expr)
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected a *Error, got %T", err)
+			}
+			if e.Pos != test.pos || e.Msg != test.msg {
+				t.Errorf("expected %q at %d, got %q at %d", test.msg, test.pos, e.Msg, e.Pos)
+			}
+		})
+	}
+}
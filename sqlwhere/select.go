@@ -0,0 +1,278 @@
+package sqlwhere
+
+import (
+	"strings"
+
+	"github.com/aquasecurity/esquery"
+)
+
+// selectItem is an item of a SELECT statement's select list.
+type selectItem struct {
+	pos      int
+	function string // upper case, empty for plain columns
+	field    string // empty for "*" and COUNT(*)
+	alias    string
+}
+
+// name returns the name of the aggregation produced for the item.
+func (item selectItem) name() string {
+	if item.alias != "" {
+		return item.alias
+	}
+	if item.function == "" {
+		return item.field
+	}
+	if item.field == "" {
+		return strings.ToLower(item.function)
+	}
+	return strings.ToLower(item.function) + "_" + item.field
+}
+
+// Select compiles a SELECT statement into a search request. The statement
+// has the form:
+//
+//	SELECT <items> [WHERE <predicate>] [GROUP BY <fields>]
+//
+// Without aggregate functions or a GROUP BY clause, the selected columns (or
+// "*") restrict the returned document sources. Otherwise, the request
+// returns no hits, and the aggregate functions AVG, SUM, MAX, MIN and
+// COUNT(field) are compiled into avg, sum, max, min and value_count
+// aggregations named after their alias, or after the function and field
+// (e.g. "avg_bytes"). With GROUP BY, COUNT(*) is available as the doc_count
+// of buckets; without it, COUNT(*) is compiled into a value_count
+// aggregation on the _id field, since the total number of hits is not exact
+// by default. Each field of the GROUP BY clause produces a terms aggregation
+// named after the field (or its alias in the select list), nested within the
+// aggregation of the previous field; the aggregate functions are
+// sub-aggregations of the innermost one. Note that terms aggregations only
+// return the top 10 buckets unless their size is changed.
+//
+// There is no FROM clause, the index to search is provided when running the
+// request. Errors are always of type *Error.
+func Select(stmt string) (*esquery.SearchRequest, error) {
+	st, err := newParseState(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := st.keyword("SELECT"); err != nil {
+		return nil, err
+	}
+	items, err := st.selectList()
+	if err != nil {
+		return nil, err
+	}
+
+	req := esquery.Search()
+	if st.peek().is("WHERE") {
+		st.next()
+		q, err := st.or()
+		if err != nil {
+			return nil, err
+		}
+		req.Query(q)
+	}
+
+	var groupBy []token
+	if st.peek().is("GROUP") {
+		st.next()
+		if err := st.keyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			f, err := st.field()
+			if err != nil {
+				return nil, err
+			}
+			groupBy = append(groupBy, f)
+			if st.peek().kind != tokComma {
+				break
+			}
+			st.next()
+		}
+	}
+
+	if t := st.peek(); t.kind != tokEOF {
+		if t.is("FROM") {
+			return nil, errorf(t.pos, "FROM is not supported, the index is provided when running the request")
+		}
+		return nil, errorf(t.pos, "expected WHERE, GROUP BY or end of statement, found %s", t.describe())
+	}
+
+	return compileSelect(req, items, groupBy)
+}
+
+func (st *parseState) selectList() ([]selectItem, error) {
+	var items []selectItem
+	for {
+		item, err := st.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if st.peek().kind != tokComma {
+			return items, nil
+		}
+		st.next()
+	}
+}
+
+func (st *parseState) selectItem() (item selectItem, err error) {
+	t := st.peek()
+	item.pos = t.pos
+
+	if t.kind == tokStar {
+		st.next()
+		return item, nil
+	}
+
+	if t.kind == tokIdent && !t.quoted && st.tokens[st.pos+1].kind == tokLParen {
+		st.next()
+		st.next()
+		item.function = strings.ToUpper(t.text)
+		switch item.function {
+		case "AVG", "SUM", "MAX", "MIN":
+			f, err := st.field()
+			if err != nil {
+				return item, err
+			}
+			item.field = f.text
+		case "COUNT":
+			if st.peek().kind == tokStar {
+				st.next()
+			} else {
+				f, err := st.field()
+				if err != nil {
+					return item, err
+				}
+				item.field = f.text
+			}
+		default:
+			return item, errorf(t.pos, "unsupported function %s", item.function)
+		}
+		if _, err := st.expect(tokRParen); err != nil {
+			return item, err
+		}
+	} else {
+		f, err := st.field()
+		if err != nil {
+			return item, err
+		}
+		item.field = f.text
+	}
+
+	if st.peek().is("AS") {
+		st.next()
+		alias, err := st.field()
+		if err != nil {
+			return item, err
+		}
+		item.alias = alias.text
+	}
+
+	return item, nil
+}
+
+func compileSelect(req *esquery.SearchRequest, items []selectItem, groupBy []token) (*esquery.SearchRequest, error) {
+	var columns []string
+	var metrics []esquery.Aggregation
+	names := make(map[string]bool)
+	aliases := make(map[string]string)
+
+	for _, item := range items {
+		switch {
+		case item.function == "" && item.field == "":
+			if len(groupBy) > 0 {
+				return nil, errorf(item.pos, `"*" cannot be selected with GROUP BY`)
+			}
+		case item.function == "":
+			if !groups(groupBy, item.field) {
+				if len(groupBy) > 0 {
+					return nil, errorf(item.pos, "column %q must appear in the GROUP BY clause or be used in an aggregate function", item.field)
+				}
+				if item.alias != "" {
+					return nil, errorf(item.pos, "aliases are only supported for aggregate functions and grouped columns")
+				}
+			}
+			if item.alias != "" {
+				aliases[item.field] = item.alias
+			}
+			columns = append(columns, item.field)
+		case item.function == "COUNT" && item.field == "" && len(groupBy) > 0:
+			// the number of documents is the doc_count of buckets
+		default:
+			name := item.name()
+			if names[name] {
+				return nil, errorf(item.pos, "duplicate aggregation name %q", name)
+			}
+			names[name] = true
+			if item.function == "COUNT" && item.field == "" {
+				// hits.total is capped, count the documents instead
+				item.field = "_id"
+			}
+			metrics = append(metrics, metric(item, name))
+		}
+	}
+
+	if len(groupBy) == 0 && len(metrics) == 0 {
+		if len(columns) > 0 && len(columns) == len(items) {
+			req.SourceIncludes(columns...)
+		}
+		return req, nil
+	}
+
+	if len(groupBy) == 0 && len(columns) > 0 {
+		return nil, errorf(items[0].pos, "columns cannot be selected together with aggregate functions without GROUP BY")
+	}
+
+	req.Size(0)
+	if len(groupBy) == 0 {
+		return req.Aggs(metrics...), nil
+	}
+
+	// build the terms aggregations from the innermost one outwards
+	aggs := metrics
+	for i := len(groupBy) - 1; i >= 0; i-- {
+		field := groupBy[i].text
+		name := field
+		if alias, ok := aliases[field]; ok {
+			name = alias
+		}
+		if names[name] {
+			return nil, errorf(groupBy[i].pos, "duplicate aggregation name %q", name)
+		}
+		names[name] = true
+
+		terms := esquery.TermsAgg(name, field)
+		if len(aggs) > 0 {
+			terms.Aggs(aggs...)
+		}
+		aggs = []esquery.Aggregation{terms}
+	}
+
+	return req.Aggs(aggs...), nil
+}
+
+func groups(groupBy []token, field string) bool {
+	for _, g := range groupBy {
+		if g.text == field {
+			return true
+		}
+	}
+	return false
+}
+
+func metric(item selectItem, name string) esquery.Aggregation {
+	switch item.function {
+	case "AVG":
+		return esquery.Avg(name, item.field)
+	case "SUM":
+		return esquery.Sum(name, item.field)
+	case "MAX":
+		return esquery.Max(name, item.field)
+	case "MIN":
+		return esquery.Min(name, item.field)
+	default:
+		return esquery.ValueCount(name, item.field)
+	}
+}
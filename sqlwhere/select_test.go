@@ -0,0 +1,121 @@
+package sqlwhere
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aquasecurity/esquery"
+)
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		exp  *esquery.SearchRequest
+	}{
+		{
+			"columns",
+			`SELECT title, severity WHERE status = 'open'`,
+			esquery.Search().
+				Query(esquery.Term("status", "open")).
+				SourceIncludes("title", "severity"),
+		},
+		{
+			"all columns",
+			`select *`,
+			esquery.Search(),
+		},
+		{
+			"aggregates without grouping",
+			`SELECT AVG(bytes), max(bytes) AS largest, COUNT(*)`,
+			esquery.Search().
+				Size(0).
+				Aggs(
+					esquery.Avg("avg_bytes", "bytes"),
+					esquery.Max("largest", "bytes"),
+					esquery.ValueCount("count", "_id"),
+				),
+		},
+		{
+			"count without grouping",
+			`SELECT COUNT(*) AS matches WHERE a = 1`,
+			esquery.Search().
+				Query(esquery.Term("a", 1)).
+				Size(0).
+				Aggs(esquery.ValueCount("matches", "_id")),
+		},
+		{
+			"grouping",
+			`SELECT host AS server, region, SUM(bytes), MIN(latency), COUNT(user)
+			 WHERE status = 200
+			 GROUP BY host, region`,
+			esquery.Search().
+				Query(esquery.Term("status", 200)).
+				Size(0).
+				Aggs(
+					esquery.TermsAgg("server", "host").Aggs(
+						esquery.TermsAgg("region", "region").Aggs(
+							esquery.Sum("sum_bytes", "bytes"),
+							esquery.Min("min_latency", "latency"),
+							esquery.ValueCount("count_user", "user"),
+						),
+					),
+				),
+		},
+		{
+			"grouping without aggregates",
+			`SELECT COUNT(*) GROUP BY host`,
+			esquery.Search().
+				Size(0).
+				Aggs(esquery.TermsAgg("host", "host")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := Select(test.stmt)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			exp, _ := json.Marshal(test.exp.Map())
+			got, _ := json.Marshal(req.Map())
+			if string(exp) != string(got) {
+				t.Errorf("expected %s, got %s", exp, got)
+			}
+		})
+	}
+}
+
+func TestSelectErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		pos  int
+		msg  string
+	}{
+		{"missing select", `title = 1`, 0, "expected SELECT, found identifier"},
+		{"from clause", `SELECT * FROM logs`, 9, "FROM is not supported, the index is provided when running the request"},
+		{"ungrouped column", `SELECT host, AVG(bytes) GROUP BY region`, 7, `column "host" must appear in the GROUP BY clause or be used in an aggregate function`},
+		{"mixed columns and aggregates", `SELECT host, AVG(bytes)`, 7, "columns cannot be selected together with aggregate functions without GROUP BY"},
+		{"unsupported function", `SELECT MEDIAN(bytes)`, 7, "unsupported function MEDIAN"},
+		{"duplicate names", `SELECT AVG(a) AS x, SUM(b) AS x`, 20, `duplicate aggregation name "x"`},
+		{"missing by", `SELECT COUNT(*) GROUP host`, 22, "expected BY, found identifier"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Select(test.stmt)
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected a *Error, got %T", err)
+			}
+			if e.Pos != test.pos || e.Msg != test.msg {
+				t.Errorf("expected %q at %d, got %q at %d", test.msg, test.pos, e.Msg, e.Pos)
+			}
+		})
+	}
+}
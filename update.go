@@ -0,0 +1,112 @@
+package esquery
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// UpdateRequest represents a request to ElasticSearch's Update By Query API,
+// described in
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update-by-query.html
+type UpdateRequest struct {
+	index  []string
+	query  Mappable
+	script *Script
+}
+
+// Update creates a new UpdateRequest object, to be filled via method chaining.
+func Update() *UpdateRequest {
+	return &UpdateRequest{}
+}
+
+// Index sets the index names for the request
+func (req *UpdateRequest) Index(index ...string) *UpdateRequest {
+	req.index = index
+	return req
+}
+
+// Query sets a query for the request, selecting the documents to update.
+func (req *UpdateRequest) Query(q Mappable) *UpdateRequest {
+	req.query = q
+	return req
+}
+
+// Script sets the script updating each selected document.
+func (req *UpdateRequest) Script(script *Script) *UpdateRequest {
+	req.script = script
+	return req
+}
+
+// GetQuery returns the query of the request.
+func (req *UpdateRequest) GetQuery() Mappable {
+	return req.query
+}
+
+// Clone returns a deep copy of the request.
+func (req *UpdateRequest) Clone() *UpdateRequest {
+	c := *req
+	c.index = cloneStrings(req.index)
+	c.query = cloneMappable(req.query)
+	c.script = cloneScript(req.script)
+	return &c
+}
+
+// Map returns a map representation of the request, thus implementing the
+// Mappable interface.
+func (req *UpdateRequest) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	if req.query != nil {
+		m["query"] = req.query.Map()
+	}
+	if req.script != nil {
+		m["script"] = req.script.Map()
+	}
+	return m
+}
+
+// Validate validates the request's query and script, implementing the
+// Validator interface.
+func (req *UpdateRequest) Validate() error {
+	var v validation
+	v.optional("/query", req.query)
+	v.optional("/script", req.script)
+	return v.err()
+}
+
+// Run executes the request using the provided ElasticSearch client. The
+// request is validated first, see Validate.
+func (req *UpdateRequest) Run(
+	api *elasticsearch.Client,
+	o ...func(*esapi.UpdateByQueryRequest),
+) (res *esapi.Response, err error) {
+	return req.RunUpdate(api.UpdateByQuery, o...)
+}
+
+// RunUpdate is the same as the Run method, except that it accepts a value of
+// type esapi.UpdateByQuery (usually this is the UpdateByQuery field of an
+// elasticsearch.Client object). Since the ElasticSearch client does not provide
+// an interface type for its API (which would allow implementation of mock
+// clients), this provides a workaround. The UpdateByQuery function in the ES
+// client is actually a field of a function type.
+func (req *UpdateRequest) RunUpdate(
+	upd esapi.UpdateByQuery,
+	o ...func(*esapi.UpdateByQueryRequest),
+) (res *esapi.Response, err error) {
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = json.NewEncoder(&b).Encode(req.Map())
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append([]func(*esapi.UpdateByQueryRequest){upd.WithBody(&b)}, o...)
+
+	return upd(req.index, opts...)
+}
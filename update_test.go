@@ -0,0 +1,30 @@
+package esquery
+
+import "testing"
+
+func TestUpdate(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"an update by query request",
+			Update().
+				Index("findings").
+				Query(Term("status", "open")).
+				Script(InlineScript("ctx._source.status = params.status").Param("status", "closed")),
+			map[string]interface{}{
+				"query": map[string]interface{}{
+					"term": map[string]interface{}{
+						"status": map[string]interface{}{
+							"value": "open",
+						},
+					},
+				},
+				"script": map[string]interface{}{
+					"source": "ctx._source.status = params.status",
+					"params": map[string]interface{}{
+						"status": "closed",
+					},
+				},
+			},
+		},
+	})
+}
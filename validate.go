@@ -0,0 +1,182 @@
+package esquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator is the interface implemented by queries, aggregations and
+// requests that can check whether they would produce valid ElasticSearch DSL.
+// All types provided by the package implement it.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError describes a single problem found by Validate.
+type ValidationError struct {
+	// Path is a JSON pointer-like path to the offending element, relative to
+	// the map generated by the validated value (e.g. "/bool/must/0/range/age").
+	Path string
+
+	// Msg describes the problem.
+	Msg string
+}
+
+// Error returns a string representation of the error, implementing the error
+// interface.
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Msg
+	}
+	return e.Path + ": " + e.Msg
+}
+
+// ValidationErrors is the error type returned by Validate methods, holding
+// all the problems found in a value and its descendants.
+type ValidationErrors []*ValidationError
+
+// Error returns a string representation of the errors, implementing the error
+// interface.
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return "esquery: invalid DSL: " + strings.Join(msgs, "; ")
+}
+
+// Validate validates the provided value if it implements the Validator
+// interface. It is mostly useful for validating values of the Mappable
+// interface type.
+func Validate(m Mappable) error {
+	if isNil(m) {
+		return ValidationErrors{{Msg: "is nil"}}
+	}
+	if v, ok := m.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+func isNil(m interface{}) bool {
+	if m == nil {
+		return true
+	}
+	v := reflect.ValueOf(m)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// jsonPath builds a JSON pointer from the provided elements, escaping them as
+// required.
+func jsonPath(elems ...interface{}) string {
+	var b strings.Builder
+	for _, elem := range elems {
+		b.WriteByte('/')
+		s := fmt.Sprint(elem)
+		s = strings.Replace(s, "~", "~0", -1)
+		s = strings.Replace(s, "/", "~1", -1)
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// validation accumulates the errors found while validating a value.
+type validation struct {
+	errs ValidationErrors
+}
+
+// errorf records an error at the provided path.
+func (v *validation) errorf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Path: path,
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+// check records an error at the provided path if the condition is false.
+func (v *validation) check(cond bool, path, format string, args ...interface{}) {
+	if !cond {
+		v.errorf(path, format, args...)
+	}
+}
+
+// field records an error if the provided field name is empty.
+func (v *validation) field(path, name string) {
+	v.check(name != "", path, "field name is required")
+}
+
+// child validates a nested value, recording its errors under the provided
+// path. A nil value is an error. Errors that are not ValidationErrors (e.g.
+// those returned by Script.Validate) are recorded at the provided path.
+func (v *validation) child(path string, m Mappable) {
+	if isNil(m) {
+		v.errorf(path, "is required")
+		return
+	}
+
+	val, ok := m.(Validator)
+	if !ok {
+		return
+	}
+
+	err := val.Validate()
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, e := range errs {
+			v.errs = append(v.errs, &ValidationError{Path: path + e.Path, Msg: e.Msg})
+		}
+		return
+	}
+	v.errorf(path, "%s", strings.TrimPrefix(err.Error(), "esquery: "))
+}
+
+// optional validates a nested value that may be nil.
+func (v *validation) optional(path string, m Mappable) {
+	if !isNil(m) {
+		v.child(path, m)
+	}
+}
+
+// children validates a list of nested values, recording their errors under
+// the provided path followed by their index.
+func (v *validation) children(path string, ms []Mappable) {
+	for i, m := range ms {
+		v.child(path+jsonPath(i), m)
+	}
+}
+
+// aggs validates a list of aggregations, recording their errors under the
+// provided path followed by their name.
+func (v *validation) aggs(path string, aggs []Aggregation) {
+	names := make(map[string]bool, len(aggs))
+	for i, agg := range aggs {
+		if isNil(agg) {
+			v.errorf(path+jsonPath(i), "is required")
+			continue
+		}
+		name := agg.Name()
+		switch {
+		case name == "":
+			v.errorf(path, "aggregation name is required")
+		case names[name]:
+			v.errorf(path+jsonPath(name), "duplicate aggregation name")
+		}
+		names[name] = true
+		v.child(path+jsonPath(name), agg)
+	}
+}
+
+// err returns the accumulated errors, or nil if there are none.
+func (v *validation) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,262 @@
+package esquery
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value Mappable
+		paths []string
+	}{
+		{
+			"valid bool query",
+			Bool().
+				Must(Term("tag", "tech")).
+				Should(Range("age").Gte(18), Match("title", "go")).
+				MinimumShouldMatch(1),
+			nil,
+		},
+		{
+			"range with no bounds",
+			Range("age"),
+			[]string{"/range/age"},
+		},
+		{
+			"nested errors in bool query",
+			Bool().
+				Must(Range("age"), Term("", "a")).
+				Filter(Terms("tags")),
+			[]string{"/bool/must/0/range/age", "/bool/must/1/term", "/bool/filter/0/terms/tags"},
+		},
+		{
+			"minimum_should_match without should clauses",
+			Bool().Must(Term("tag", "tech")).MinimumShouldMatch(1),
+			[]string{"/bool/minimum_should_match"},
+		},
+		{
+			"nil clause in dis_max",
+			DisMax(Term("a", "b"), nil),
+			[]string{"/dis_max/queries/1"},
+		},
+		{
+			"boosting query with no negative part",
+			Boosting().Positive(Term("a", "b")).NegativeBoost(2),
+			[]string{"/boosting/negative", "/boosting/negative_boost"},
+		},
+		{
+			"span query with invalid clause",
+			SpanNear(SpanTerm("text", "quick"), SpanMultiTerm(Range("date"))),
+			[]string{"/span_near/clauses/1/span_multi/match/range/date"},
+		},
+		{
+			"function score with unsafe script",
+			FunctionScore(MatchAll()).Functions(
+				ScriptScoreFunc(InlineScript("doc['user'].value == 'bob'").Param("user", "bob")),
+			),
+			[]string{"/function_score/functions/0/script_score/script"},
+		},
+		{
+			"filter aggregation with nil filter",
+			FilterAgg("filtered", nil),
+			[]string{"/filter"},
+		},
+		{
+			"aggregations and terms_set with unsafe scripts",
+			Search().
+				Query(TermsSet("tags", "a").MinimumShouldMatchScript(
+					InlineScript("params.n == 'bob' ? 1 : 2").Param("n", "bob"),
+				)).
+				Aggs(
+					Avg("avg_age", "").Script(InlineScript("doc['age'].value * 'x'").Param("x", "x")),
+					Cardinality("users", "").Script(StoredScript("")),
+					TermsAgg("by_name", "").Script(InlineScript("doc['name'].value + '-eu'").Param("suffix", "-eu")),
+					TermsAgg("by_tag", "").Script(InlineScript("doc['tag'].value")),
+				),
+			[]string{
+				"/query/terms_set/tags/minimum_should_match_script",
+				"/aggs/avg_age/avg/script",
+				"/aggs/users/cardinality/script",
+				"/aggs/by_name/terms/script",
+			},
+		},
+		{
+			"nil scripts",
+			Search().
+				Query(TermsSet("tags", "a").MinimumShouldMatchField("n").MinimumShouldMatchScript(nil)).
+				Aggs(Avg("avg_age", "age").Script(nil), TermsAgg("by_tag", "tag").Script(nil)),
+			nil,
+		},
+		{
+			"sub-aggregations",
+			TermsAgg("by_tag", "tag").Aggs(
+				Avg("avg_age", ""),
+				Max("max_age", "age"),
+				Max("max_age", "age"),
+			),
+			[]string{"/aggs/avg_age/avg", "/aggs/max_age"},
+		},
+		{
+			"search request",
+			Search().
+				Query(Range("age")).
+				PostFilter(Exists("")).
+				Aggs(TermsAgg("by_tag", "").Aggs(Sum("total", ""))),
+			[]string{
+				"/query/range/age",
+				"/post_filter/exists/field",
+				"/aggs/by_tag/terms/field",
+				"/aggs/by_tag/aggs/total/sum",
+			},
+		},
+		{
+			"typed sort keys",
+			Search().
+				Sort("created_at", OrderDesc).
+				SortBy(
+					SortField(""),
+					SortScore(),
+					SortField("price").Nested(SortNested("offers").Filter(Range("offers.price"))),
+				).
+				Aggs(TopHits("top").SortBy(SortField(""))),
+			[]string{
+				"/aggs/top/top_hits/sort/0",
+				"/sort/1",
+				"/sort/3/price/nested/filter/range/offers.price",
+			},
+		},
+		{
+			"empty query strings",
+			Bool().Must(QueryString(""), SimpleQueryString("")).Should(QueryString("a:b")),
+			[]string{"/bool/must/0/query_string/query", "/bool/must/1/simple_query_string/query"},
+		},
+		{
+			"highlight with invalid queries",
+			Search().Highlight(
+				Highlight().
+					HighlightQuery(Range("score")).
+					Field("title", Highlight().HighlightQuery(Term("", "a"))).
+					Field("body"),
+			),
+			[]string{"/highlight/query/range/score", "/highlight/fields/title/query/term"},
+		},
+		{
+			"script sort without script",
+			Search().SortBy(SortScript(nil, ScriptSortNumber)),
+			[]string{"/sort/0/_script/script"},
+		},
+		{
+			"field names are escaped",
+			Range("a/b"),
+			[]string{"/range/a~1b"},
+		},
+		{
+			"nil value",
+			(*BoolQuery)(nil),
+			[]string{""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(test.value)
+			if test.paths == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %#v", err)
+			}
+			paths := make([]string, len(errs))
+			for i, e := range errs {
+				paths[i] = e.Path
+			}
+			if !reflect.DeepEqual(paths, test.paths) {
+				t.Errorf("expected errors at %q, got %q (%s)", test.paths, paths, err)
+			}
+		})
+	}
+}
+
+func TestValidationErrorString(t *testing.T) {
+	err := Bool().Must(Range("age"), Terms("tags")).Validate()
+	expected := "esquery: invalid DSL: /bool/must/0/range/age: at least one bound is required; " +
+		"/bool/must/1/terms/tags: at least one value is required"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestMapDoesNotPanic(t *testing.T) {
+	runMapTests(t, []mapTest{
+		{
+			"terms aggregation with empty include",
+			TermsAgg("by_tag", "tag").Include(),
+			map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "tag",
+				},
+			},
+		},
+		{
+			"filter aggregation with nil filter",
+			FilterAgg("filtered", nil),
+			map[string]interface{}{},
+		},
+		{
+			"metric aggregation with nil script",
+			Avg("avg_age", "age").Script(nil),
+			map[string]interface{}{
+				"avg": map[string]interface{}{
+					"field": "age",
+				},
+			},
+		},
+		{
+			"terms_set query with nil script",
+			TermsSet("tags", "a").MinimumShouldMatchScript(nil),
+			map[string]interface{}{
+				"terms_set": map[string]interface{}{
+					"tags": map[string]interface{}{
+						"terms": []string{"a"},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestRunValidates(t *testing.T) {
+	called := false
+	search := esapi.Search(func(o ...func(*esapi.SearchRequest)) (*esapi.Response, error) {
+		called = true
+		return &esapi.Response{StatusCode: 200}, nil
+	})
+	del := esapi.DeleteByQuery(func(index []string, body io.Reader, o ...func(*esapi.DeleteByQueryRequest)) (*esapi.Response, error) {
+		called = true
+		return &esapi.Response{StatusCode: 200}, nil
+	})
+
+	if _, err := Query(Range("age")).RunSearch(search); err == nil || called {
+		t.Errorf("expected invalid search to be refused, got err=%v, called=%v", err, called)
+	}
+	if _, err := Delete().Index("users").RunDelete(del); err == nil || called {
+		t.Errorf("expected delete without query to be refused, got err=%v, called=%v", err, called)
+	}
+	if _, err := Query(Range("age")).MarshalJSON(); err == nil {
+		t.Error("expected MarshalJSON to fail for an invalid search")
+	}
+
+	if _, err := Query(Range("age").Gte(18)).RunSearch(search); err != nil || !called {
+		t.Errorf("expected valid search to run, got err=%v, called=%v", err, called)
+	}
+}
@@ -0,0 +1,212 @@
+package esquery
+
+import "sort"
+
+// Visitor is the interface implemented by the visitors of query and
+// aggregation trees, as traversed by Walk. Visit is called for every node of
+// the tree; if the returned visitor w is not nil, the children of the node
+// are visited with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Mappable) (w Visitor)
+}
+
+// Walk traverses a tree of queries, aggregations, score functions, intervals
+// rules and requests in depth-first order: it starts by calling
+// v.Visit(node), and if the visitor it returns is not nil, Walk is called
+// recursively with it for each of the non-nil children of node, followed by
+// a call of w.Visit(nil). Children are visited in a fixed order, e.g. a
+// search request's query before its post filter, sort keys, highlight and
+// aggregations, and a bool query's must clauses before its filter, must_not
+// and should clauses.
+//
+// Nodes of types unknown to the library (such as custom queries) have no
+// children.
+func Walk(node Mappable, v Visitor) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	for _, child := range children(node) {
+		if !isNil(child) {
+			Walk(child, v)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Mappable) bool
+
+func (f inspector) Visit(node Mappable) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a tree in depth-first order like Walk: it starts by
+// calling f(node), and if f returns true, Inspect is called recursively for
+// each of the non-nil children of node, followed by a call of f(nil).
+//
+// For example, the fields referenced by term queries can be collected with:
+//
+//	var fields []string
+//	esquery.Inspect(query, func(node esquery.Mappable) bool {
+//	    if q, ok := node.(*esquery.TermQuery); ok {
+//	        fields = append(fields, q.GetField())
+//	    }
+//	    return true
+//	})
+func Inspect(node Mappable, f func(Mappable) bool) {
+	Walk(node, inspector(f))
+}
+
+// children returns the child nodes of the provided node, which may contain
+// nil values.
+func children(node Mappable) []Mappable {
+	switch n := node.(type) {
+	// requests
+	case *SearchRequest:
+		nodes := append([]Mappable{n.query, n.postFilter}, hitNodes(&n.hitOptions)...)
+		return append(nodes, aggNodes(n.aggs)...)
+	case *CountRequest:
+		return []Mappable{n.query}
+	case *UpdateRequest:
+		return []Mappable{n.query}
+	case *RegisterQueryRequest:
+		return []Mappable{n.query}
+
+	// compound queries
+	case *BoolQuery:
+		var nodes []Mappable
+		nodes = append(nodes, n.must...)
+		nodes = append(nodes, n.filter...)
+		nodes = append(nodes, n.mustNot...)
+		return append(nodes, n.should...)
+	case *BoostingQuery:
+		return []Mappable{n.Pos, n.Neg}
+	case *ConstantScoreQuery:
+		return []Mappable{n.filter}
+	case *DisMaxQuery:
+		return n.queries
+	case *FunctionScoreQuery:
+		nodes := []Mappable{n.query}
+		for _, f := range n.functions {
+			nodes = append(nodes, f)
+		}
+		return nodes
+	case *ScriptScoreQuery:
+		return []Mappable{n.query}
+	case *NestedQuery:
+		return []Mappable{n.query}
+	case *PinnedQuery:
+		return []Mappable{n.organic}
+
+	// score functions
+	case *WeightFunction:
+		return []Mappable{n.filter}
+	case *RandomScoreFunction:
+		return []Mappable{n.filter}
+	case *FieldValueFactorFunction:
+		return []Mappable{n.filter}
+	case *DecayFunction:
+		return []Mappable{n.filter}
+	case *ScriptScoreFunction:
+		return []Mappable{n.filter}
+
+	// span queries
+	case *SpanNearQuery:
+		return spanNodes(n.clauses)
+	case *SpanOrQuery:
+		return spanNodes(n.clauses)
+	case *SpanNotQuery:
+		return []Mappable{n.include, n.exclude}
+	case *SpanFirstQuery:
+		return []Mappable{n.match}
+	case *SpanContainingQuery:
+		return []Mappable{n.big, n.little}
+	case *SpanWithinQuery:
+		return []Mappable{n.big, n.little}
+	case *SpanMultiTermQuery:
+		return []Mappable{n.match}
+	case *FieldMaskingSpanQuery:
+		return []Mappable{n.query}
+
+	// intervals
+	case *IntervalsQuery:
+		return []Mappable{n.rule}
+	case *IntervalsMatchRule:
+		return []Mappable{n.filter}
+	case *IntervalsAllOfRule:
+		return append(ruleNodes(n.intervals), n.filter)
+	case *IntervalsAnyOfRule:
+		return append(ruleNodes(n.intervals), n.filter)
+	case *IntervalsFilter:
+		return []Mappable{n.rule}
+
+	// aggregations
+	case *TermsAggregation:
+		return aggNodes(n.aggs)
+	case *FilterAggregation:
+		return append([]Mappable{n.filter}, aggNodes(n.aggs)...)
+	case *NestedAggregation:
+		return aggNodes(n.aggs)
+	case *TopHitsAgg:
+		return hitNodes(&n.hitOptions)
+
+	// hit options
+	case *QueryHighlight:
+		nodes := []Mappable{n.highlightQuery}
+		names := make([]string, 0, len(n.fields))
+		for name := range n.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			nodes = append(nodes, n.fields[name])
+		}
+		return nodes
+	case *FieldSort:
+		return []Mappable{n.nested}
+	case *NestedSort:
+		return []Mappable{n.filter, n.nested}
+	}
+
+	return nil
+}
+
+// hitNodes returns the typed sort keys and the highlight of the provided
+// options.
+func hitNodes(opts *hitOptions) []Mappable {
+	var nodes []Mappable
+	for _, k := range opts.sort {
+		if k.sorter != nil {
+			nodes = append(nodes, k.sorter)
+		}
+	}
+	return append(nodes, opts.highlight)
+}
+
+func aggNodes(aggs []Aggregation) []Mappable {
+	nodes := make([]Mappable, len(aggs))
+	for i, agg := range aggs {
+		nodes[i] = agg
+	}
+	return nodes
+}
+
+func spanNodes(clauses []SpanQuery) []Mappable {
+	nodes := make([]Mappable, len(clauses))
+	for i, c := range clauses {
+		nodes[i] = c
+	}
+	return nodes
+}
+
+func ruleNodes(rules []IntervalsRule) []Mappable {
+	nodes := make([]Mappable, len(rules))
+	for i, r := range rules {
+		nodes[i] = r
+	}
+	return nodes
+}
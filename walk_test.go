@@ -0,0 +1,166 @@
+package esquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// typeVisitor records the types of the visited nodes, and the end of the
+// visit of their children.
+type typeVisitor struct {
+	types *[]string
+	skip  string
+}
+
+func (v typeVisitor) Visit(node Mappable) Visitor {
+	if node == nil {
+		*v.types = append(*v.types, "end")
+		return nil
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", node), "*esquery.")
+	*v.types = append(*v.types, name)
+	if name == v.skip {
+		return nil
+	}
+	return v
+}
+
+func TestWalk(t *testing.T) {
+	req := Search().
+		Query(Bool().
+			Must(Match("title", "openssl")).
+			Filter(
+				Nested("packages", Term("packages.name", "openssl")),
+				DisMax(Wildcard("path", "*lib"), Prefix("path", "/usr")),
+			).
+			Should(SpanNear(SpanTerm("body", "buffer"), SpanTerm("body", "overflow")))).
+		PostFilter(Range("score").Gte(7)).
+		Aggs(
+			TermsAgg("by_status", "status").Aggs(Avg("avg_score", "score")),
+			FilterAgg("fixed", Term("fixed", true)),
+		)
+
+	tests := []struct {
+		name     string
+		skip     string
+		expected []string
+	}{
+		{
+			"full traversal",
+			"",
+			[]string{
+				"SearchRequest",
+				"BoolQuery",
+				"MatchQuery", "end",
+				"NestedQuery", "TermQuery", "end", "end",
+				"DisMaxQuery", "RegexpQuery", "end", "PrefixQuery", "end", "end",
+				"SpanNearQuery", "SpanTermQuery", "end", "SpanTermQuery", "end", "end",
+				"end",
+				"RangeQuery", "end",
+				"TermsAggregation", "AvgAgg", "end", "end",
+				"FilterAggregation", "TermQuery", "end", "end",
+				"end",
+			},
+		},
+		{
+			"skipping children",
+			"BoolQuery",
+			[]string{
+				"SearchRequest",
+				"BoolQuery",
+				"RangeQuery", "end",
+				"TermsAggregation", "AvgAgg", "end", "end",
+				"FilterAggregation", "TermQuery", "end", "end",
+				"end",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var types []string
+			Walk(req, typeVisitor{types: &types, skip: test.skip})
+			if !reflect.DeepEqual(types, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, types)
+			}
+		})
+	}
+}
+
+func TestWalkHitOptions(t *testing.T) {
+	req := Search().
+		Query(MatchAll()).
+		Sort("score", OrderDesc).
+		SortBy(
+			SortField("offers.price").Nested(SortNested("offers").Filter(Wildcard("offers.seller", "*corp"))),
+			SortScript(InlineScript("doc['a'].value"), ScriptSortNumber),
+		).
+		Highlight(Highlight().
+			HighlightQuery(Regexp("title", "open.*")).
+			Field("title", Highlight().HighlightQuery(Terms("tags", "a", "b")))).
+		Aggs(TopHits("top").SortBy(SortScore()))
+
+	var types []string
+	Walk(req, typeVisitor{types: &types})
+	expected := []string{
+		"SearchRequest",
+		"MatchAllQuery", "end",
+		"FieldSort", "NestedSort", "RegexpQuery", "end", "end", "end",
+		"ScriptSort", "end",
+		"QueryHighlight", "RegexpQuery", "end", "QueryHighlight", "TermsQuery", "end", "end", "end",
+		"TopHitsAgg", "FieldSort", "end", "end",
+		"end",
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected %v, got %v", expected, types)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	q := Bool().
+		Must(MultiMatch("openssl").Fields("title^2", "description")).
+		Filter(
+			Terms("severity", "high", "critical"),
+			Wildcard("path", "*lib"),
+			Regexp("name", "open.*"),
+		).
+		MustNot(ConstantScore(Exists("fixed_at")))
+
+	var fields, expensive []string
+	var clauses int
+	Inspect(q, func(node Mappable) bool {
+		switch n := node.(type) {
+		case *BoolQuery:
+			clauses += len(n.GetMust()) + len(n.GetFilter()) +
+				len(n.GetMustNot()) + len(n.GetShould())
+		case *MultiMatchQuery:
+			fields = append(fields, n.GetFields()...)
+		case *TermsQuery:
+			fields = append(fields, n.GetField())
+		case *ExistsQuery:
+			fields = append(fields, n.Field)
+		case *RegexpQuery:
+			fields = append(fields, n.GetField())
+			if !n.IsWildcard() || strings.HasPrefix(n.GetValue(), "*") {
+				expensive = append(expensive, n.GetField())
+			}
+		}
+		return true
+	})
+
+	expFields := []string{"title", "description", "severity", "path", "name", "fixed_at"}
+	if !reflect.DeepEqual(fields, expFields) {
+		t.Errorf("expected fields %v, got %v", expFields, fields)
+	}
+
+	expExpensive := []string{"path", "name"}
+	if !reflect.DeepEqual(expensive, expExpensive) {
+		t.Errorf("expected expensive queries on %v, got %v", expExpensive, expensive)
+	}
+
+	if clauses != 5 {
+		t.Errorf("expected 5 clauses, got %d", clauses)
+	}
+}